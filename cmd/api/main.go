@@ -1,75 +1,301 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"go-placement-policy/internal/api"
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/auth"
+	"go-placement-policy/internal/changes"
+	"go-placement-policy/internal/config"
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/logging"
+	"go-placement-policy/internal/metrics"
+	"go-placement-policy/internal/notify"
+	"go-placement-policy/internal/policy"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/schedule"
 	"go-placement-policy/internal/storage"
+	"go-placement-policy/internal/webhooks"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 )
 
+// fatal logs msg at error level and exits.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
 func main() {
-	// The init() function in the storage package handles loading student data from students.json
-	// and initializing the ActivePolicyConfig with default values. 
-	// This check is a safeguard: if ActivePolicyConfig appears uninitialized (e.g. zero-valued for MaxN 
-	// and Enabled is false for MaximumCompanies policy), it logs a message and initializes to an empty config.
-	// This situation should ideally not occur if storage.init() functions correctly.
-	if storage.ActivePolicyConfig.MaximumCompanies.MaxN == 0 && !storage.ActivePolicyConfig.MaximumCompanies.Enabled {
-		// This explicit initialization to an empty config might be too drastic if defaults are expected.
-		// A better approach might be to call storage.initializeDefaultPolicies() if a zero-value is detected,
-		// or rely solely on the init() in the storage package.
-		// For now, logging this state is important for diagnostics.
-		// storage.ActivePolicyConfig = models.PolicyConfig{} // Consider implications before re-enabling.
-		log.Println("Warning: ActivePolicyConfig in main appeared to be zero-valued after storage init. This might indicate an issue with default policy loading.")
+	// Server settings come from flags, environment variables and an optional config file (see internal/config).
+	// --print-config shows the result without starting the server.
+	cfg, opts, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		fatal("could not load configuration", "error", err)
+	}
+	if opts.PrintConfig {
+		cfg.Print(os.Stdout)
+	}
+	if err := cfg.Validate(); err != nil {
+		fatal("invalid configuration", "error", err)
+	}
+	if opts.PrintConfig {
+		return
+	}
+
+	// Logs are JSON lines on stderr. At debug level every eligibility decision is logged with its student,
+	// company, verdict and codes.
+	if err := logging.SetLevel(cfg.LogLevel); err != nil {
+		fatal("invalid log level", "error", err)
+	}
+
+	if err := storage.Load(cfg.DataDir); err != nil {
+		fatal("could not load data", "error", err)
+	}
+
+	// The audit log is append-only and survives restarts, so disputes can be traced back to the exact decision.
+	if err := audit.Open(cfg.AuditLogPath); err != nil {
+		fatal("could not open audit log", "error", err)
+	}
+	// Every recorded change is also published to live GET /events subscribers.
+	audit.AddListener(events.PublishAudit)
+
+	// Webhook subscriptions are saved with their secrets so deliveries resume, still signed, after a restart.
+	if err := webhooks.Open(cfg.WebhooksPath); err != nil {
+		fatal("could not open webhook subscriptions", "error", err)
+	}
+	webhooks.Start()
+
+	// Eligibility results are cached per student–company pair until either record, the policies or the placement
+	// statistics change. The cache size bounds the number of cached pairs; 0 disables the cache.
+	eligibility.SetCacheSize(cfg.Eligibility.CacheSize)
+
+	// Eligibility flips detected after each change are appended to their own log, queryable at GET /eligibility/changes.
+	if err := changes.Open(cfg.Eligibility.ChangesPath); err != nil {
+		fatal("could not open eligibility change log", "error", err)
+	}
+	changes.Start()
+
+	// Students are emailed about new drives, policy changes that block them and recorded offers.
+	// The transport is smtp, file (appending full messages to a file) or log. Students' preferences are kept
+	// in their own file so opt-outs survive restarts.
+	if err := notify.OpenPreferences(cfg.Notify.PreferencesPath); err != nil {
+		fatal("could not open notification preferences", "error", err)
+	}
+	switch cfg.Notify.Transport {
+	case config.TransportLog:
+		notify.Start(notify.LogTransport{})
+	case config.TransportFile:
+		notify.Start(&notify.FileTransport{Path: cfg.Notify.File, From: cfg.Notify.From})
+	case config.TransportSMTP:
+		smtp := cfg.Notify.SMTP
+		notify.Start(notify.SMTPTransport{Addr: smtp.Addr, From: cfg.Notify.From, Username: smtp.Username, Password: string(smtp.Password)})
+	}
+
+	// Drive days (for same-day conflict checks) are counted in the campus time zone.
+	if cfg.CampusTimezone != "" {
+		location, err := time.LoadLocation(cfg.CampusTimezone)
+		if err != nil {
+			fatal("invalid campus time zone", "value", cfg.CampusTimezone, "error", err)
+		}
+		schedule.Location = location
+	}
+
+	// Optionally manage policies from a reviewed YAML/JSON file instead of the API.
+	// The file is polled for changes; invalid versions are rejected and the previous config stays active.
+	if policyFile := cfg.PolicyFile; policyFile.Path != "" {
+		interval := time.Duration(policyFile.PollInterval)
+		policy.WatchFile(context.Background(), policyFile.Path, interval)
+		slog.Info("policy configuration is managed by a file; API policy edits are disabled", "path", policyFile.Path, "pollInterval", interval.String())
 	}
 
 	router := chi.NewRouter()
+	router.NotFound(problem.NotFoundHandler)
+	router.MethodNotAllowed(problem.MethodNotAllowedHandler)
 
 	// CORS Middleware Configuration to allow requests from the React frontend (localhost:3000).
 	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"}, // React app's origin
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}, // Common HTTP methods
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"}, // Common headers
-		ExposedHeaders:   []string{"Link"}, // Headers the client can access
+		AllowedOrigins:   cfg.AllowedOrigins, // The React app's origin by default
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}, // Common HTTP methods
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "If-Match", "If-None-Match", "Last-Event-ID"}, // Common headers
+		ExposedHeaders:   []string{"Link", "X-Total-Count", "ETag", "Content-Disposition"}, // Headers the client can access
 		AllowCredentials: true, // Allows cookies to be sent
 		MaxAge:           300,  // How long the result of a preflight request can be cached (in seconds)
 	}))
 
 	// Standard Chi middleware
-	router.Use(middleware.Logger)    // Logs request details (method, path, duration, status)
+	router.Use(middleware.RequestID) // Assigns each request an ID, echoed in error responses as requestId
+	router.Use(logging.Middleware)   // Logs each request as a JSON line (method, path, route, status, duration)
+	router.Use(metrics.Middleware)   // Counts and times requests per route for GET /metrics
 	router.Use(middleware.Recoverer) // Gracefully handles panics and returns a 500 error
 	router.Use(middleware.Heartbeat("/ping")) // Provides a /ping endpoint for health checks
+	// Resolves bearer tokens into principals. Anonymous requests pass through and are rejected by RequireRole on protected routes.
+	router.Use(auth.Authenticate)
 
 	// An additional, simple heartbeat endpoint. /ping from middleware.Heartbeat is usually sufficient.
 	router.Get("/heartbeat", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("Alive"))
 	})
 
+	// Coordinators log in with one of the configured accounts and get a session token; this is how the React
+	// frontend authenticates. The coordinator token is a fixed token for scripts and curl. Validation guarantees
+	// at least one of them, so the server is never left open.
+	if token := cfg.Auth.CoordinatorToken; token != "" {
+		auth.RegisterToken(string(token), auth.Principal{Role: auth.RoleCoordinator, Name: "coordinator"})
+	}
+	passwords := map[string]string{}
+	for name, password := range cfg.Auth.CoordinatorAccounts {
+		passwords[name] = string(password)
+	}
+	auth.SetCoordinatorAccounts(passwords)
+	api.SessionTTL = time.Duration(cfg.Auth.SessionTTL)
+	api.StudentTokenTTL = time.Duration(cfg.Auth.StudentTokenTTL)
+
+	// Prometheus metrics. Like the heartbeats these are unauthenticated, so scrapers need no token; they expose
+	// only aggregate counts.
+	router.Get("/metrics", metrics.Handler)
+
 	// API Route definitions
-	// Policy related endpoints
+	// Public, non-student-specific endpoints
 	router.Get("/policies", api.GetPoliciesHandler)
-	router.Post("/policies/configure", api.ConfigurePoliciesHandler)
+	router.Get("/companies", api.GetAllCompaniesHandler)
+	router.Get("/drives", api.GetDrivesHandler)
+	router.Get("/drives/calendar.ics", api.GetCampusCalendarHandler)
+	router.Get("/drives/{driveID}", api.GetDriveHandler)
 
-	// Student related endpoints
-	router.Get("/students", api.GetAllStudentsHandler)
-	router.Get("/students/{studentID}", api.GetStudentByIDHandler)
-	router.Post("/students", api.CreateStudentHandler)
+	// Coordinator login and session management
+	router.Post("/auth/login", api.LoginHandler)
+	router.Post("/auth/logout", api.LogoutHandler)
+	router.Get("/auth/session", api.GetSessionHandler)
 
-	// Company related endpoints
-	router.Get("/companies", api.GetAllCompaniesHandler)
+	// Coordinator-only endpoints. These expose every student's data, so student tokens are refused.
+	router.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleCoordinator))
+
+		// Policy related endpoints
+		r.Get("/policies/source", api.GetPolicySourceHandler)
+		r.Get("/policies/impact", api.GetPolicyImpactHandler)
+		r.Post("/policies/configure", api.ConfigurePoliciesHandler)
+		r.Patch("/policies", api.PatchPoliciesHandler)
+		r.Put("/policies/{policyName}", api.PutPolicyHandler)
+		r.Post("/policies/{policyName}/enable", api.EnablePolicyHandler)
+		r.Post("/policies/{policyName}/disable", api.DisablePolicyHandler)
+		r.Post("/policies/customRules/validate", api.ValidateRuleConditionHandler)
+		r.Put("/policies/customRules/{ruleName}", api.PutCustomRuleHandler)
+		r.Delete("/policies/customRules/{ruleName}", api.DeleteCustomRuleHandler)
+
+		// Student related endpoints
+		r.Get("/students", api.GetAllStudentsHandler)
+		r.Get("/students/{studentID}", api.GetStudentByIDHandler)
+		r.Post("/students", api.CreateStudentHandler)
+		r.Post("/students/import", api.ImportStudentsHandler)
+		r.Post("/companies/import", api.ImportCompaniesHandler)
+		r.Put("/students/{studentID}", api.UpdateStudentHandler)
+		r.Post("/students/{studentID}/offers", api.RecordOfferHandler)
+		r.Post("/students/{studentID}/token", api.IssueStudentTokenHandler)
+		r.Delete("/students/{studentID}/token", api.RevokeStudentTokensHandler)
+		r.Get("/students/{studentID}/recommendations", api.GetStudentRecommendationsHandler)
+		r.Get("/students/{studentID}/calendar.ics", api.GetStudentCalendarHandler)
+		r.Get("/students/{studentID}/notifications", api.GetStudentNotificationsHandler)
+		r.Put("/students/{studentID}/notifications", api.UpdateStudentNotificationsHandler)
 
-	// Eligibility checking endpoints
-	router.Post("/eligibility/check", api.CheckEligibilityHandler)
-	router.Get("/eligibility/company/{companyID}/students", api.GetEligibleStudentsForCompanyHandler)
+		// Drive scheduling endpoints
+		r.Post("/drives", api.CreateDriveHandler)
+		r.Put("/drives/{driveID}", api.UpdateDriveHandler)
+		r.Get("/drives/conflicts", api.GetDriveConflictsHandler)
+		r.Get("/drives/{driveID}/applications", api.GetDriveApplicationsHandler)
+		r.Post("/drives/{driveID}/rounds/{roundName}/shortlist", api.UploadShortlistHandler)
+		r.Post("/drives/{driveID}/rounds/{roundName}/results", api.UploadRoundResultsHandler)
 
-	port := ":8080"
-	log.Printf("Server starting on port %s using chi router with CORS enabled...\n", port)
-	err := http.ListenAndServe(port, router)
-	if err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+		// Eligibility checking endpoints
+		r.Post("/eligibility/check", api.CheckEligibilityHandler)
+		r.Get("/eligibility/company/{companyID}/students", api.GetEligibleStudentsForCompanyHandler)
+		r.Get("/eligibility/changes", api.GetEligibilityChangesHandler)
+		r.Get("/eligibility/cache", api.GetEligibilityCacheHandler)
+
+		// Audit log of mutations and eligibility decisions, and a live stream of changes
+		r.Get("/audit", api.GetAuditLogHandler)
+		r.Get("/events", api.StreamEventsHandler)
+
+		// Outbound webhooks: subscriptions, delivery log and dead letters
+		r.Get("/webhooks", api.GetWebhooksHandler)
+		r.Post("/webhooks", api.CreateWebhookHandler)
+		r.Get("/webhooks/deliveries", api.GetWebhookDeliveriesHandler)
+		r.Get("/webhooks/deadletters", api.GetDeadLettersHandler)
+		r.Post("/webhooks/deliveries/{deliveryID}/retry", api.RetryDeliveryHandler)
+		r.Get("/webhooks/{webhookID}", api.GetWebhookHandler)
+		r.Put("/webhooks/{webhookID}", api.UpdateWebhookHandler)
+		r.Delete("/webhooks/{webhookID}", api.DeleteWebhookHandler)
+
+		// Reporting: analytics and data exports
+		r.Get("/stats", api.GetStatsHandler)
+		r.Get("/export/students", api.ExportStudentsHandler)
+		r.Get("/export/companies", api.ExportCompaniesHandler)
+		r.Get("/export/eligibility", api.ExportEligibilityMatrixHandler)
+		r.Get("/export/applications", api.ExportApplicationsHandler)
+	})
+
+	// Student self-service endpoints, scoped to the student the token was issued for.
+	router.Route("/me", func(r chi.Router) {
+		r.Use(auth.RequireRole(auth.RoleStudent))
+		r.Get("/", api.GetMyProfileHandler)
+		r.Get("/eligibility", api.GetMyEligibilityHandler)
+		r.Get("/recommendations", api.GetMyRecommendationsHandler)
+		r.Put("/dream", api.UpdateMyDreamHandler)
+		r.Get("/applications", api.GetMyApplicationsHandler)
+		r.Post("/applications", api.ApplyHandler)
+		r.Get("/calendar.ics", api.GetMyCalendarHandler)
+		r.Get("/notifications", api.GetMyNotificationsHandler)
+		r.Put("/notifications", api.UpdateMyNotificationsHandler)
+	})
+
+	// Stopping the server cancels the base context, which ends event streams, then waits for other requests to finish.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           router,
+		ReadTimeout:       time.Duration(cfg.Timeouts.Read),
+		ReadHeaderTimeout: time.Duration(cfg.Timeouts.ReadHeader),
+		WriteTimeout:      time.Duration(cfg.Timeouts.Write),
+		IdleTimeout:       time.Duration(cfg.Timeouts.Idle),
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", cfg.Addr, "tls", cfg.TLS.Enabled(), "storageBackend", cfg.StorageBackend)
+		if cfg.TLS.Enabled() {
+			serveErr <- server.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		} else {
+			serveErr <- server.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-serveErr:
+		fatal("server failed", "error", err)
+	case <-ctx.Done():
+	}
+	slog.Info("shutting down", "timeout", time.Duration(cfg.Timeouts.Shutdown).String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Timeouts.Shutdown))
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown did not complete", "error", err)
 	}
+	changes.Flush()
 } 
\ No newline at end of file
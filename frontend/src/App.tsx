@@ -1,4 +1,4 @@
-import React from 'react';
+import React, { useEffect, useState } from 'react';
 import {
   AppBar,
   Box,
@@ -19,6 +19,8 @@ import CreateStudentPage from './pages/CreateStudentPage';
 import DocumentationPage from './pages/DocumentationPage';
 import CompanyListPage from './pages/CompanyListPage';
 import EditStudentPage from './pages/EditStudentPage';
+import LoginPage from './pages/LoginPage';
+import { Session, getSession, logout, onSessionChange } from './api/session';
 
 const theme = createTheme({
   palette: {
@@ -73,6 +75,10 @@ const theme = createTheme({
 });
 
 const App: React.FC = () => {
+  // Every page needs coordinator access, so without a session only the login page is shown.
+  const [session, setSession] = useState<Session | null>(getSession);
+  useEffect(() => onSessionChange(setSession), []);
+
   return (
     <ThemeProvider theme={theme}>
       <CssBaseline />
@@ -88,21 +94,26 @@ const App: React.FC = () => {
           <Button color="inherit" component={Link} to="/policies">Policy Editor</Button>
           <Button color="inherit" component={Link} to="/eligibility-check">Eligibility Checker</Button>
           <Button color="inherit" component={Link} to="/documentation">Documentation</Button>
+          {session && <Button color="inherit" onClick={() => logout()}>Log out ({session.principal.name})</Button>}
         </Toolbar>
       </AppBar>
 
       {/* Routes */}
       <Container component="main" sx={{ mt: 2, mb: 2 }}>
-        <Routes>
-          <Route path="/" element={<HomePage />} />
-          <Route path="/student-list" element={<StudentListPage />} />
-          <Route path="/students/create" element={<CreateStudentPage />} />
-          <Route path="/students/edit/:id" element={<EditStudentPage />} />
-          <Route path="/companies" element={<CompanyListPage />} />
-          <Route path="/policies" element={<PolicyEditorPage />} />
-          <Route path="/eligibility-check" element={<EligibilityCheckerPage />} />
-          <Route path="/documentation" element={<DocumentationPage />} />
-        </Routes>
+        {session ? (
+          <Routes>
+            <Route path="/" element={<HomePage />} />
+            <Route path="/student-list" element={<StudentListPage />} />
+            <Route path="/students/create" element={<CreateStudentPage />} />
+            <Route path="/students/edit/:id" element={<EditStudentPage />} />
+            <Route path="/companies" element={<CompanyListPage />} />
+            <Route path="/policies" element={<PolicyEditorPage />} />
+            <Route path="/eligibility-check" element={<EligibilityCheckerPage />} />
+            <Route path="/documentation" element={<DocumentationPage />} />
+          </Routes>
+        ) : (
+          <LoginPage />
+        )}
       </Container>
 
       {/* Footer */}
@@ -0,0 +1,32 @@
+import axios, { AxiosError, AxiosInstance } from 'axios';
+import { attachProblemInterceptor } from './problem';
+import { clearSession, getSession } from './session';
+
+// createApiClient returns an axios instance for the Go API that sends the current session's token with every
+// request and returns to the login page when the server rejects it.
+export const createApiClient = (): AxiosInstance => {
+    const client = axios.create({
+        baseURL: 'http://localhost:8080', // Base URL for the Go backend API
+        headers: {
+            'Content-Type': 'application/json',
+        },
+    });
+    client.interceptors.request.use((config) => {
+        const session = getSession();
+        if (session) {
+            config.headers.Authorization = `Bearer ${session.token}`;
+        }
+        return config;
+    });
+    client.interceptors.response.use(
+        (response) => response,
+        (error: AxiosError) => {
+            if (error.response?.status === 401) {
+                clearSession();
+            }
+            return Promise.reject(error);
+        },
+    );
+    attachProblemInterceptor(client);
+    return client;
+};
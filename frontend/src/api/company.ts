@@ -1,14 +1,10 @@
-import axios from 'axios';
+import { createApiClient } from './client';
 import { Company } from '../interfaces/company'; // Ensure path is correct
+import { ListPage } from '../interfaces/list';
 
-const apiClient = axios.create({
-    baseURL: 'http://localhost:8080', // Your Go API base URL
-    headers: {
-        'Content-Type': 'application/json',
-    },
-});
+const apiClient = createApiClient();
 
 export const getCompanies = async (): Promise<Company[]> => {
-    const response = await apiClient.get<Company[]>('/companies');
-    return response.data;
+    const response = await apiClient.get<ListPage<Company>>('/companies');
+    return response.data.items;
 }; 
\ No newline at end of file
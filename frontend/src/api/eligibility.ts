@@ -1,13 +1,8 @@
-import axios from 'axios';
+import { createApiClient } from './client';
 import { EligibilityRequestPayload, EligibilityResult } from '../interfaces/eligibility';
 import { Student } from '../interfaces/student';
 
-const apiClient = axios.create({
-    baseURL: 'http://localhost:8080', // Your Go API base URL
-    headers: {
-        'Content-Type': 'application/json',
-    },
-});
+const apiClient = createApiClient();
 
 export const checkStudentEligibility = async (payload: EligibilityRequestPayload): Promise<EligibilityResult> => {
     const response = await apiClient.post<EligibilityResult>('/eligibility/check', payload);
@@ -1,26 +1,24 @@
-import axios from 'axios';
-import { PolicyConfig } from '../interfaces/policy'; // Ensure path is correct
+import { createApiClient } from './client';
+import { PolicyConfig, VersionedPolicyConfig } from '../interfaces/policy'; // Ensure path is correct
 
-const apiClient = axios.create({
-    baseURL: 'http://localhost:8080',
-    headers: {
-        'Content-Type': 'application/json',
-    },
-});
+const apiClient = createApiClient();
 
 /**
  * Fetches the current policy configuration.
  */
-export const getPolicies = async (): Promise<PolicyConfig> => {
-    const response = await apiClient.get<PolicyConfig>('/policies');
+export const getPolicies = async (): Promise<VersionedPolicyConfig> => {
+    const response = await apiClient.get<VersionedPolicyConfig>('/policies');
     return response.data;
 };
 
 /**
  * Updates the policy configuration.
+ * If the config carries the version it was loaded at, the server rejects the update with a
+ * precondition_failed error when someone else has saved in the meantime.
  * @param config The new policy configuration.
  */
-export const updatePolicies = async (config: PolicyConfig): Promise<PolicyConfig> => {
-    const response = await apiClient.post<PolicyConfig>('/policies/configure', config);
+export const updatePolicies = async (config: VersionedPolicyConfig): Promise<VersionedPolicyConfig> => {
+    const headers = config.version ? { 'If-Match': `"${config.version}"` } : {};
+    const response = await apiClient.post<VersionedPolicyConfig>('/policies/configure', config, { headers });
     return response.data;
 };
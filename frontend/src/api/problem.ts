@@ -0,0 +1,34 @@
+import { AxiosError, AxiosInstance } from 'axios';
+
+// Problem mirrors the RFC 7807 problem document returned by the Go API for every error.
+export interface Problem {
+    type: string;
+    title: string;
+    status: number;
+    detail?: string;
+    instance?: string;
+    code: string;
+    requestId?: string;
+    errors?: { field: string; message: string }[];
+}
+
+// ApiError carries the parsed problem so pages can show field errors or switch on the error code.
+export class ApiError extends Error {
+    constructor(public problem: Problem) {
+        super(problem.detail || problem.title);
+        this.name = 'ApiError';
+    }
+}
+
+// Rejects failed requests with an ApiError whose message is the problem's detail.
+export const attachProblemInterceptor = (client: AxiosInstance): void => {
+    client.interceptors.response.use(
+        (response) => response,
+        (error: AxiosError<Problem>) => {
+            if (error.response?.data && typeof error.response.data === 'object' && 'code' in error.response.data) {
+                return Promise.reject(new ApiError(error.response.data));
+            }
+            return Promise.reject(error);
+        },
+    );
+};
@@ -0,0 +1,69 @@
+import axios from 'axios';
+import { attachProblemInterceptor } from './problem';
+
+// Session is the coordinator session returned by POST /auth/login. It is kept in sessionStorage, so it ends when
+// the browser tab closes, and nothing secret is built into the JavaScript bundle.
+export interface Session {
+    token: string;
+    expiresAt: string;
+    principal: { role: string; name: string };
+}
+
+const storageKey = 'placementSession';
+const listeners = new Set<(session: Session | null) => void>();
+
+const authClient = axios.create({
+    baseURL: 'http://localhost:8080',
+    headers: { 'Content-Type': 'application/json' },
+});
+attachProblemInterceptor(authClient);
+
+// Returns the stored session, or null if there is none or it has expired.
+export const getSession = (): Session | null => {
+    const raw = sessionStorage.getItem(storageKey);
+    if (!raw) {
+        return null;
+    }
+    const session = JSON.parse(raw) as Session;
+    if (new Date(session.expiresAt).getTime() <= Date.now()) {
+        sessionStorage.removeItem(storageKey);
+        return null;
+    }
+    return session;
+};
+
+const setSession = (session: Session | null): void => {
+    if (session) {
+        sessionStorage.setItem(storageKey, JSON.stringify(session));
+    } else {
+        sessionStorage.removeItem(storageKey);
+    }
+    listeners.forEach((listener) => listener(session));
+};
+
+// Calls listener whenever the user logs in or out, or the session is rejected. Returns an unsubscribe function.
+export const onSessionChange = (listener: (session: Session | null) => void): (() => void) => {
+    listeners.add(listener);
+    return () => {
+        listeners.delete(listener);
+    };
+};
+
+// Exchanges a coordinator account's name and password for a session token.
+export const login = async (name: string, password: string): Promise<Session> => {
+    const { data } = await authClient.post<Session>('/auth/login', { name, password });
+    setSession(data);
+    return data;
+};
+
+// Revokes the session token on the server and forgets it locally.
+export const logout = async (): Promise<void> => {
+    const session = getSession();
+    setSession(null);
+    if (session) {
+        await authClient.post('/auth/logout', null, { headers: { Authorization: `Bearer ${session.token}` } }).catch(() => undefined);
+    }
+};
+
+// Forgets a session the server no longer accepts, e.g. after it expired or the server restarted.
+export const clearSession = (): void => setSession(null);
@@ -1,18 +1,14 @@
-import axios from 'axios';
+import { createApiClient } from './client';
 import { Student } from '../interfaces/student';
+import { ListPage } from '../interfaces/list';
 
 // apiClient is an axios instance pre-configured with the base URL for the Go backend API.
-const apiClient = axios.create({
-    baseURL: 'http://localhost:8080', // Base URL for the Go backend API
-    headers: {
-        'Content-Type': 'application/json',
-    },
-});
+const apiClient = createApiClient();
 
 // Fetches all students from the backend.
 export const getStudents = async (): Promise<Student[]> => {
-    const { data } = await apiClient.get<Student[]>('/students');
-    return data;
+    const { data } = await apiClient.get<ListPage<Student>>('/students');
+    return data.items;
 };
 
 // Fetches a single student by their ID from the backend.
@@ -2,4 +2,5 @@ export interface Company {
     id: string;
     name: string;
     offeredSalary: number; // We only strictly need id and name for the dropdown
+    roles?: string[];
 } 
\ No newline at end of file
@@ -11,4 +11,11 @@ export interface EligibilityResult {
     isEligible: boolean;
     reasons: string[];
     policySpecifics?: string;
+    decisions?: PolicyDecision[];
+}
+
+export interface PolicyDecision {
+    policy: string; // e.g. "offerCategory", or "customRule:<name>"
+    code: string;
+    outcome: 'blocked' | 'allowed' | 'overridden';
 } 
\ No newline at end of file
@@ -0,0 +1,7 @@
+// One page of a list endpoint such as GET /students or GET /companies.
+export interface ListPage<T> {
+    items: T[];
+    total: number; // Matches across all pages
+    offset: number;
+    limit: number; // 0 when no limit was requested
+}
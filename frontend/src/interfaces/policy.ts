@@ -29,6 +29,19 @@ export interface OfferCategoryPolicy {
     requiredHikePercentage: number;
 }
 
+// VersionedPolicyConfig is what the API returns: the policy sections plus the server-managed version,
+// which is sent back as If-Match so concurrent edits are detected.
+export type VersionedPolicyConfig = PolicyConfig & { version?: number };
+
+// CustomRule is a named condition in the rule expression language, evaluated after the built-in policies.
+export interface CustomRule {
+    name: string;
+    enabled: boolean;
+    condition: string; // e.g. "student.isPlaced && company.offeredSalary < 1.5 * student.currentSalary"
+    effect: 'block' | 'allow' | 'override';
+    message?: string;
+}
+
 export interface PolicyConfig {
     maximumCompanies: MaximumCompaniesPolicy;
     dreamOffer: DreamOfferPolicy;
@@ -36,4 +49,5 @@ export interface PolicyConfig {
     cgpaThreshold: CGPAThresholdPolicy;
     placementPercentage: PlacementPercentagePolicy;
     offerCategory: OfferCategoryPolicy;
+    customRules?: CustomRule[];
 } 
\ No newline at end of file
@@ -7,5 +7,8 @@ export interface Student {
     companiesApplied: number;
     dreamOffer: number;
     dreamCompany: string;
+    department?: string;
+    rollNumber?: string;
+    email?: string;
     currentOfferCategory?: string;
 } 
\ No newline at end of file
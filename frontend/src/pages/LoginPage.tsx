@@ -0,0 +1,68 @@
+import React, { useState } from 'react';
+import {
+    Alert,
+    Box,
+    Button,
+    Container,
+    Paper,
+    TextField,
+    Typography,
+} from '@mui/material';
+import { login } from '../api/session';
+
+// LoginPage asks a coordinator for their account name and password and starts a session.
+const LoginPage: React.FC = () => {
+    const [name, setName] = useState('');
+    const [password, setPassword] = useState('');
+    const [error, setError] = useState<string | null>(null);
+    const [submitting, setSubmitting] = useState(false);
+
+    const handleSubmit = async (event: React.FormEvent) => {
+        event.preventDefault();
+        setSubmitting(true);
+        setError(null);
+        try {
+            await login(name, password); // App re-renders with the session once it is stored.
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Login failed');
+            setSubmitting(false);
+        }
+    };
+
+    return (
+        <Container maxWidth="xs">
+            <Paper sx={{ p: 4, mt: 6 }}>
+                <Typography variant="h5" gutterBottom>
+                    Coordinator Login
+                </Typography>
+                {error && <Alert severity="error" sx={{ mb: 2 }}>{error}</Alert>}
+                <Box component="form" onSubmit={handleSubmit}>
+                    <TextField
+                        label="Name"
+                        value={name}
+                        onChange={(e) => setName(e.target.value)}
+                        autoComplete="username"
+                        fullWidth
+                        required
+                        margin="normal"
+                    />
+                    <TextField
+                        label="Password"
+                        type="password"
+                        value={password}
+                        onChange={(e) => setPassword(e.target.value)}
+                        autoComplete="current-password"
+                        fullWidth
+                        required
+                        margin="normal"
+                    />
+                    <Button type="submit" variant="contained" color="primary" fullWidth sx={{ mt: 2 }} disabled={submitting}>
+                        Log in
+                    </Button>
+                </Box>
+            </Paper>
+        </Container>
+    );
+};
+
+export default LoginPage;
@@ -8,6 +8,7 @@ import Paper from '@mui/material/Paper';
 import CircularProgress from '@mui/material/CircularProgress';
 import Alert from '@mui/material/Alert';
 import PolicyForm from '../components/PolicyForm'; // Import the actual form
+import { ApiError } from '../api/problem';
 
 const PolicyEditorPage: React.FC = () => {
     const queryClient = useQueryClient();
@@ -24,6 +25,11 @@ const PolicyEditorPage: React.FC = () => {
             alert('Policies updated successfully!');
         },
         onError: (error) => {
+            if (error instanceof ApiError && error.problem.code === 'precondition_failed') {
+                alert('Another coordinator changed the policies after you opened this page. The latest version has been reloaded; please reapply your changes.');
+                queryClient.invalidateQueries({ queryKey: ['policies'] });
+                return;
+            }
             alert(`Error updating policies: ${error.message}`);
         },
     });
@@ -5,3 +5,5 @@ go 1.22
 require github.com/go-chi/chi/v5 v5.2.1
 
 require github.com/go-chi/cors v1.2.1
+
+require gopkg.in/yaml.v3 v3.0.1
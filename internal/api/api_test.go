@@ -0,0 +1,21 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// serve sends one request through a router that maps method and pattern to handler, so URL parameters resolve as
+// they do in the server, and returns the recorded response.
+func serve(t *testing.T, handler http.Handler, method, pattern, target string, body io.Reader) *httptest.ResponseRecorder {
+	t.Helper()
+	router := chi.NewRouter()
+	router.Method(method, pattern, handler)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, target, body))
+	return rec
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/auth"
+	"go-placement-policy/internal/problem"
+)
+
+// actorFromRequest identifies who is making the request for audit purposes.
+func actorFromRequest(r *http.Request) string {
+	if principal, ok := auth.FromContext(r.Context()); ok {
+		return principal.Actor()
+	}
+	return "anonymous"
+}
+
+// recordAudit stamps the entry with the request's actor and appends it to the audit log.
+// Write failures are logged rather than failing the request, since the mutation has already been applied.
+func recordAudit(r *http.Request, entry audit.Entry) {
+	entry.Actor = actorFromRequest(r)
+	if _, err := audit.Record(entry); err != nil {
+		slog.ErrorContext(r.Context(), "failed to persist audit entry", "action", entry.Action, "entityType", entry.EntityType, "entityId", entry.EntityID, "error", err)
+	}
+}
+
+// auditPageSize is the number of audit entries returned when the request does not set limit.
+const auditPageSize = 100
+
+// GetAuditLogHandler returns audit entries, oldest first, optionally filtered by the query parameters
+// actor, action, entityType, entityId, from and to (RFC 3339 timestamps; from inclusive, to exclusive).
+// Entries are paged by afterId (the last ID already received) and limit; when more entries match, a Link
+// header with rel="next" points at the following page.
+func GetAuditLogHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	filter := audit.Filter{
+		Actor:      query.values.Get("actor"),
+		Action:     query.values.Get("action"),
+		EntityType: query.values.Get("entityType"),
+		EntityID:   query.values.Get("entityId"),
+		From:       query.timestamp("from"),
+		To:         query.timestamp("to"),
+		AfterID:    int64(query.nonNegativeInt("afterId")),
+		Limit:      query.nonNegativeInt("limit"),
+	}
+	switch {
+	case filter.Limit > maxPageSize:
+		query.errors = append(query.errors, problem.FieldError{Field: "limit", Message: "must not exceed " + strconv.Itoa(maxPageSize)})
+	case filter.Limit == 0:
+		filter.Limit = auditPageSize
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	limit := filter.Limit
+	filter.Limit++ // One extra entry tells whether there is a next page.
+	entries := audit.Query(filter)
+	if len(entries) > limit {
+		entries = entries[:limit]
+		next := r.URL.Query()
+		next.Set("afterId", strconv.FormatInt(entries[limit-1].ID, 10))
+		next.Set("limit", strconv.Itoa(limit))
+		w.Header().Set("Link", "<"+r.URL.Path+"?"+next.Encode()+`>; rel="next"`)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
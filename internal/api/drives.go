@@ -0,0 +1,407 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/schedule"
+	"go-placement-policy/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// driveIDParam parses the {driveID} URL parameter. On failure it writes an invalid_path_parameter problem and returns false.
+func driveIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	driveID, err := strconv.Atoi(chi.URLParam(r, "driveID"))
+	if err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidPathParam, "Invalid drive ID format in URL path",
+			problem.FieldError{Field: "driveID", Message: "must be an integer"})
+		return 0, false
+	}
+	return driveID, true
+}
+
+// driveNotFound writes a drive_not_found problem for the given ID.
+func driveNotFound(w http.ResponseWriter, r *http.Request, driveID int) {
+	problem.Respond(w, r, http.StatusNotFound, problem.CodeDriveNotFound, "Drive not found for ID: "+strconv.Itoa(driveID))
+}
+
+// validateDrive checks a drive submitted by a coordinator, including that its company exists.
+func validateDrive(d models.Drive) []problem.FieldError {
+	var errs []problem.FieldError
+	if _, found := storage.FindCompanyByID(d.CompanyID); !found {
+		errs = append(errs, problem.FieldError{Field: "companyId", Message: "must name an existing company"})
+	}
+	if d.RegistrationOpensAt.IsZero() {
+		errs = append(errs, problem.FieldError{Field: "registrationOpensAt", Message: "is required"})
+	}
+	if d.RegistrationClosesAt.IsZero() {
+		errs = append(errs, problem.FieldError{Field: "registrationClosesAt", Message: "is required"})
+	} else if !d.RegistrationClosesAt.After(d.RegistrationOpensAt) {
+		errs = append(errs, problem.FieldError{Field: "registrationClosesAt", Message: "must be after registrationOpensAt"})
+	}
+	if d.TestDate != nil && d.TestDate.Before(d.RegistrationOpensAt) {
+		errs = append(errs, problem.FieldError{Field: "testDate", Message: "cannot be before registration opens"})
+	}
+	for i, t := range d.InterviewDates {
+		if t.Before(d.RegistrationOpensAt) {
+			errs = append(errs, problem.FieldError{Field: fmt.Sprintf("interviewDates[%d]", i), Message: "cannot be before registration opens"})
+		}
+	}
+	for i, round := range d.Rounds {
+		field := fmt.Sprintf("rounds[%d].name", i)
+		switch {
+		case round.Name == "":
+			errs = append(errs, problem.FieldError{Field: field, Message: "is required"})
+		case strings.EqualFold(round.Name, models.ScreeningRound):
+			errs = append(errs, problem.FieldError{Field: field, Message: "is reserved for the stage before the first round"})
+		case d.RoundIndex(round.Name) != i:
+			errs = append(errs, problem.FieldError{Field: field, Message: "duplicates an earlier round"})
+		}
+	}
+	return errs
+}
+
+// validateRoundChanges refuses to drop a round that candidates are still waiting on.
+func validateRoundChanges(d models.Drive) []problem.FieldError {
+	var errs []problem.FieldError
+	waiting := map[string]int{}
+	for _, a := range storage.ApplicationsForDrive(d.ID) {
+		if a.Status == models.ApplicationStatusInProgress && d.RoundIndex(a.CurrentRound) < 0 {
+			waiting[a.CurrentRound]++
+		}
+	}
+	for round, n := range waiting {
+		errs = append(errs, problem.FieldError{Field: "rounds", Message: fmt.Sprintf("cannot remove round %q while %d candidate(s) are in it", round, n)})
+	}
+	return errs
+}
+
+// normalizeDrive trims free-text fields and drops empty roles.
+func normalizeDrive(d *models.Drive) {
+	d.Venue = strings.TrimSpace(d.Venue)
+	for i := range d.Rounds {
+		d.Rounds[i].Name = strings.TrimSpace(d.Rounds[i].Name)
+	}
+	roles := d.Roles[:0]
+	for _, role := range d.Roles {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	d.Roles = roles
+}
+
+// GetDrivesHandler lists drives ordered by registration deadline, optionally for one companyId.
+// open=true limits the list to drives whose registration window is open now.
+func GetDrivesHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	open := query.bool("open")
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	drives := storage.ListDrives(r.URL.Query().Get("companyId"))
+	if open != nil {
+		now := time.Now()
+		filtered := []models.Drive{}
+		for _, d := range drives {
+			if d.RegistrationOpen(now) == *open {
+				filtered = append(filtered, d)
+			}
+		}
+		drives = filtered
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drives)
+}
+
+// GetDriveHandler returns a single drive.
+func GetDriveHandler(w http.ResponseWriter, r *http.Request) {
+	driveID, ok := driveIDParam(w, r)
+	if !ok {
+		return
+	}
+	drive, found := storage.FindDriveByID(driveID)
+	if !found {
+		driveNotFound(w, r, driveID)
+		return
+	}
+	if notModified(w, r, drive.Version) {
+		return
+	}
+
+	setETag(w, drive.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drive)
+}
+
+// CreateDriveHandler schedules a new drive for an existing company.
+func CreateDriveHandler(w http.ResponseWriter, r *http.Request) {
+	var drive models.Drive
+	if !decodeJSON(w, r, &drive) {
+		return
+	}
+	normalizeDrive(&drive)
+	if fieldErrors := validateDrive(drive); len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Drive data failed validation", fieldErrors...)
+		return
+	}
+
+	drive = storage.AddDrive(drive)
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionDriveCreate,
+		EntityType: audit.EntityDrive,
+		EntityID:   strconv.Itoa(drive.ID),
+		After:      audit.Snapshot(drive),
+	})
+
+	setETag(w, drive.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(drive)
+}
+
+// UpdateDriveHandler replaces a drive, honouring If-Match. Students already registered keep their registration
+// even if the new dates now clash; GET /drives/conflicts reports such clashes. Rounds may be renamed or reordered
+// only while no candidate is waiting on a round that would disappear.
+func UpdateDriveHandler(w http.ResponseWriter, r *http.Request) {
+	driveID, ok := driveIDParam(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var drive models.Drive
+	if !decodeJSON(w, r, &drive) {
+		return
+	}
+	normalizeDrive(&drive)
+	drive.ID = driveID
+	fieldErrors := validateDrive(drive)
+	if len(fieldErrors) == 0 {
+		fieldErrors = validateRoundChanges(drive)
+	}
+	if len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Drive data failed validation", fieldErrors...)
+		return
+	}
+
+	previous, drive, err := storage.ReplaceDrive(drive, expectedVersion)
+	if isConflict(err) {
+		versionConflict(w, r, "drive", drive.Version)
+		return
+	} else if err != nil {
+		driveNotFound(w, r, driveID)
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionDriveUpdate,
+		EntityType: audit.EntityDrive,
+		EntityID:   strconv.Itoa(driveID),
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(drive),
+	})
+
+	setETag(w, drive.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(drive)
+}
+
+// StudentScheduleConflict lists a student's registered drives that fall on the same day.
+type StudentScheduleConflict struct {
+	StudentID   int                 `json:"studentId"`
+	StudentName string              `json:"studentName"`
+	DriveID     int                 `json:"driveId"`
+	Conflicts   []schedule.Conflict `json:"conflicts"`
+}
+
+// GetDriveConflictsHandler reports every student registered for two or more drives on the same campus day.
+// New registrations are refused when they would clash, so this mainly catches drives rescheduled afterwards.
+func GetDriveConflictsHandler(w http.ResponseWriter, r *http.Request) {
+	drivesByStudent := map[int][]models.Drive{}
+	var studentOrder []int
+	for _, a := range storage.AllApplications() {
+		if a.DriveID == 0 {
+			continue
+		}
+		drive, found := storage.FindDriveByID(a.DriveID)
+		if !found {
+			continue
+		}
+		if _, seen := drivesByStudent[a.StudentID]; !seen {
+			studentOrder = append(studentOrder, a.StudentID)
+		}
+		drivesByStudent[a.StudentID] = append(drivesByStudent[a.StudentID], drive)
+	}
+
+	result := []StudentScheduleConflict{}
+	for _, studentID := range studentOrder {
+		drives := drivesByStudent[studentID]
+		student, _ := storage.FindStudentByID(studentID)
+		for i, drive := range drives {
+			// Compare each drive only with later ones so every clashing pair is reported once.
+			if conflicts := schedule.Conflicts(drive, drives[i+1:]); len(conflicts) > 0 {
+				result = append(result, StudentScheduleConflict{StudentID: studentID, StudentName: student.FullName, DriveID: drive.ID, Conflicts: conflicts})
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// scheduleConflictError aborts an application that would clash with the student's other drives.
+type scheduleConflictError struct {
+	conflicts []schedule.Conflict
+}
+
+func (e *scheduleConflictError) Error() string {
+	return fmt.Sprintf("drive clashes with %d registered drive(s)", len(e.conflicts))
+}
+
+// selectDrive picks the drive an application registers for. If driveID is zero, the company's drive with an
+// open registration window is used; companies without any drives accept applications at any time (nil drive).
+// It writes a problem and returns false if the drive is unknown or its registration window is not open.
+func selectDrive(w http.ResponseWriter, r *http.Request, company models.Company, driveID int) (*models.Drive, bool) {
+	drives := storage.ListDrives(company.ID)
+	now := time.Now()
+	if driveID != 0 {
+		for _, d := range drives {
+			if d.ID == driveID {
+				if !d.RegistrationOpen(now) {
+					registrationClosed(w, r, company, []models.Drive{d})
+					return nil, false
+				}
+				return &d, true
+			}
+		}
+		problem.Respond(w, r, http.StatusNotFound, problem.CodeDriveNotFound,
+			fmt.Sprintf("Drive %d is not a drive of company %s", driveID, company.ID))
+		return nil, false
+	}
+	if len(drives) == 0 {
+		return nil, true
+	}
+	for _, d := range drives {
+		if d.RegistrationOpen(now) {
+			return &d, true
+		}
+	}
+	registrationClosed(w, r, company, drives)
+	return nil, false
+}
+
+// registrationClosed writes a registration_closed problem listing the company's drive windows.
+func registrationClosed(w http.ResponseWriter, r *http.Request, company models.Company, drives []models.Drive) {
+	p := problem.New(http.StatusConflict, problem.CodeRegistrationClosed, "Registration for "+company.Name+" is not open")
+	p.Extensions = map[string]interface{}{"drives": drives}
+	problem.Write(w, r, p)
+}
+
+// checkScheduleConflicts returns an AddApplication check that refuses drive if it shares a day with a drive
+// the student has already registered for.
+func checkScheduleConflicts(drive *models.Drive) func([]models.Application) error {
+	if drive == nil {
+		return nil
+	}
+	return func(existing []models.Application) error {
+		var registered []models.Drive
+		for _, a := range existing {
+			if a.DriveID == 0 {
+				continue
+			}
+			if d, found := storage.FindDriveByID(a.DriveID); found {
+				registered = append(registered, d)
+			}
+		}
+		if conflicts := schedule.Conflicts(*drive, registered); len(conflicts) > 0 {
+			return &scheduleConflictError{conflicts: conflicts}
+		}
+		return nil
+	}
+}
+
+// respondApplicationError maps an AddApplication error to a problem response.
+func respondApplicationError(w http.ResponseWriter, r *http.Request, company models.Company, err error) {
+	var conflictErr *scheduleConflictError
+	switch {
+	case errors.Is(err, storage.ErrAlreadyApplied):
+		problem.Respond(w, r, http.StatusConflict, problem.CodeAlreadyApplied, "Already applied to company "+company.ID)
+	case isConflict(err):
+		problem.Respond(w, r, http.StatusConflict, problem.CodePreconditionFailed,
+			"Your record kept changing while the application was checked; try again")
+	case errors.As(err, &conflictErr):
+		p := problem.New(http.StatusConflict, problem.CodeScheduleConflict,
+			"This drive falls on the same day as another drive you are registered for")
+		p.Extensions = map[string]interface{}{"conflicts": conflictErr.conflicts}
+		problem.Write(w, r, p)
+	default:
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not store application")
+	}
+}
+
+// writeCalendar sends the drives as an iCalendar feed.
+func writeCalendar(w http.ResponseWriter, name string, drives []models.Drive) {
+	var events []schedule.Event
+	for _, d := range drives {
+		company, _ := storage.FindCompanyByID(d.CompanyID)
+		events = append(events, schedule.DriveEvents(d, company.Name)...)
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	schedule.WriteICS(w, name, events, time.Now())
+}
+
+// registeredDrives returns the drives the student has registered for through applications.
+func registeredDrives(studentID int) []models.Drive {
+	drives := []models.Drive{}
+	for _, a := range storage.ApplicationsForStudent(studentID) {
+		if a.DriveID == 0 {
+			continue
+		}
+		if d, found := storage.FindDriveByID(a.DriveID); found {
+			drives = append(drives, d)
+		}
+	}
+	return drives
+}
+
+// GetCampusCalendarHandler serves every drive as an iCalendar feed.
+func GetCampusCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	writeCalendar(w, "Campus placement drives", storage.ListDrives(""))
+}
+
+// GetStudentCalendarHandler serves the drives a student has registered for as an iCalendar feed.
+func GetStudentCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	student, found := storage.FindStudentByID(studentID)
+	if !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+	writeCalendar(w, "Placement drives for "+student.FullName, registeredDrives(student.ID))
+}
+
+// GetMyCalendarHandler is GetStudentCalendarHandler for the authenticated student.
+func GetMyCalendarHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+	writeCalendar(w, "My placement drives", registeredDrives(student.ID))
+}
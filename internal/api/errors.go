@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/mail"
+	"regexp"
+	"strconv"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// decodeJSON decodes the request body into v. On failure it writes an invalid_json problem and returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Invalid JSON payload: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// studentIDParam parses the {studentID} URL parameter. On failure it writes an invalid_path_parameter problem and returns false.
+func studentIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	studentIDStr := chi.URLParam(r, "studentID")
+	studentID, err := strconv.Atoi(studentIDStr)
+	if err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidPathParam, "Invalid student ID format in URL path",
+			problem.FieldError{Field: "studentID", Message: "must be an integer"})
+		return 0, false
+	}
+	return studentID, true
+}
+
+// studentNotFound writes a student_not_found problem for the given ID.
+func studentNotFound(w http.ResponseWriter, r *http.Request, studentID int) {
+	problem.Respond(w, r, http.StatusNotFound, problem.CodeStudentNotFound, "Student not found for ID: "+strconv.Itoa(studentID))
+}
+
+// companyNotFound writes a company_not_found problem for the given ID.
+func companyNotFound(w http.ResponseWriter, r *http.Request, companyID string) {
+	problem.Respond(w, r, http.StatusNotFound, problem.CodeCompanyNotFound, "Company not found for ID: "+companyID)
+}
+
+// duplicateRollNumber writes a duplicate_roll_number conflict for the given roll number.
+func duplicateRollNumber(w http.ResponseWriter, r *http.Request, rollNumber string) {
+	problem.Respond(w, r, http.StatusConflict, problem.CodeDuplicateRollNo, "Another student already has roll number "+rollNumber,
+		problem.FieldError{Field: "rollNumber", Message: "must be unique"})
+}
+
+// validateStudent checks the fields of a student submitted by a client.
+func validateStudent(s models.Student) []problem.FieldError {
+	var errs []problem.FieldError
+	if s.FullName == "" {
+		errs = append(errs, problem.FieldError{Field: "name", Message: "cannot be empty"})
+	}
+	if s.CGPA < 0 || s.CGPA > 10 {
+		errs = append(errs, problem.FieldError{Field: "cgpa", Message: "must be between 0 and 10"})
+	}
+	if s.CurrentSalary < 0 {
+		errs = append(errs, problem.FieldError{Field: "currentSalary", Message: "cannot be negative"})
+	}
+	if s.NumCompaniesApplied < 0 {
+		errs = append(errs, problem.FieldError{Field: "companiesApplied", Message: "cannot be negative"})
+	}
+	if s.DreamOfferAmount < 0 {
+		errs = append(errs, problem.FieldError{Field: "dreamOffer", Message: "cannot be negative"})
+	}
+	if s.Email != "" {
+		if addr, err := mail.ParseAddress(s.Email); err != nil || addr.Address != s.Email {
+			errs = append(errs, problem.FieldError{Field: "email", Message: "must be a plain email address such as name@example.edu"})
+		}
+	}
+	return errs
+}
+
+// companyIDPattern restricts company IDs to characters that are safe in URL paths, e.g. "C001".
+var companyIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// validateCompany checks the fields of a company submitted by a client.
+func validateCompany(c models.Company) []problem.FieldError {
+	var errs []problem.FieldError
+	if !companyIDPattern.MatchString(c.ID) {
+		errs = append(errs, problem.FieldError{Field: "id", Message: "must be 1-32 letters, digits, '-' or '_', starting with a letter or digit"})
+	}
+	if c.Name == "" {
+		errs = append(errs, problem.FieldError{Field: "name", Message: "cannot be empty"})
+	}
+	if c.OfferedSalary <= 0 {
+		errs = append(errs, problem.FieldError{Field: "offeredSalary", Message: "must be greater than 0"})
+	}
+	return errs
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// etagFor formats a record version as a strong ETag.
+func etagFor(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// setETag sets the ETag header for a record at the given version.
+func setETag(w http.ResponseWriter, version int64) {
+	w.Header().Set("ETag", etagFor(version))
+}
+
+// notModified answers a conditional GET with 304 when If-None-Match already names the current version.
+func notModified(w http.ResponseWriter, r *http.Request, version int64) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	current := etagFor(version)
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == current || tag == "*" {
+			setETag(w, version)
+			w.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatchVersion reads the version a mutation is conditioned on from the If-Match header.
+// A missing header or "*" yields 0, meaning the update is unconditional.
+// A malformed header writes a problem response and returns false.
+func ifMatchVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, true
+	}
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version <= 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidHeader, "If-Match must be a single ETag previously returned by this API",
+			problem.FieldError{Field: "If-Match", Message: "expected a value like \"3\""})
+		return 0, false
+	}
+	return version, true
+}
+
+// versionConflict writes a 412 problem naming the record's current version so the client can reload it.
+func versionConflict(w http.ResponseWriter, r *http.Request, resource string, currentVersion int64) {
+	setETag(w, currentVersion)
+	p := problem.New(http.StatusPreconditionFailed, problem.CodePreconditionFailed,
+		"The "+resource+" was modified by someone else since you loaded it; reload and reapply your changes")
+	p.Extensions = map[string]interface{}{"currentVersion": currentVersion}
+	problem.Write(w, r, p)
+}
+
+// isConflict reports whether err is a storage version conflict.
+func isConflict(err error) bool {
+	return errors.Is(err, storage.ErrVersionConflict)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/problem"
+)
+
+// eventKeepAlive is how often an idle event stream sends a comment line, so proxies do not close the connection.
+const eventKeepAlive = 15 * time.Second
+
+// eventRetry is the reconnection delay suggested to EventSource clients.
+const eventRetry = 3 * time.Second
+
+// StreamEventsHandler streams change events as Server-Sent Events. Each event's SSE type is the change that
+// caused it (e.g. "student.update" or "offer.record") and its data is the events.Event as JSON.
+// Optional topic parameters (repeated or comma-separated) limit the stream to those topics. A client that
+// reconnects with the Last-Event-ID header (or lastEventId parameter) first receives the events it missed;
+// if they are no longer buffered it receives a "reset" event and should reload its data.
+func StreamEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	var topics []string
+	for _, raw := range r.URL.Query()["topic"] {
+		for _, topic := range strings.Split(raw, ",") {
+			if topic = strings.TrimSpace(topic); topic == "" {
+				continue
+			}
+			if !slices.Contains(events.Topics, topic) {
+				query.errors = append(query.errors, problem.FieldError{Field: "topic",
+					Message: fmt.Sprintf("unknown topic %q; must be one of %s", topic, strings.Join(events.Topics, ", "))})
+				continue
+			}
+			topics = append(topics, topic)
+		}
+	}
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("lastEventId")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || lastID < 0 {
+			query.errors = append(query.errors, problem.FieldError{Field: "lastEventId", Message: "must be a non-negative integer"})
+		}
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Streaming is not supported by this connection")
+		return
+	}
+	// Streams outlive the server's write timeout, so it does not apply to them.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		slog.WarnContext(r.Context(), "could not lift write deadline for event stream", "error", err)
+	}
+
+	subscription, replay, complete := events.Subscribe(topics, lastID)
+	defer subscription.Cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // Stop nginx from buffering the stream.
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry.Milliseconds())
+	if !complete {
+		fmt.Fprintf(w, "event: reset\ndata: {\"reason\":\"events after %d are no longer available\"}\n\n", lastID)
+	}
+	for _, e := range replay {
+		writeEvent(w, e)
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case e, open := <-subscription.C:
+			if !open {
+				return // Dropped for falling behind; the client reconnects with Last-Event-ID.
+			}
+			writeEvent(w, e)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes one event in SSE framing. The JSON encoding never contains newlines, so it fits one data line.
+func writeEvent(w http.ResponseWriter, e events.Event) {
+	data, _ := json.Marshal(e)
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+}
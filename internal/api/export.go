@@ -0,0 +1,193 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/export"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// exportFlushEvery is the number of rows written between flushes to the client, so large exports
+// reach the client progressively instead of piling up in buffers.
+const exportFlushEvery = 200
+
+// exportFormat reads the format query parameter (csv, jsonl or xlsx; default csv).
+func (p *queryParser) exportFormat() export.Format {
+	format, err := export.ParseFormat(p.values.Get("format"))
+	if err != nil {
+		p.errors = append(p.errors, problem.FieldError{Field: "format", Message: "must be csv, jsonl or xlsx"})
+	}
+	return format
+}
+
+// streamExport sends a table as a file download named after name and today's date. produce is called with
+// an emit function that writes one row; rows are flushed to the client as they are produced.
+// Once the first byte is sent the status can no longer change, so later write errors (usually a client
+// that went away) are only logged.
+func streamExport(w http.ResponseWriter, r *http.Request, format export.Format, name string, columns []string, produce func(emit func(values ...interface{}) error) error) {
+	filename := fmt.Sprintf("%s-%s.%s", name, time.Now().UTC().Format("20060102"), format)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	writer, err := export.NewWriter(w, format, name, columns)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "could not start export", "export", name, "error", err)
+		return
+	}
+	flusher, _ := w.(http.Flusher)
+	rows := 0
+	emit := func(values ...interface{}) error {
+		if err := writer.WriteRow(values); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return nil
+	}
+	if err := produce(emit); err != nil {
+		slog.ErrorContext(r.Context(), "export aborted", "export", name, "rows", rows, "error", err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		slog.ErrorContext(r.Context(), "could not finish export", "export", name, "error", err)
+	}
+}
+
+// ExportStudentsHandler exports the students matching the GET /students filters, in the requested sort order.
+func ExportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	filter := query.studentFilter()
+	opts := listOptions{Sort: query.sortKeys(sortFields(studentComparators))}
+	format := query.exportFormat()
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	students := sortAndPage(storage.ListStudents(filter), opts, studentComparators)
+	config := storage.ActivePolicy()
+	columns := []string{"id", "rollNumber", "name", "department", "cgpa", "isPlaced", "currentSalary", "offerCategory", "companiesApplied", "dreamOffer", "dreamCompany"}
+	streamExport(w, r, format, "students", columns, func(emit func(...interface{}) error) error {
+		for _, s := range students {
+			err := emit(s.ID, s.RollNumber, s.FullName, s.Department, s.CGPA, s.IsPlaced, s.CurrentSalary,
+				eligibility.OfferCategory(s, config), s.NumCompaniesApplied, s.DreamOfferAmount, s.DreamCompanyName)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportCompaniesHandler exports the companies matching the GET /companies filters, in the requested sort order.
+func ExportCompaniesHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	filter := query.companyFilter()
+	opts := listOptions{Sort: query.sortKeys(sortFields(companyComparators))}
+	format := query.exportFormat()
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	companies := sortAndPage(storage.ListCompanies(filter), opts, companyComparators)
+	streamExport(w, r, format, "companies", []string{"id", "name", "offeredSalary", "roles"}, func(emit func(...interface{}) error) error {
+		for _, c := range companies {
+			if err := emit(c.ID, c.Name, c.OfferedSalary, c.Roles); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportEligibilityMatrixHandler exports one row per student (filtered and sorted as in GET /students) with
+// one true/false column per company, named by company ID. Repeat companyId to limit the columns.
+// Eligibility is evaluated row by row as the file is written, against the policies active at that moment.
+func ExportEligibilityMatrixHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	filter := query.studentFilter()
+	opts := listOptions{Sort: query.sortKeys(sortFields(studentComparators))}
+	format := query.exportFormat()
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	companies := storage.AllCompanies()
+	if ids := r.URL.Query()["companyId"]; len(ids) > 0 {
+		companies = companies[:0]
+		for _, id := range ids {
+			company, found := storage.FindCompanyByID(id)
+			if !found {
+				companyNotFound(w, r, id)
+				return
+			}
+			companies = append(companies, company)
+		}
+	}
+
+	students := sortAndPage(storage.ListStudents(filter), opts, studentComparators)
+	columns := []string{"studentId", "rollNumber", "name"}
+	for _, c := range companies {
+		columns = append(columns, c.ID)
+	}
+	streamExport(w, r, format, "eligibility", columns, func(emit func(...interface{}) error) error {
+		row := make([]interface{}, len(columns))
+		for _, s := range students {
+			row[0], row[1], row[2] = s.ID, s.RollNumber, s.FullName
+			for i, c := range companies {
+				row[3+i] = eligibility.PerformEligibilityCheck(r.Context(), s, c).IsEligible
+			}
+			if err := emit(row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExportApplicationsHandler exports applications with student and company names, optionally filtered
+// by studentId, companyId and status.
+func ExportApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	format := query.exportFormat()
+	studentID := -1
+	if raw := r.URL.Query().Get("studentId"); raw != "" {
+		studentID = query.nonNegativeInt("studentId")
+	}
+	companyID := r.URL.Query().Get("companyId")
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.ValidApplicationStatus(status) {
+		query.errors = append(query.errors, problem.FieldError{Field: "status", Message: "must be applied, in_progress, rejected or offered"})
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	columns := []string{"id", "studentId", "rollNumber", "studentName", "companyId", "companyName", "status", "appliedAt", "reasons"}
+	streamExport(w, r, format, "applications", columns, func(emit func(...interface{}) error) error {
+		for _, a := range storage.AllApplications() {
+			if (studentID >= 0 && a.StudentID != studentID) || (companyID != "" && a.CompanyID != companyID) || (status != "" && a.Status != status) {
+				continue
+			}
+			student, _ := storage.FindStudentByID(a.StudentID)
+			company, _ := storage.FindCompanyByID(a.CompanyID)
+			err := emit(a.ID, a.StudentID, student.RollNumber, student.FullName, a.CompanyID, company.Name, a.Status, a.AppliedAt, a.Reasons)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
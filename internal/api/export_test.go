@@ -0,0 +1,138 @@
+package api
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// exportFixture stores a company and a student for the export tests. The company's name starts with "=" so
+// CSV exports must neutralise it.
+func exportFixture(t *testing.T) (models.Company, models.Student) {
+	t.Helper()
+	company := models.Company{ID: "EXPORT-CO", Name: "=Export Ltd", OfferedSalary: 650000, Roles: []string{"SDE", "QA"}}
+	storage.ImportCompanies(func(func(string) (models.Company, bool)) ([]models.Company, bool) {
+		return []models.Company{company}, true
+	})
+	student, found := storage.FindStudentByRollNumber("EXPORT-1")
+	if !found {
+		var err error
+		if student, err = storage.AddStudent(models.Student{FullName: "Export Student", RollNumber: "EXPORT-1", CGPA: 8}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return company, student
+}
+
+func getExport(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+// checkDownload fails the test unless rec is a successful download of a file in format with the given name prefix.
+func checkDownload(t *testing.T, rec *httptest.ResponseRecorder, name, format, contentType string) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if got := rec.Header().Get("Content-Type"); got != contentType {
+		t.Errorf("Content-Type = %q, want %q", got, contentType)
+	}
+	disposition := rec.Header().Get("Content-Disposition")
+	if !strings.HasPrefix(disposition, `attachment; filename="`+name+"-") || !strings.HasSuffix(disposition, "."+format+`"`) {
+		t.Errorf("Content-Disposition = %q, want a %s .%s attachment", disposition, name, format)
+	}
+}
+
+func TestExportCompaniesCSV(t *testing.T) {
+	exportFixture(t)
+	rec := getExport(ExportCompaniesHandler, "/export/companies?q=export+ltd")
+	checkDownload(t, rec, "companies", "csv", "text/csv; charset=utf-8")
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"id", "name", "offeredSalary", "roles"}, {"EXPORT-CO", "'=Export Ltd", "650000", "SDE; QA"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("CSV = %q, want %q", records, want)
+	}
+}
+
+func TestExportCompaniesJSONL(t *testing.T) {
+	exportFixture(t)
+	rec := getExport(ExportCompaniesHandler, "/export/companies?format=jsonl&q=export+ltd")
+	checkDownload(t, rec, "companies", "jsonl", "application/x-ndjson")
+
+	var rows []map[string]interface{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var row map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+		rows = append(rows, row)
+	}
+	want := []map[string]interface{}{{"id": "EXPORT-CO", "name": "=Export Ltd", "offeredSalary": 650000.0, "roles": []interface{}{"SDE", "QA"}}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("JSONL rows = %v, want %v", rows, want)
+	}
+}
+
+func TestExportCompaniesXLSX(t *testing.T) {
+	exportFixture(t)
+	rec := getExport(ExportCompaniesHandler, "/export/companies?format=xlsx&q=export+ltd")
+	checkDownload(t, rec, "companies", "xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+
+	body := rec.Body.Bytes()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("not a valid zip: %v", err)
+	}
+	if last := archive.File[len(archive.File)-1].Name; last != "xl/worksheets/sheet1.xml" {
+		t.Errorf("last entry is %s, want the worksheet", last)
+	}
+}
+
+func TestExportEligibilityMatrix(t *testing.T) {
+	company, student := exportFixture(t)
+
+	rec := getExport(ExportEligibilityMatrixHandler, "/export/eligibility?q=export+student&companyId="+company.ID)
+	checkDownload(t, rec, "eligibility", "csv", "text/csv; charset=utf-8")
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"studentId", "rollNumber", "name", company.ID}, {strconv.Itoa(student.ID), "EXPORT-1", "Export Student", "true"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("matrix = %q, want %q", records, want)
+	}
+
+	tests := []struct {
+		target string
+		want   int
+	}{
+		{"/export/eligibility?companyId=NO-SUCH-CO", http.StatusNotFound},
+		{"/export/eligibility?companyId=" + company.ID + "&companyId=NO-SUCH-CO", http.StatusNotFound},
+		{"/export/eligibility?format=pdf", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := getExport(ExportEligibilityMatrixHandler, tt.target)
+		if rec.Code != tt.want || rec.Header().Get("Content-Disposition") != "" {
+			t.Errorf("%s: status %d, Content-Disposition %q; want %d and no download",
+				tt.target, rec.Code, rec.Header().Get("Content-Disposition"), tt.want)
+		}
+	}
+}
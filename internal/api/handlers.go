@@ -2,12 +2,17 @@ package api
 
 import (
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/changes"
 	"go-placement-policy/internal/eligibility"
 	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
 	"go-placement-policy/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -15,30 +20,23 @@ import (
 
 // ConfigurePoliciesHandler accepts a POST request with a new policy configuration,
 // updates the active policy in storage, and returns the updated configuration.
+// The whole configuration is replaced; use PatchPoliciesHandler to change individual policies.
+// If an If-Match header is sent, the update is rejected with 412 unless it names the active version.
 func ConfigurePoliciesHandler(w http.ResponseWriter, r *http.Request) {
 	var newConfig models.PolicyConfig
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&newConfig); err != nil {
-		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &newConfig) {
 		return
 	}
-
-	storage.PolicyConfigMutex.Lock()
-	storage.ActivePolicyConfig = newConfig
-	storage.PolicyConfigMutex.Unlock()
-
-	log.Printf("Policy configuration updated: %+v\n", newConfig)
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(newConfig)
+	updatePolicy(w, r, audit.ActionPolicyConfigure, func(models.PolicyConfig) (models.PolicyConfig, error) {
+		return newConfig, nil
+	})
 }
 
 // CheckEligibilityHandler accepts a POST request with StudentID and CompanyID,
 // checks the student's eligibility for the company, and returns the eligibility result.
 func CheckEligibilityHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Only POST method is allowed", http.StatusMethodNotAllowed)
+		problem.Respond(w, r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Only POST method is allowed")
 		return
 	}
 
@@ -46,43 +44,30 @@ func CheckEligibilityHandler(w http.ResponseWriter, r *http.Request) {
 		StudentID int    `json:"studentId"`
 		CompanyID string `json:"companyId"`
 	}
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON format: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	var student models.Student
-	studentFound := false
-	// Note: Iterating through all students here. For larger datasets, a map or indexed lookup would be more efficient.
-	for _, s := range storage.Students {
-		if s.ID == req.StudentID {
-			student = s
-			studentFound = true
-			break
-		}
-	}
-
-	var company models.Company
-	companyFound := false
-	for _, c := range storage.Companies { // Iterate over slice
-		if c.ID == req.CompanyID {
-			company = c
-			companyFound = true
-			break
-		}
-	}
+	student, studentFound := storage.FindStudentByID(req.StudentID)
+	company, companyFound := storage.FindCompanyByID(req.CompanyID)
 
 	if !studentFound {
-		http.Error(w, "Student not found", http.StatusNotFound)
+		studentNotFound(w, r, req.StudentID)
 		return
 	}
 	if !companyFound { // Updated check
-		http.Error(w, "Company not found", http.StatusNotFound)
+		companyNotFound(w, r, req.CompanyID)
 		return
 	}
 
-	result := eligibility.PerformEligibilityCheck(student, company)
+	result := eligibility.PerformEligibilityCheck(r.Context(), student, company)
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionEligibilityChecked,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(student.ID),
+		After:      audit.Snapshot(result),
+		Reasons:    result.Reasons,
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(result)
@@ -91,74 +76,69 @@ func CheckEligibilityHandler(w http.ResponseWriter, r *http.Request) {
 // GetPoliciesHandler handles GET requests to retrieve the current active policy configuration.
 func GetPoliciesHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Only GET method is allowed", http.StatusMethodNotAllowed)
+		problem.Respond(w, r, http.StatusMethodNotAllowed, problem.CodeMethodNotAllowed, "Only GET method is allowed")
 		return
 	}
 
-	storage.PolicyConfigMutex.RLock()
-	currentConfig := storage.ActivePolicyConfig
-	storage.PolicyConfigMutex.RUnlock()
+	currentConfig := storage.ActivePolicy()
+	if notModified(w, r, currentConfig.Version) {
+		return
+	}
 
+	setETag(w, currentConfig.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(currentConfig)
 }
 
-// GetAllStudentsHandler returns a list of all students.
-// In a production system, pagination and filtering capabilities would be important here.
+// GetAllStudentsHandler returns students matching the optional filters
+// (isPlaced, minCgpa, maxCgpa, minSalary, maxSalary, dreamCompany, department, q),
+// ordered by sort (e.g. "-cgpa,name") and paginated by offset/limit.
+// The body is a listPage holding the page and the total match count; page links are in Link.
 func GetAllStudentsHandler(w http.ResponseWriter, r *http.Request) {
-	studentsToReturn := storage.Students
-	if studentsToReturn == nil {
-		studentsToReturn = []models.Student{} // Ensure a valid JSON array (empty) is returned instead of null.
+	query := &queryParser{values: r.URL.Query()}
+	filter := query.studentFilter()
+	opts := query.listOptions(sortFields(studentComparators))
+	if query.respondInvalidQuery(w, r) {
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(studentsToReturn)
+	matches := storage.ListStudents(filter)
+	writeList(w, r, sortAndPage(matches, opts, studentComparators), len(matches), opts)
 }
 
 // GetStudentByIDHandler retrieves and returns a single student by their ID from the URL path.
 func GetStudentByIDHandler(w http.ResponseWriter, r *http.Request) {
-	studentIDStr := chi.URLParam(r, "studentID")
-	studentID, err := strconv.Atoi(studentIDStr)
-	if err != nil {
-		http.Error(w, "Invalid student ID format in URL path", http.StatusBadRequest)
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
 		return
 	}
 
-	var student models.Student
-	found := false
-	// RLock for reading Students data.
-	// Consider a more granular lock if Students and PolicyConfig are frequently updated independently
-	// or if student data becomes very large and this linear scan becomes a bottleneck.
-	storage.PolicyConfigMutex.RLock()
-	for _, s := range storage.Students {
-		if s.ID == studentID {
-			student = s
-			found = true
-			break
-		}
-	}
-	storage.PolicyConfigMutex.RUnlock()
-
+	student, found := storage.FindStudentByID(studentID)
 	if !found {
-		http.Error(w, "Student not found for ID: "+studentIDStr, http.StatusNotFound)
+		studentNotFound(w, r, studentID)
+		return
+	}
+	if notModified(w, r, student.Version) {
 		return
 	}
 
+	setETag(w, student.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(student)
 }
 
-// GetAllCompaniesHandler returns a list of all companies.
-// Companies are stored in a map; this handler converts it to a slice for JSON output.
+// GetAllCompaniesHandler returns companies matching the optional filters (minSalary, maxSalary, q),
+// ordered by sort and paginated by offset/limit, in the same form as GetAllStudentsHandler.
 func GetAllCompaniesHandler(w http.ResponseWriter, r *http.Request) {
-	// Directly use the Companies slice, which is already loaded from JSON.
-	companiesToReturn := storage.Companies
-	if companiesToReturn == nil {
-		companiesToReturn = []models.Company{}
+	query := &queryParser{values: r.URL.Query()}
+	filter := query.companyFilter()
+	opts := query.listOptions(sortFields(companyComparators))
+	if query.respondInvalidQuery(w, r) {
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(companiesToReturn)
+	matches := storage.ListCompanies(filter)
+	writeList(w, r, sortAndPage(matches, opts, companyComparators), len(matches), opts)
 }
 
 // GetEligibleStudentsForCompanyHandler retrieves all students eligible for a specific company.
@@ -166,31 +146,21 @@ func GetAllCompaniesHandler(w http.ResponseWriter, r *http.Request) {
 func GetEligibleStudentsForCompanyHandler(w http.ResponseWriter, r *http.Request) {
 	companyID := chi.URLParam(r, "companyID")
 	if companyID == "" {
-		http.Error(w, "Company ID is required in URL path", http.StatusBadRequest)
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidPathParam, "Company ID is required in URL path")
 		return
 	}
 
-	var company models.Company
-	companyFound := false
-	for _, c := range storage.Companies { // Iterate over slice
-		if c.ID == companyID {
-			company = c
-			companyFound = true
-			break
-		}
-	}
-
-	if !companyFound { // Updated check, now using companyFound
-		http.Error(w, "Company not found for ID: "+companyID, http.StatusNotFound)
+	company, companyFound := storage.FindCompanyByID(companyID)
+	if !companyFound {
+		companyNotFound(w, r, companyID)
 		return
 	}
 
 	eligibleStudents := []models.Student{}
-	// Iterate through all students and check eligibility for the given company.
-	// The Students slice is read here. PolicyConfig is read within PerformEligibilityCheck (which handles its own locking).
-	// If student data modification becomes frequent and concurrent, locking storage.Students might be needed here.
-	for _, student := range storage.Students {
-		result := eligibility.PerformEligibilityCheck(student, company)
+	// Iterate through a snapshot of all students and check eligibility for the given company.
+	// PolicyConfig is read within PerformEligibilityCheck (which handles its own locking).
+	for _, student := range storage.AllStudents() {
+		result := eligibility.PerformEligibilityCheck(r.Context(), student, company)
 		if result.IsEligible {
 			eligibleStudents = append(eligibleStudents, student)
 		}
@@ -209,32 +179,167 @@ func GetEligibleStudentsForCompanyHandler(w http.ResponseWriter, r *http.Request
 // appends the student to in-memory storage, updates placement stats, and returns the created student.
 func CreateStudentHandler(w http.ResponseWriter, r *http.Request) {
 	var newStudent models.Student
-	if err := json.NewDecoder(r.Body).Decode(&newStudent); err != nil {
-		http.Error(w, "Invalid JSON payload: "+err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &newStudent) {
 		return
 	}
 
-	// Basic validation for student name.
-	if newStudent.FullName == "" {
-		http.Error(w, "Student name cannot be empty", http.StatusBadRequest)
+	// Basic validation of the submitted fields.
+	if fieldErrors := validateStudent(newStudent); len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Student data failed validation", fieldErrors...)
 		return
 	}
 
-	storage.PolicyConfigMutex.Lock() // Lock to safely modify shared Students slice and generate ID.
-	defer storage.PolicyConfigMutex.Unlock()
-
-	// Assign a new ID - simple increment for this example.
-	// In a production system, use a more robust ID generation strategy (e.g., UUID or database auto-increment).
-	if len(storage.Students) > 0 {
-		newStudent.ID = storage.Students[len(storage.Students)-1].ID + 1
-	} else {
-		newStudent.ID = 1 // First student in the system.
+	// The storage layer assigns a fresh ID from its monotonic allocator and refreshes placement stats.
+	stored, err := storage.AddStudent(newStudent)
+	if err != nil {
+		duplicateRollNumber(w, r, newStudent.RollNumber)
+		return
 	}
+	newStudent = stored
 
-	storage.Students = append(storage.Students, newStudent)
-	storage.UpdatePlacementStats() // Crucial to update stats after adding a new student.
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionStudentCreate,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(newStudent.ID),
+		After:      audit.Snapshot(newStudent),
+	})
 
+	setETag(w, newStudent.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(newStudent)
 }
+
+// UpdateStudentHandler handles PUT requests that replace an existing student's record.
+// The ID in the URL path is authoritative; any ID or version in the body is ignored.
+// If an If-Match header is sent, the update is rejected with 412 unless it names the stored version.
+func UpdateStudentHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var student models.Student
+	if !decodeJSON(w, r, &student) {
+		return
+	}
+	if fieldErrors := validateStudent(student); len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Student data failed validation", fieldErrors...)
+		return
+	}
+	student.ID = studentID
+
+	rollNumber := student.RollNumber
+	previous, student, err := storage.ReplaceStudent(student, expectedVersion)
+	if isConflict(err) {
+		versionConflict(w, r, "student", student.Version)
+		return
+	} else if errors.Is(err, storage.ErrDuplicateRollNumber) {
+		duplicateRollNumber(w, r, rollNumber)
+		return
+	} else if err != nil {
+		studentNotFound(w, r, studentID)
+		return
+	}
+
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionStudentUpdate,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(studentID),
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(student),
+	})
+
+	setETag(w, student.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(student)
+}
+
+// RecordOfferHandler records that a student received an offer from a company.
+// The student becomes placed at the offered salary, which changes their offer category for later eligibility checks.
+func RecordOfferHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CompanyID string  `json:"companyId"`
+		Salary    float64 `json:"salary"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	company, found := storage.FindCompanyByID(req.CompanyID)
+	if !found {
+		companyNotFound(w, r, req.CompanyID)
+		return
+	}
+	if req.Salary <= 0 {
+		req.Salary = company.OfferedSalary // Default to the company's advertised salary.
+	}
+
+	before, after, err := storage.RecordOffer(studentID, company.ID, req.Salary, expectedVersion)
+	if isConflict(err) {
+		versionConflict(w, r, "student", after.Version)
+		return
+	} else if err != nil {
+		studentNotFound(w, r, studentID)
+		return
+	}
+
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionOfferRecord,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(studentID),
+		Before:     audit.Snapshot(before),
+		After:      audit.Snapshot(after),
+		Reasons:    []string{fmt.Sprintf("Offer from %s (%s) at %.2f", company.Name, company.ID, req.Salary)},
+	})
+
+	setETag(w, after.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(after)
+}
+
+// GetEligibilityChangesHandler lists detected eligibility flips, oldest first. since is either a change ID
+// (only later changes are returned, so clients can poll with the last ID they saw) or an RFC 3339 timestamp.
+// studentId, companyId and eligible (true for gained, false for lost) narrow the list.
+func GetEligibilityChangesHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	filter := changes.Filter{
+		StudentID: query.nonNegativeInt("studentId"),
+		CompanyID: r.URL.Query().Get("companyId"),
+		Eligible:  query.bool("eligible"),
+	}
+	if since := r.URL.Query().Get("since"); since != "" {
+		if id, err := strconv.ParseInt(since, 10, 64); err == nil && id >= 0 {
+			filter.AfterID = id
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filter.Since = t
+		} else {
+			query.errors = append(query.errors, problem.FieldError{Field: "since", Message: "must be a change ID or an RFC 3339 timestamp"})
+		}
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes.Query(filter))
+}
+
+// GetEligibilityCacheHandler reports the size and hit/miss counters of the eligibility result cache.
+func GetEligibilityCacheHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(eligibility.Stats())
+}
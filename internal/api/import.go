@@ -0,0 +1,267 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/importer"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// maxImportBytes caps the size of an import upload.
+const maxImportBytes = 10 << 20
+
+// studentImportFields are the canonical column names accepted by ImportStudentsHandler, matching the JSON field names.
+var studentImportFields = []string{"rollNumber", "name", "cgpa", "isPlaced", "currentSalary", "companiesApplied", "dreamOffer", "dreamCompany", "department", "email"}
+
+// readImportRecords reads the request body as CSV or as a JSON array, chosen by the format query parameter
+// or else the Content-Type, applying any ?map=field:Column mappings. It also reads the dryRun flag.
+// On failure it writes a problem and returns false.
+func readImportRecords(w http.ResponseWriter, r *http.Request, fields []string) (records []importer.Record, dryRun bool, ok bool) {
+	query := &queryParser{values: r.URL.Query()}
+	if v := query.bool("dryRun"); v != nil {
+		dryRun = *v
+	}
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		format = "csv"
+		if mediaType == "application/json" {
+			format = "json"
+		}
+	}
+	if format != "csv" && format != "json" {
+		query.errors = append(query.errors, problem.FieldError{Field: "format", Message: "must be csv or json"})
+	}
+	mapping, err := importer.ParseMapping(r.URL.Query()["map"], fields)
+	if err != nil {
+		query.errors = append(query.errors, problem.FieldError{Field: "map", Message: err.Error()})
+	}
+	if query.respondInvalidQuery(w, r) {
+		return nil, false, false
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBytes)
+	if format == "json" {
+		records, err = importer.ReadJSON(body, fields, mapping)
+	} else {
+		records, err = importer.ReadCSV(body, fields, mapping)
+	}
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		problem.Respond(w, r, http.StatusRequestEntityTooLarge, problem.CodeImportFailed,
+			fmt.Sprintf("Import files are limited to %d MiB", maxImportBytes>>20))
+		return nil, false, false
+	case err != nil:
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeImportFailed, "Could not read import: "+err.Error())
+		return nil, false, false
+	}
+	return records, dryRun, true
+}
+
+// respondImport writes the outcome of an import. A report with failed rows becomes an import_failed problem
+// carrying the report, unless this was a dry run, which always succeeds so clients can show every row's result.
+func respondImport(w http.ResponseWriter, r *http.Request, report importer.Report, entity string) {
+	if report.Failed > 0 && !report.DryRun {
+		p := problem.New(http.StatusBadRequest, problem.CodeImportFailed,
+			fmt.Sprintf("%d of %d %s rows are invalid; nothing was imported", report.Failed, report.TotalRows, entity))
+		p.Extensions = map[string]interface{}{"report": report}
+		problem.Write(w, r, p)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// studentFromRecord overlays the columns present in the record onto base, so an upsert only changes
+// the fields the file provides. Field errors are collected in the returned parser.
+func studentFromRecord(record importer.Record, base models.Student) (models.Student, *importer.Parser) {
+	p := &importer.Parser{Record: record}
+	s := base
+	if s.RollNumber == "" {
+		s.RollNumber = p.String("rollNumber") // Matched students keep their stored spelling of the roll number.
+	}
+	if p.Has("name") {
+		s.FullName = p.String("name")
+	}
+	if p.Has("cgpa") {
+		s.CGPA = p.Float("cgpa")
+	}
+	if p.Has("isPlaced") {
+		s.IsPlaced = p.Bool("isPlaced")
+	}
+	if p.Has("currentSalary") {
+		s.CurrentSalary = p.Float("currentSalary")
+	}
+	if p.Has("companiesApplied") {
+		s.NumCompaniesApplied = p.Int("companiesApplied")
+	}
+	if p.Has("dreamOffer") {
+		s.DreamOfferAmount = p.Float("dreamOffer")
+	}
+	if p.Has("dreamCompany") {
+		s.DreamCompanyName = p.String("dreamCompany")
+	}
+	if p.Has("department") {
+		s.Department = p.String("department")
+	}
+	if p.Has("email") {
+		s.Email = p.String("email")
+	}
+	return s, p
+}
+
+// ImportStudentsHandler bulk-imports students from CSV or a JSON array, upserting by roll number:
+// rows whose roll number matches an existing student update only the columns present, other rows create students.
+// Every row is validated first and nothing is stored unless all rows are valid; with ?dryRun=true nothing is
+// stored at all and the report shows what would happen. Placement stats are refreshed once after the import.
+func ImportStudentsHandler(w http.ResponseWriter, r *http.Request) {
+	records, dryRun, ok := readImportRecords(w, r, studentImportFields)
+	if !ok {
+		return
+	}
+
+	report := importer.Report{DryRun: dryRun, Rows: []importer.RowResult{}}
+	stored, previous := storage.ImportStudents(func(lookup func(string) (models.Student, bool)) ([]models.Student, bool) {
+		var batch []models.Student
+		seen := map[string]int{} // Normalised roll number -> line of its first occurrence.
+		for _, record := range records {
+			rollNumber := record.Fields["rollNumber"]
+			existing, found := lookup(rollNumber)
+			student, parser := studentFromRecord(record, existing)
+
+			if rollNumber == "" {
+				parser.Fail("rollNumber", "is required to match rows to existing students")
+			} else if line, duplicate := seen[storage.IndexKey(rollNumber)]; duplicate {
+				parser.Fail("rollNumber", fmt.Sprintf("duplicates the row on line %d", line))
+			} else {
+				seen[storage.IndexKey(rollNumber)] = record.Line
+			}
+			for _, fe := range validateStudent(student) {
+				if !parser.Failed(fe.Field) { // An unparseable value already has a more specific error.
+					parser.Fail(fe.Field, fe.Message)
+				}
+			}
+
+			row := importer.RowResult{Line: record.Line, Key: rollNumber, Action: importer.ActionCreate}
+			switch {
+			case len(parser.Errors) > 0:
+				row.Action, row.Errors = importer.ActionError, parser.Errors
+			case found:
+				row.Action, row.ID = importer.ActionUpdate, strconv.Itoa(existing.ID)
+			}
+			report.Add(row)
+			batch = append(batch, student)
+		}
+		return batch, !dryRun && report.Failed == 0
+	})
+
+	report.Committed = !dryRun && report.Failed == 0
+	if report.Committed {
+		for i, student := range stored {
+			report.Rows[i].ID = strconv.Itoa(student.ID)
+			entry := audit.Entry{
+				Action:     audit.ActionStudentCreate,
+				EntityType: audit.EntityStudent,
+				EntityID:   strconv.Itoa(student.ID),
+				After:      audit.Snapshot(student),
+			}
+			if previous[i].ID != 0 {
+				entry.Action = audit.ActionStudentUpdate
+				entry.Before = audit.Snapshot(previous[i])
+			}
+			recordAudit(r, entry)
+		}
+	}
+	respondImport(w, r, report, "student")
+}
+
+// companyImportFields are the canonical column names accepted by ImportCompaniesHandler.
+var companyImportFields = []string{"id", "name", "offeredSalary", "roles"}
+
+// companyFromRecord overlays the columns present in the record onto base, like studentFromRecord.
+func companyFromRecord(record importer.Record, base models.Company) (models.Company, *importer.Parser) {
+	p := &importer.Parser{Record: record}
+	c := base
+	c.ID = p.String("id")
+	if p.Has("name") {
+		c.Name = p.String("name")
+	}
+	if p.Has("offeredSalary") {
+		c.OfferedSalary = p.Float("offeredSalary")
+	}
+	if p.Has("roles") {
+		c.Roles = p.List("roles")
+	}
+	return c, p
+}
+
+// ImportCompaniesHandler bulk-imports companies from CSV or a JSON array, upserting by company ID.
+// Roles are read from an optional roles column separated by ";" or "|". Validation, dry-run and the
+// all-or-nothing commit work as in ImportStudentsHandler.
+func ImportCompaniesHandler(w http.ResponseWriter, r *http.Request) {
+	records, dryRun, ok := readImportRecords(w, r, companyImportFields)
+	if !ok {
+		return
+	}
+
+	report := importer.Report{DryRun: dryRun, Rows: []importer.RowResult{}}
+	stored, previous := storage.ImportCompanies(func(lookup func(string) (models.Company, bool)) ([]models.Company, bool) {
+		var batch []models.Company
+		seen := map[string]int{} // Normalised company ID -> line of its first occurrence.
+		for _, record := range records {
+			id := record.Fields["id"]
+			existing, found := lookup(id)
+			company, parser := companyFromRecord(record, existing)
+
+			if line, duplicate := seen[storage.IndexKey(id)]; duplicate && id != "" {
+				parser.Fail("id", fmt.Sprintf("duplicates the row on line %d", line))
+			} else {
+				seen[storage.IndexKey(id)] = record.Line
+			}
+			for _, fe := range validateCompany(company) {
+				if !parser.Failed(fe.Field) { // An unparseable value already has a more specific error.
+					parser.Fail(fe.Field, fe.Message)
+				}
+			}
+
+			row := importer.RowResult{Line: record.Line, Key: id, ID: id, Action: importer.ActionCreate}
+			switch {
+			case len(parser.Errors) > 0:
+				row.Action, row.ID, row.Errors = importer.ActionError, "", parser.Errors
+			case found:
+				row.Action = importer.ActionUpdate
+			}
+			report.Add(row)
+			batch = append(batch, company)
+		}
+		return batch, !dryRun && report.Failed == 0
+	})
+
+	report.Committed = !dryRun && report.Failed == 0
+	if report.Committed {
+		for i, company := range stored {
+			entry := audit.Entry{
+				Action:     audit.ActionCompanyCreate,
+				EntityType: audit.EntityCompany,
+				EntityID:   company.ID,
+				After:      audit.Snapshot(company),
+			}
+			if previous[i].ID != "" {
+				entry.Action = audit.ActionCompanyUpdate
+				entry.Before = audit.Snapshot(previous[i])
+			}
+			recordAudit(r, entry)
+		}
+	}
+	respondImport(w, r, report, "company")
+}
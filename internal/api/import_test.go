@@ -0,0 +1,123 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/importer"
+	"go-placement-policy/internal/storage"
+)
+
+// postImport sends a CSV body to an import handler and decodes the report, which a failed import carries in
+// its problem's report extension.
+func postImport(t *testing.T, handler http.HandlerFunc, target, csv string) (int, importer.Report) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, target, strings.NewReader(csv)))
+	var failed struct {
+		Report *importer.Report `json:"report"`
+	}
+	var report importer.Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &failed); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+	if failed.Report != nil {
+		return rec.Code, *failed.Report
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+	return rec.Code, report
+}
+
+// rowErrors returns the first error message of each row, "" for valid rows.
+func rowErrors(report importer.Report) []string {
+	var messages []string
+	for _, row := range report.Rows {
+		message := ""
+		if len(row.Errors) > 0 {
+			message = row.Errors[0].Message
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func TestImportCompaniesIsAllOrNothing(t *testing.T) {
+	tests := []struct {
+		name       string
+		csv        string
+		wantErrors []string
+		absent     []string // Company IDs that must not have been stored.
+	}{
+		{"IDs differing only in case", "id,name,offeredSalary\nIMP-A,Alpha,500000\nimp-a,Alpha Again,600000\n",
+			[]string{"", "duplicates the row on line 2"}, []string{"IMP-A", "imp-a"}},
+		{"IDs differing only in spaces", "id,name,offeredSalary\nIMP-B,Beta,500000\n\" IMP-B\",Beta Again,600000\n",
+			[]string{"", "duplicates the row on line 2"}, []string{"IMP-B"}},
+		{"one invalid row", "id,name,offeredSalary\nIMP-C,Gamma,500000\nIMP-D,Delta,0\n",
+			[]string{"", "must be greater than 0"}, []string{"IMP-C", "IMP-D"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			revision := storage.Revision()
+			code, report := postImport(t, ImportCompaniesHandler, "/import/companies", tt.csv)
+			if code != http.StatusBadRequest || report.Committed {
+				t.Fatalf("status %d, committed %v; want 400 and nothing imported", code, report.Committed)
+			}
+			if got := rowErrors(report); !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("row errors = %q, want %q", got, tt.wantErrors)
+			}
+			for _, id := range tt.absent {
+				if _, found := storage.FindCompanyByID(id); found {
+					t.Errorf("company %s was stored by a failed import", id)
+				}
+			}
+			if storage.Revision() != revision {
+				t.Error("a failed import changed the storage revision")
+			}
+		})
+	}
+}
+
+func TestImportCompaniesCommitsEveryRow(t *testing.T) {
+	csv := "id,name,offeredSalary,roles\nIMP-E,Epsilon,700000,SDE;QA\nIMP-F,Zeta,800000,\n"
+
+	code, report := postImport(t, ImportCompaniesHandler, "/import/companies?dryRun=true", csv)
+	if code != http.StatusOK || !report.DryRun || report.Committed || report.Created != 2 {
+		t.Fatalf("dry run: status %d, report %+v", code, report)
+	}
+	if _, found := storage.FindCompanyByID("IMP-E"); found {
+		t.Fatal("a dry run stored a company")
+	}
+
+	code, report = postImport(t, ImportCompaniesHandler, "/import/companies", csv)
+	if code != http.StatusOK || !report.Committed || report.Created != 2 {
+		t.Fatalf("import: status %d, report %+v", code, report)
+	}
+	if c, _ := storage.FindCompanyByID("IMP-E"); c.Name != "Epsilon" || !reflect.DeepEqual(c.Roles, []string{"SDE", "QA"}) || c.Version != 1 {
+		t.Errorf("stored company %+v", c)
+	}
+
+	code, report = postImport(t, ImportCompaniesHandler, "/import/companies", "id,offeredSalary\nIMP-E,750000\n")
+	if c, _ := storage.FindCompanyByID("IMP-E"); code != http.StatusOK || report.Updated != 1 || c.Name != "Epsilon" || c.OfferedSalary != 750000 || c.Version != 2 {
+		t.Errorf("partial update: status %d, report %+v, stored %+v; want only the salary changed", code, report, c)
+	}
+}
+
+func TestImportStudentsRejectsRepeatedRollNumbers(t *testing.T) {
+	csv := "rollNumber,name,cgpa\nIMP-S1,Asha,8\n imp-s1 ,Asha Again,9\n"
+	code, report := postImport(t, ImportStudentsHandler, "/import/students", csv)
+	if code != http.StatusBadRequest || report.Committed {
+		t.Fatalf("status %d, committed %v; want 400 and nothing imported", code, report.Committed)
+	}
+	if got, want := rowErrors(report), []string{"", "duplicates the row on line 2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("row errors = %q, want %q", got, want)
+	}
+	if _, found := storage.FindStudentByRollNumber("IMP-S1"); found {
+		t.Error("a failed import stored a student")
+	}
+}
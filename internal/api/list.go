@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// maxPageSize caps the limit query parameter so a single request cannot page through everything at once.
+const maxPageSize = 500
+
+// sortKey is one entry of the comma-separated sort query parameter, e.g. "-cgpa".
+type sortKey struct {
+	Field      string
+	Descending bool
+}
+
+// listOptions holds the pagination and sorting parameters shared by list endpoints.
+// A zero Limit means "no limit", which keeps the unparameterised endpoints returning everything.
+type listOptions struct {
+	Offset int
+	Limit  int
+	Sort   []sortKey
+}
+
+// queryParser accumulates field errors while reading typed query parameters,
+// so a request with several bad parameters gets them all reported at once.
+type queryParser struct {
+	values url.Values
+	errors []problem.FieldError
+}
+
+func (p *queryParser) float(name string) *float64 {
+	raw := p.values.Get(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		p.errors = append(p.errors, problem.FieldError{Field: name, Message: "must be a number"})
+		return nil
+	}
+	return &v
+}
+
+func (p *queryParser) bool(name string) *bool {
+	raw := p.values.Get(name)
+	if raw == "" {
+		return nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		p.errors = append(p.errors, problem.FieldError{Field: name, Message: "must be true or false"})
+		return nil
+	}
+	return &v
+}
+
+func (p *queryParser) nonNegativeInt(name string) int {
+	raw := p.values.Get(name)
+	if raw == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 0 {
+		p.errors = append(p.errors, problem.FieldError{Field: name, Message: "must be a non-negative integer"})
+		return 0
+	}
+	return v
+}
+
+func (p *queryParser) timestamp(name string) time.Time {
+	raw := p.values.Get(name)
+	if raw == "" {
+		return time.Time{}
+	}
+	v, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		p.errors = append(p.errors, problem.FieldError{Field: name, Message: "must be an RFC 3339 timestamp"})
+	}
+	return v
+}
+
+// listOptions reads offset, limit and sort. Sort fields must be keys of allowedSort.
+func (p *queryParser) listOptions(allowedSort map[string]bool) listOptions {
+	opts := listOptions{
+		Offset: p.nonNegativeInt("offset"),
+		Limit:  p.nonNegativeInt("limit"),
+	}
+	if opts.Limit > maxPageSize {
+		p.errors = append(p.errors, problem.FieldError{Field: "limit", Message: "must not exceed " + strconv.Itoa(maxPageSize)})
+	}
+	opts.Sort = p.sortKeys(allowedSort)
+	return opts
+}
+
+// sortKeys reads the comma-separated sort parameter. Sort fields must be keys of allowedSort.
+func (p *queryParser) sortKeys(allowedSort map[string]bool) []sortKey {
+	var keys []sortKey
+	if raw := p.values.Get("sort"); raw != "" {
+		for _, field := range strings.Split(raw, ",") {
+			key := sortKey{Field: strings.TrimSpace(field)}
+			if strings.HasPrefix(key.Field, "-") {
+				key.Field = key.Field[1:]
+				key.Descending = true
+			}
+			if !allowedSort[key.Field] {
+				p.errors = append(p.errors, problem.FieldError{Field: "sort", Message: "unknown sort field '" + key.Field + "'"})
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// studentFilter reads the student list filters shared by GET /students and the student exports.
+func (p *queryParser) studentFilter() storage.StudentFilter {
+	return storage.StudentFilter{
+		IsPlaced:     p.bool("isPlaced"),
+		MinCGPA:      p.float("minCgpa"),
+		MaxCGPA:      p.float("maxCgpa"),
+		MinSalary:    p.float("minSalary"),
+		MaxSalary:    p.float("maxSalary"),
+		DreamCompany: p.values.Get("dreamCompany"),
+		Department:   p.values.Get("department"),
+		NameContains: p.values.Get("q"),
+	}
+}
+
+// companyFilter reads the company list filters shared by GET /companies and the company exports.
+func (p *queryParser) companyFilter() storage.CompanyFilter {
+	return storage.CompanyFilter{
+		MinSalary:    p.float("minSalary"),
+		MaxSalary:    p.float("maxSalary"),
+		NameContains: p.values.Get("q"),
+	}
+}
+
+// respondInvalidQuery writes the accumulated field errors, if any, and reports whether it did.
+func (p *queryParser) respondInvalidQuery(w http.ResponseWriter, r *http.Request) bool {
+	if len(p.errors) == 0 {
+		return false
+	}
+	problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidQueryParam, "Invalid query parameters", p.errors...)
+	return true
+}
+
+// sortAndPage orders items by the requested keys (falling back to storage order) and slices out the requested page.
+func sortAndPage[T any](items []T, opts listOptions, comparators map[string]func(a, b T) int) []T {
+	if len(opts.Sort) > 0 {
+		sort.SliceStable(items, func(i, j int) bool {
+			for _, key := range opts.Sort {
+				c := comparators[key.Field](items[i], items[j])
+				if key.Descending {
+					c = -c
+				}
+				if c != 0 {
+					return c < 0
+				}
+			}
+			return false
+		})
+	}
+
+	if opts.Offset >= len(items) {
+		return []T{}
+	}
+	items = items[opts.Offset:]
+	if opts.Limit > 0 && opts.Limit < len(items) {
+		items = items[:opts.Limit]
+	}
+	return items
+}
+
+// listPage is the body of a list response: one page of items and the window it covers. Limit is 0 when the
+// request did not set one, in which case every match from Offset on is included.
+type listPage[T any] struct {
+	Items  []T `json:"items"`
+	Total  int `json:"total"` // Matches across all pages.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// writeList sends one page of a list along with its total match count and the page links.
+func writeList[T any](w http.ResponseWriter, r *http.Request, items []T, total int, opts listOptions) {
+	writeListHeaders(w, r, total, opts)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(listPage[T]{Items: items, Total: total, Offset: opts.Offset, Limit: opts.Limit})
+}
+
+// writeListHeaders repeats the total match count in X-Total-Count and adds RFC 8288 Link headers for the
+// next and previous pages when the request is paginated, so clients can page without reading the body.
+func writeListHeaders(w http.ResponseWriter, r *http.Request, total int, opts listOptions) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if opts.Limit == 0 {
+		return
+	}
+
+	pageLink := func(offset int, rel string) string {
+		query := r.URL.Query()
+		query.Set("offset", strconv.Itoa(offset))
+		query.Set("limit", strconv.Itoa(opts.Limit))
+		return "<" + r.URL.Path + "?" + query.Encode() + `>; rel="` + rel + `"`
+	}
+	var links []string
+	if opts.Offset+opts.Limit < total {
+		links = append(links, pageLink(opts.Offset+opts.Limit, "next"))
+	}
+	if opts.Offset > 0 {
+		prev := opts.Offset - opts.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(prev, "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareInt(a, b int) int {
+	return compareFloat(float64(a), float64(b))
+}
+
+// studentComparators defines the sort keys accepted by GET /students.
+var studentComparators = map[string]func(a, b models.Student) int{
+	"id": func(a, b models.Student) int { return compareInt(a.ID, b.ID) },
+	"name": func(a, b models.Student) int {
+		return strings.Compare(strings.ToLower(a.FullName), strings.ToLower(b.FullName))
+	},
+	"cgpa":             func(a, b models.Student) int { return compareFloat(a.CGPA, b.CGPA) },
+	"currentSalary":    func(a, b models.Student) int { return compareFloat(a.CurrentSalary, b.CurrentSalary) },
+	"companiesApplied": func(a, b models.Student) int { return compareInt(a.NumCompaniesApplied, b.NumCompaniesApplied) },
+	"dreamOffer":       func(a, b models.Student) int { return compareFloat(a.DreamOfferAmount, b.DreamOfferAmount) },
+}
+
+// companyComparators defines the sort keys accepted by GET /companies.
+var companyComparators = map[string]func(a, b models.Company) int{
+	"id": func(a, b models.Company) int { return strings.Compare(a.ID, b.ID) },
+	"name": func(a, b models.Company) int {
+		return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	},
+	"offeredSalary": func(a, b models.Company) int { return compareFloat(a.OfferedSalary, b.OfferedSalary) },
+}
+
+// sortFields returns the set of sort keys defined by a comparator map.
+func sortFields[T any](comparators map[string]func(a, b T) int) map[string]bool {
+	fields := map[string]bool{}
+	for k := range comparators {
+		fields[k] = true
+	}
+	return fields
+}
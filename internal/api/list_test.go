@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestWriteListEnvelope(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantItems []int
+		wantLink  []string
+	}{
+		{"everything", "", []int{1, 2, 3, 4, 5}, nil},
+		{"first page", "limit=2", []int{1, 2}, []string{`offset=2`, `rel="next"`}},
+		{"middle page", "offset=2&limit=2", []int{3, 4}, []string{`rel="next"`, `offset=0`, `rel="prev"`}},
+		{"last page", "offset=4&limit=2", []int{5}, []string{`offset=2`, `rel="prev"`}},
+		{"past the end", "offset=9&limit=2", []int{}, []string{`rel="prev"`}},
+		{"sorted", "sort=-id&limit=2", []int{5, 4}, []string{`rel="next"`}},
+	}
+	comparators := map[string]func(a, b int) int{"id": compareInt}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/things?"+tt.query, nil)
+			query := &queryParser{values: r.URL.Query()}
+			opts := query.listOptions(sortFields(comparators))
+			if len(query.errors) > 0 {
+				t.Fatalf("query errors: %v", query.errors)
+			}
+			items := []int{1, 2, 3, 4, 5}
+			rec := httptest.NewRecorder()
+			writeList(rec, r, sortAndPage(items, opts, comparators), len(items), opts)
+
+			var page listPage[int]
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != 5 || page.Offset != opts.Offset || page.Limit != opts.Limit {
+				t.Errorf("window = total %d, offset %d, limit %d; want 5, %d, %d", page.Total, page.Offset, page.Limit, opts.Offset, opts.Limit)
+			}
+			if len(page.Items) != len(tt.wantItems) {
+				t.Fatalf("items = %v, want %v", page.Items, tt.wantItems)
+			}
+			for i := range page.Items {
+				if page.Items[i] != tt.wantItems[i] {
+					t.Fatalf("items = %v, want %v", page.Items, tt.wantItems)
+				}
+			}
+			if got := rec.Header().Get("X-Total-Count"); got != "5" {
+				t.Errorf("X-Total-Count = %q, want 5", got)
+			}
+			link, _ := url.QueryUnescape(rec.Header().Get("Link"))
+			for _, want := range tt.wantLink {
+				if !strings.Contains(link, want) {
+					t.Errorf("Link %q does not contain %q", link, want)
+				}
+			}
+			if tt.wantLink == nil && link != "" {
+				t.Errorf("unexpected Link %q", link)
+			}
+		})
+	}
+}
+
+func TestListOptionsErrors(t *testing.T) {
+	query := &queryParser{values: url.Values{"offset": {"-1"}, "limit": {"501"}, "sort": {"id,salary"}}}
+	query.listOptions(map[string]bool{"id": true})
+	fields := map[string]bool{}
+	for _, e := range query.errors {
+		fields[e.Field] = true
+	}
+	for _, want := range []string{"offset", "limit", "sort"} {
+		if !fields[want] {
+			t.Errorf("no error reported for %s: %v", want, query.errors)
+		}
+	}
+}
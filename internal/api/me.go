@@ -0,0 +1,261 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/auth"
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// StudentProfile is the self-service view of a student, including their computed offer tier.
+type StudentProfile struct {
+	models.Student
+	OfferCategory string `json:"offerCategory,omitempty"` // L1, L2 or L3; empty while unplaced.
+}
+
+// StudentTokenTTL is how long a student token from POST /students/{studentID}/token stays valid.
+var StudentTokenTTL = 7 * 24 * time.Hour
+
+// IssueStudentTokenHandler lets a coordinator issue a bearer token that a student uses for the /me endpoints.
+// The token expires after StudentTokenTTL and can be revoked earlier with RevokeStudentTokensHandler.
+func IssueStudentTokenHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	student, found := storage.FindStudentByID(studentID)
+	if !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+
+	principal := auth.Principal{Role: auth.RoleStudent, Name: student.FullName, StudentID: student.ID}
+	token, expiresAt, err := auth.IssueSession(principal, StudentTokenTTL)
+	if err != nil {
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not generate token")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"studentId": student.ID, "token": token, "expiresAt": expiresAt})
+}
+
+// RevokeStudentTokensHandler invalidates every token issued to a student, e.g. after one has leaked.
+func RevokeStudentTokensHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	if _, found := storage.FindStudentByID(studentID); !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"studentId": studentID, "revoked": auth.RevokeStudent(studentID)})
+}
+
+// currentStudent loads the student record belonging to the authenticated principal.
+// It writes an error response and returns false if the request is not from a known student.
+func currentStudent(w http.ResponseWriter, r *http.Request) (models.Student, bool) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok || principal.Role != auth.RoleStudent {
+		problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Student authentication required")
+		return models.Student{}, false
+	}
+	student, found := storage.FindStudentByID(principal.StudentID)
+	if !found {
+		problem.Respond(w, r, http.StatusNotFound, problem.CodeStudentNotFound, "Student record no longer exists")
+		return models.Student{}, false
+	}
+	return student, true
+}
+
+// GetMyProfileHandler returns the authenticated student's own record and offer tier.
+func GetMyProfileHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+
+	config := storage.ActivePolicy()
+
+	setETag(w, student.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(StudentProfile{Student: student, OfferCategory: eligibility.OfferCategory(student, config)})
+}
+
+// GetMyEligibilityHandler evaluates the authenticated student against every company and returns all results,
+// including the reasons for companies the student is not eligible for.
+func GetMyEligibilityHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+
+	results := []models.EligibilityResult{}
+	for _, company := range storage.AllCompanies() {
+		results = append(results, eligibility.PerformEligibilityCheck(r.Context(), student, company))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// UpdateMyDreamHandler lets the authenticated student declare their dream company and/or dream offer amount.
+// Fields omitted from the body are left unchanged. If-Match is honoured as in UpdateStudentHandler.
+func UpdateMyDreamHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		DreamCompany *string  `json:"dreamCompany"`
+		DreamOffer   *float64 `json:"dreamOffer"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.DreamOffer != nil && *req.DreamOffer < 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Dream offer cannot be negative",
+			problem.FieldError{Field: "dreamOffer", Message: "cannot be negative"})
+		return
+	}
+
+	updated, err := storage.UpdateStudentIfVersion(student.ID, expectedVersion, func(s *models.Student) {
+		student = *s // Capture the latest version for the audit "before" snapshot.
+		if req.DreamCompany != nil {
+			s.DreamCompanyName = *req.DreamCompany
+		}
+		if req.DreamOffer != nil {
+			s.DreamOfferAmount = *req.DreamOffer
+		}
+	})
+	if isConflict(err) {
+		versionConflict(w, r, "student", updated.Version)
+		return
+	} else if err != nil {
+		studentNotFound(w, r, student.ID)
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionStudentUpdate,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(student.ID),
+		Before:     audit.Snapshot(student),
+		After:      audit.Snapshot(updated),
+	})
+
+	setETag(w, updated.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// GetMyApplicationsHandler lists the applications the authenticated student has submitted.
+func GetMyApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(storage.ApplicationsForStudent(student.ID))
+}
+
+// maxApplyAttempts bounds how often ApplyHandler repeats the eligibility check when the student record changes
+// between the check and storing the application.
+const maxApplyAttempts = 5
+
+// ApplyHandler submits an application from the authenticated student to a company, registering them for one of
+// its drives (driveId, or the drive whose registration is open now). Companies with scheduled drives only accept
+// applications inside a registration window, and a drive on the same day as one the student already registered
+// for is refused. The eligibility check is re-run at submission time; ineligible applications are rejected with
+// the result attached.
+func ApplyHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		CompanyID string `json:"companyId"`
+		DriveID   int    `json:"driveId"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	company, found := storage.FindCompanyByID(req.CompanyID)
+	if !found {
+		companyNotFound(w, r, req.CompanyID)
+		return
+	}
+	drive, ok := selectDrive(w, r, company, req.DriveID)
+	if !ok {
+		return
+	}
+
+	driveID := 0
+	if drive != nil {
+		driveID = drive.ID
+	}
+
+	// The application is only stored if the student record is unchanged since the eligibility check. If another
+	// request changed it in between, e.g. a concurrent application, the check is repeated against the new record.
+	var application models.Application
+	var result models.EligibilityResult
+	for attempt := 1; ; attempt++ {
+		result = eligibility.PerformEligibilityCheck(r.Context(), student, company)
+		if !result.IsEligible {
+			recordAudit(r, audit.Entry{
+				Action:     audit.ActionApplicationDenied,
+				EntityType: audit.EntityStudent,
+				EntityID:   strconv.Itoa(student.ID),
+				After:      audit.Snapshot(result),
+				Reasons:    result.Reasons,
+			})
+			p := problem.New(http.StatusForbidden, problem.CodeNotEligible, "Student is not eligible to apply to "+company.Name)
+			p.Extensions = map[string]interface{}{"eligibility": result}
+			problem.Write(w, r, p)
+			return
+		}
+
+		var err error
+		application, err = storage.AddApplication(student.ID, student.Version, company.ID, driveID, result.Reasons, checkScheduleConflicts(drive))
+		if err == nil {
+			break
+		}
+		if !isConflict(err) || attempt == maxApplyAttempts {
+			respondApplicationError(w, r, company, err)
+			return
+		}
+		if student, ok = currentStudent(w, r); !ok {
+			return
+		}
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionApplicationCreate,
+		EntityType: audit.EntityApplication,
+		EntityID:   strconv.Itoa(application.ID),
+		After:      audit.Snapshot(application),
+		Reasons:    result.Reasons,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(application)
+}
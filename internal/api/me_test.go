@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/auth"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+func TestStudentTokensExpireAndCanBeRevoked(t *testing.T) {
+	student, err := storage.AddStudent(models.Student{FullName: "Token Student", RollNumber: "TOKEN-1", CGPA: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := fmt.Sprintf("/students/%d/token", student.ID)
+
+	var issued struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}
+	for i := 0; i < 2; i++ {
+		rec := serve(t, http.HandlerFunc(IssueStudentTokenHandler), http.MethodPost, "/students/{studentID}/token", target, nil)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("issuing: status %d: %s", rec.Code, rec.Body)
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &issued); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := time.Now().Add(StudentTokenTTL); issued.ExpiresAt.Before(want.Add(-time.Minute)) || issued.ExpiresAt.After(want.Add(time.Minute)) {
+		t.Errorf("expiresAt = %v, want about %v", issued.ExpiresAt, want)
+	}
+
+	profile := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+issued.Token)
+		rec := httptest.NewRecorder()
+		auth.Authenticate(http.HandlerFunc(GetMyProfileHandler)).ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := profile(); code != http.StatusOK {
+		t.Fatalf("GET /me with the issued token: status %d", code)
+	}
+
+	rec := serve(t, http.HandlerFunc(RevokeStudentTokensHandler), http.MethodDelete, "/students/{studentID}/token", target, nil)
+	var revoked struct {
+		Revoked int `json:"revoked"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &revoked); err != nil || rec.Code != http.StatusOK || revoked.Revoked != 2 {
+		t.Errorf("revoking: status %d, body %s; want both tokens revoked", rec.Code, rec.Body)
+	}
+	if code := profile(); code != http.StatusUnauthorized {
+		t.Errorf("GET /me with a revoked token: status %d, want 401", code)
+	}
+
+	rec = serve(t, http.HandlerFunc(RevokeStudentTokensHandler), http.MethodDelete, "/students/{studentID}/token", "/students/999999/token", nil)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("revoking for an unknown student: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/notify"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// writePreferences sends a student's notification preferences.
+func writePreferences(w http.ResponseWriter, studentID int) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(notify.PreferencesFor(studentID))
+}
+
+// updatePreferences replaces a student's notification preferences from the request body.
+func updatePreferences(w http.ResponseWriter, r *http.Request, studentID int) {
+	var req struct {
+		Enabled *bool    `json:"enabled"`
+		Muted   []string `json:"muted"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	var fieldErrors []problem.FieldError
+	for i, kind := range req.Muted {
+		if !slices.Contains(notify.Kinds, kind) {
+			fieldErrors = append(fieldErrors, problem.FieldError{Field: fmt.Sprintf("muted[%d]", i),
+				Message: fmt.Sprintf("unknown notification kind %q", kind)})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Notification preferences failed validation", fieldErrors...)
+		return
+	}
+
+	preferences := notify.Preferences{StudentID: studentID, Enabled: req.Enabled == nil || *req.Enabled, Muted: req.Muted}
+	previous, err := notify.SetPreferences(preferences)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to save notification preferences", "studentId", studentID, "error", err)
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not save notification preferences")
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionPreferencesUpdate,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(studentID),
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(notify.PreferencesFor(studentID)),
+	})
+	writePreferences(w, studentID)
+}
+
+// GetStudentNotificationsHandler returns a student's notification preferences.
+func GetStudentNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	if _, found := storage.FindStudentByID(studentID); !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+	writePreferences(w, studentID)
+}
+
+// UpdateStudentNotificationsHandler replaces a student's notification preferences.
+func UpdateStudentNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	if _, found := storage.FindStudentByID(studentID); !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+	updatePreferences(w, r, studentID)
+}
+
+// GetMyNotificationsHandler is GetStudentNotificationsHandler for the authenticated student.
+func GetMyNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+	writePreferences(w, student.ID)
+}
+
+// UpdateMyNotificationsHandler is UpdateStudentNotificationsHandler for the authenticated student.
+func UpdateMyNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+	updatePreferences(w, r, student.ID)
+}
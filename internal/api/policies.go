@@ -0,0 +1,195 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/policy"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/rules"
+	"go-placement-policy/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// updatePolicy is the shared path for every policy mutation: it honours If-Match, derives the new config
+// with transform, validates it, stores it under the next version, records an audit entry and responds
+// with the updated config. transform runs under the policy lock against the current active config.
+func updatePolicy(w http.ResponseWriter, r *http.Request, action string, transform func(models.PolicyConfig) (models.PolicyConfig, error)) {
+	if policy.FileManaged() {
+		problem.Respond(w, r, http.StatusConflict, problem.CodePolicyReadOnly,
+			"Policies are managed by a watched policy file; edit the file instead (see GET /policies/source)")
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+
+	previous, updated, err := storage.UpdatePolicy(expectedVersion, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		next, err := transform(current)
+		if err != nil {
+			return next, err
+		}
+		return next, policy.Validate(next)
+	})
+
+	var validationErr *policy.ValidationError
+	switch {
+	case isConflict(err):
+		versionConflict(w, r, "policy configuration", previous.Version)
+		return
+	case errors.Is(err, policy.ErrUnknownRule):
+		problem.Respond(w, r, http.StatusNotFound, problem.CodeRuleNotFound, err.Error())
+		return
+	case errors.Is(err, policy.ErrUnknownPolicy):
+		problem.Respond(w, r, http.StatusNotFound, problem.CodePolicyNotFound,
+			err.Error()+"; known policies are "+strings.Join(policy.SortedNames(), ", "))
+		return
+	case errors.As(err, &validationErr):
+		fieldErrors := make([]problem.FieldError, len(validationErr.Fields))
+		for i, f := range validationErr.Fields {
+			fieldErrors[i] = problem.FieldError{Field: f.Field, Message: f.Message}
+		}
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Policy configuration failed validation", fieldErrors...)
+		return
+	case err != nil:
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, err.Error())
+		return
+	}
+
+	slog.InfoContext(r.Context(), "policy configuration updated", "action", action, "version", updated.Version, "changedPolicies", policy.Changed(previous, updated))
+	recordAudit(r, audit.Entry{
+		Action:     action,
+		EntityType: audit.EntityPolicy,
+		EntityID:   "active",
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(updated),
+	})
+
+	setETag(w, updated.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// readBody reads the whole request body. On failure it writes a problem response and returns false.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidJSON, "Could not read request body: "+err.Error())
+		return nil, false
+	}
+	return body, true
+}
+
+// PatchPoliciesHandler applies an RFC 7396 JSON merge patch to the active policy configuration.
+// Only the members present in the patch change, so omitting a policy leaves it as it is.
+func PatchPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	updatePolicy(w, r, audit.ActionPolicyPatch, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		return policy.MergePatch(current, body)
+	})
+}
+
+// PutPolicyHandler replaces one named policy section, e.g. PUT /policies/offerCategory.
+func PutPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "policyName")
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	updatePolicy(w, r, audit.ActionPolicyUpdate, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		return policy.ReplaceSection(current, name, body)
+	})
+}
+
+// EnablePolicyHandler switches one named policy on without touching its thresholds.
+func EnablePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	setPolicyEnabled(w, r, true)
+}
+
+// DisablePolicyHandler switches one named policy off without touching its thresholds.
+func DisablePolicyHandler(w http.ResponseWriter, r *http.Request) {
+	setPolicyEnabled(w, r, false)
+}
+
+func setPolicyEnabled(w http.ResponseWriter, r *http.Request, enabled bool) {
+	name := chi.URLParam(r, "policyName")
+	action := audit.ActionPolicyDisable
+	if enabled {
+		action = audit.ActionPolicyEnable
+	}
+	updatePolicy(w, r, action, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		return policy.SetEnabled(current, name, enabled)
+	})
+}
+
+// PutCustomRuleHandler creates or replaces the custom rule named in the URL path.
+// The rule's condition is compiled and type-checked before it is saved.
+func PutCustomRuleHandler(w http.ResponseWriter, r *http.Request) {
+	var rule models.CustomRule
+	if !decodeJSON(w, r, &rule) {
+		return
+	}
+	rule.Name = chi.URLParam(r, "ruleName")
+	updatePolicy(w, r, audit.ActionPolicyUpdate, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		return policy.UpsertRule(current, rule), nil
+	})
+}
+
+// DeleteCustomRuleHandler removes the custom rule named in the URL path.
+func DeleteCustomRuleHandler(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "ruleName")
+	updatePolicy(w, r, audit.ActionPolicyUpdate, func(current models.PolicyConfig) (models.PolicyConfig, error) {
+		return policy.DeleteRule(current, name)
+	})
+}
+
+// ValidateRuleConditionHandler compiles a rule condition without saving it, so editors can check expressions as they are typed.
+func ValidateRuleConditionHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Condition string `json:"condition"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if _, err := rules.Compile(req.Condition); err != nil {
+		p := problem.New(http.StatusBadRequest, problem.CodeValidationFailed, "Invalid rule condition: "+err.Error())
+		p.Errors = []problem.FieldError{{Field: "condition", Message: err.Error()}}
+		var syntaxErr *rules.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			p.Extensions = map[string]interface{}{"position": syntaxErr.Pos}
+		}
+		problem.Write(w, r, p)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"valid": true, "condition": req.Condition})
+}
+
+// GetPolicySourceHandler reports where the active policy configuration comes from.
+// When a policy file is watched, its path, last applied version and last reload error are included.
+func GetPolicySourceHandler(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"source":        "api",
+		"activeVersion": storage.ActivePolicy().Version,
+	}
+	if status, ok := policy.ActiveFileStatus(); ok {
+		response["source"] = "file"
+		response["file"] = status
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+)
+
+// writeRecommendations ranks the companies for the student using the rankBy query parameter
+// (score, uplift, salary or driveDate) and writes the result.
+func writeRecommendations(w http.ResponseWriter, r *http.Request, student models.Student) {
+	rankBy := r.URL.Query().Get("rankBy")
+	if rankBy != "" && !eligibility.ValidRankBy(rankBy) {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidQueryParam, "Invalid query parameters",
+			problem.FieldError{Field: "rankBy", Message: "must be score, uplift, salary or driveDate"})
+		return
+	}
+
+	// ListDrives orders by deadline, so the first drive still accepting registrations is the next one.
+	now := time.Now()
+	nextDrives := map[string]models.Drive{}
+	for _, d := range storage.ListDrives("") {
+		if _, seen := nextDrives[d.CompanyID]; !seen && now.Before(d.RegistrationClosesAt) {
+			nextDrives[d.CompanyID] = d
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(eligibility.Recommend(r.Context(), student, storage.AllCompanies(), nextDrives, rankBy))
+}
+
+// GetStudentRecommendationsHandler ranks the companies a student is eligible for and lists the others
+// separately with the reasons they are blocked.
+func GetStudentRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	studentID, ok := studentIDParam(w, r)
+	if !ok {
+		return
+	}
+	student, found := storage.FindStudentByID(studentID)
+	if !found {
+		studentNotFound(w, r, studentID)
+		return
+	}
+	writeRecommendations(w, r, student)
+}
+
+// GetMyRecommendationsHandler is GetStudentRecommendationsHandler for the authenticated student.
+func GetMyRecommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	student, ok := currentStudent(w, r)
+	if !ok {
+		return
+	}
+	writeRecommendations(w, r, student)
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/importer"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// shortlistFields are the canonical column names accepted by UploadShortlistHandler. Candidates are identified
+// by studentId or rollNumber.
+var shortlistFields = []string{"studentId", "rollNumber"}
+
+// roundResultFields are the canonical column names accepted by UploadRoundResultsHandler.
+var roundResultFields = []string{"studentId", "rollNumber", "outcome"}
+
+// RoundRow is the outcome of one candidate in a shortlist or results upload.
+type RoundRow struct {
+	Line          int                   `json:"line,omitempty"` // Zero for candidates left off a shortlist.
+	Key           string                `json:"key,omitempty"`  // The student ID or roll number given in the row.
+	StudentID     int                   `json:"studentId,omitempty"`
+	ApplicationID int                   `json:"applicationId,omitempty"`
+	Outcome       string                `json:"outcome,omitempty"`
+	Status        string                `json:"status,omitempty"` // The application status after the upload.
+	CurrentRound  string                `json:"currentRound,omitempty"`
+	Errors        []importer.FieldError `json:"errors,omitempty"`
+}
+
+// RoundReport summarises a shortlist or results upload. Nothing is committed unless every row is valid.
+type RoundReport struct {
+	DryRun    bool       `json:"dryRun"`
+	Committed bool       `json:"committed"`
+	DriveID   int        `json:"driveId"`
+	Round     string     `json:"round"`
+	TotalRows int        `json:"totalRows"`
+	Cleared   int        `json:"cleared"`
+	Rejected  int        `json:"rejected"`
+	Offered   int        `json:"offered"`
+	Failed    int        `json:"failed"`
+	Rows      []RoundRow `json:"rows"`
+	// NotShortlisted lists the candidates waiting on the previous stage whom a shortlist left out; they are rejected.
+	NotShortlisted []RoundRow `json:"notShortlisted,omitempty"`
+}
+
+// add appends a row result and updates the counters.
+func (r *RoundReport) add(row RoundRow) {
+	if row.Line != 0 {
+		r.TotalRows++
+		r.Rows = append(r.Rows, row)
+	} else {
+		r.NotShortlisted = append(r.NotShortlisted, row)
+	}
+	switch {
+	case len(row.Errors) > 0:
+		r.Failed++
+	case row.Status == models.ApplicationStatusOffered:
+		r.Offered++
+	case row.Outcome == models.RoundOutcomeRejected:
+		r.Rejected++
+	default:
+		r.Cleared++
+	}
+}
+
+// driveRoundParams resolves the {driveID} and {roundName} URL parameters to the drive and the round's index.
+// On failure it writes a problem and returns false.
+func driveRoundParams(w http.ResponseWriter, r *http.Request) (models.Drive, int, bool) {
+	driveID, ok := driveIDParam(w, r)
+	if !ok {
+		return models.Drive{}, 0, false
+	}
+	drive, found := storage.FindDriveByID(driveID)
+	if !found {
+		driveNotFound(w, r, driveID)
+		return models.Drive{}, 0, false
+	}
+	roundName := chi.URLParam(r, "roundName")
+	stage := drive.RoundIndex(roundName)
+	if stage < 0 {
+		problem.Respond(w, r, http.StatusNotFound, problem.CodeRoundNotFound,
+			fmt.Sprintf("Drive %d has no round named %q", driveID, roundName))
+		return models.Drive{}, 0, false
+	}
+	return drive, stage, true
+}
+
+// stageName names a drive stage as used in error messages: "screening" for -1, otherwise the round's name.
+func stageName(d models.Drive, stage int) string {
+	if stage < 0 {
+		return models.ScreeningRound
+	}
+	return d.Rounds[stage].Name
+}
+
+// roundCandidate is an upload row resolved to a student before the applications lock is taken.
+type roundCandidate struct {
+	record    importer.Record
+	parser    *importer.Parser
+	key       string
+	studentID int
+}
+
+// resolveRoundCandidates identifies the student of each row by studentId, or else by rollNumber.
+func resolveRoundCandidates(records []importer.Record) []roundCandidate {
+	candidates := make([]roundCandidate, 0, len(records))
+	for _, record := range records {
+		c := roundCandidate{record: record, parser: &importer.Parser{Record: record}}
+		switch {
+		case c.parser.String("studentId") != "":
+			c.key = c.parser.String("studentId")
+			c.studentID = c.parser.Int("studentId")
+			if !c.parser.Failed("studentId") {
+				if _, found := storage.FindStudentByID(c.studentID); !found {
+					c.parser.Fail("studentId", "does not match any student")
+				}
+			}
+		case c.parser.String("rollNumber") != "":
+			c.key = c.parser.String("rollNumber")
+			if student, found := storage.FindStudentByRollNumber(c.key); found {
+				c.studentID = student.ID
+			} else {
+				c.parser.Fail("rollNumber", "does not match any student")
+			}
+		default:
+			c.parser.Fail("studentId", "studentId or rollNumber is required")
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// applyRoundUpload records the outcomes in an upload for one stage of a drive. Each row must name a student whose
+// application to the drive is waiting on that stage. outcome returns the row's outcome; for a shortlist every listed
+// candidate clears the stage and, with rejectUnlisted, everyone else still waiting on it is rejected.
+// Offers are recorded for candidates who clear the final round, which marks them placed.
+func applyRoundUpload(w http.ResponseWriter, r *http.Request, drive models.Drive, stage int, round string, fields []string,
+	outcome func(p *importer.Parser) string, rejectUnlisted bool) {
+	records, dryRun, ok := readImportRecords(w, r, fields)
+	if !ok {
+		return
+	}
+	company, _ := storage.FindCompanyByID(drive.CompanyID)
+	candidates := resolveRoundCandidates(records)
+	now := time.Now().UTC()
+
+	report := RoundReport{DryRun: dryRun, DriveID: drive.ID, Round: round, Rows: []RoundRow{}}
+	stored, previous := storage.ProgressDriveApplications(drive.ID, func(applications []models.Application) ([]models.Application, bool) {
+		byStudent := map[int]int{}
+		for i, a := range applications {
+			byStudent[a.StudentID] = i
+		}
+		listed := map[int]int{} // Student ID -> line of the row naming them.
+		var batch []models.Application
+		for _, c := range candidates {
+			row := RoundRow{Line: c.record.Line, Key: c.key, StudentID: c.studentID}
+			row.Outcome = outcome(c.parser)
+			i, applied := byStudent[c.studentID]
+			if c.studentID != 0 {
+				pending, waiting := -1, false
+				if applied {
+					pending, waiting = applications[i].PendingStage(drive)
+				}
+				line, duplicate := listed[c.studentID]
+				switch {
+				case duplicate:
+					c.parser.Fail("studentId", fmt.Sprintf("duplicates the row on line %d", line))
+				case !applied:
+					c.parser.Fail("studentId", fmt.Sprintf("has not registered for drive %d", drive.ID))
+				case !waiting:
+					c.parser.Fail("studentId", "application is already "+applications[i].Status)
+				case pending != stage:
+					c.parser.Fail("studentId", fmt.Sprintf("is waiting on %s, not %s", stageName(drive, pending), stageName(drive, stage)))
+				}
+				if !duplicate {
+					listed[c.studentID] = c.record.Line
+				}
+			}
+			if len(c.parser.Errors) == 0 {
+				application := applications[i]
+				if err := application.Advance(drive, row.Outcome, now); err != nil {
+					c.parser.Fail("outcome", err.Error())
+				} else {
+					row.ApplicationID, row.Status, row.CurrentRound = application.ID, application.Status, application.CurrentRound
+					batch = append(batch, application)
+				}
+			}
+			row.Errors = c.parser.Errors
+			report.add(row)
+		}
+		if rejectUnlisted {
+			for _, application := range applications {
+				if pending, ok := application.PendingStage(drive); !ok || pending != stage {
+					continue
+				}
+				if _, isListed := listed[application.StudentID]; isListed {
+					continue
+				}
+				application.Advance(drive, models.RoundOutcomeRejected, now)
+				report.add(RoundRow{StudentID: application.StudentID, ApplicationID: application.ID,
+					Outcome: models.RoundOutcomeRejected, Status: application.Status})
+				batch = append(batch, application)
+			}
+		}
+		return batch, !dryRun && report.Failed == 0
+	})
+
+	report.Committed = !dryRun && report.Failed == 0
+	if report.Committed {
+		for i, application := range stored {
+			recordAudit(r, audit.Entry{
+				Action:     audit.ActionApplicationRound,
+				EntityType: audit.EntityApplication,
+				EntityID:   strconv.Itoa(application.ID),
+				Before:     audit.Snapshot(previous[i]),
+				After:      audit.Snapshot(application),
+				Reasons:    []string{fmt.Sprintf("Drive %d %s: %s", drive.ID, round, application.RoundResults[len(application.RoundResults)-1].Outcome)},
+			})
+			if application.Status == models.ApplicationStatusOffered {
+				recordRoundOffer(r, application, company)
+			}
+		}
+	}
+
+	if report.Failed > 0 && !report.DryRun {
+		p := problem.New(http.StatusBadRequest, problem.CodeImportFailed,
+			fmt.Sprintf("%d of %d rows are invalid; no results were recorded", report.Failed, report.TotalRows))
+		p.Extensions = map[string]interface{}{"report": report}
+		problem.Write(w, r, p)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// recordRoundOffer places a student who cleared a drive's final round at the company's advertised salary.
+func recordRoundOffer(r *http.Request, application models.Application, company models.Company) {
+	before, after, err := storage.RecordOffer(application.StudentID, company.ID, company.OfferedSalary, 0)
+	if err != nil {
+		return // The student was deleted after the upload was planned; the application still records the offer.
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionOfferRecord,
+		EntityType: audit.EntityStudent,
+		EntityID:   strconv.Itoa(application.StudentID),
+		Before:     audit.Snapshot(before),
+		After:      audit.Snapshot(after),
+		Reasons:    []string{fmt.Sprintf("Offer from %s (%s) at %.2f after clearing drive %d", company.Name, company.ID, company.OfferedSalary, application.DriveID)},
+	})
+}
+
+// UploadShortlistHandler records the shortlist for a round of a drive, as CSV or a JSON array of studentId or
+// rollNumber rows. Listed candidates clear the previous stage (screening, for the first round) and move into the
+// round; everyone else still waiting on that stage is rejected. Like imports it supports ?dryRun=true and ?map=,
+// and nothing is recorded unless every row is valid.
+func UploadShortlistHandler(w http.ResponseWriter, r *http.Request) {
+	drive, stage, ok := driveRoundParams(w, r)
+	if !ok {
+		return
+	}
+	cleared := func(*importer.Parser) string { return models.RoundOutcomeCleared }
+	applyRoundUpload(w, r, drive, stage-1, drive.Rounds[stage].Name, shortlistFields, cleared, true)
+}
+
+// UploadRoundResultsHandler records per-candidate outcomes (cleared or rejected) for a round of a drive.
+// Candidates who clear a round move to the next one; clearing the final round makes the application offered
+// and records the offer on the student. Candidates not in the upload keep waiting on the round.
+func UploadRoundResultsHandler(w http.ResponseWriter, r *http.Request) {
+	drive, stage, ok := driveRoundParams(w, r)
+	if !ok {
+		return
+	}
+	outcome := func(p *importer.Parser) string {
+		v := p.String("outcome")
+		if v == "" {
+			p.Fail("outcome", "is required")
+		}
+		return v
+	}
+	applyRoundUpload(w, r, drive, stage, drive.Rounds[stage].Name, roundResultFields, outcome, false)
+}
+
+// GetDriveApplicationsHandler lists the applications registered for a drive, optionally filtered by status
+// or by the round candidates are currently in.
+func GetDriveApplicationsHandler(w http.ResponseWriter, r *http.Request) {
+	driveID, ok := driveIDParam(w, r)
+	if !ok {
+		return
+	}
+	drive, found := storage.FindDriveByID(driveID)
+	if !found {
+		driveNotFound(w, r, driveID)
+		return
+	}
+	query := &queryParser{values: r.URL.Query()}
+	status := r.URL.Query().Get("status")
+	if status != "" && !models.ValidApplicationStatus(status) {
+		query.errors = append(query.errors, problem.FieldError{Field: "status", Message: "must be applied, in_progress, rejected or offered"})
+	}
+	round := r.URL.Query().Get("round")
+	if round != "" && drive.RoundIndex(round) < 0 {
+		query.errors = append(query.errors, problem.FieldError{Field: "round", Message: "must name a round of the drive"})
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+
+	result := []models.Application{}
+	for _, a := range storage.ApplicationsForDrive(drive.ID) {
+		if status != "" && a.Status != status {
+			continue
+		}
+		if round != "" && (a.Status != models.ApplicationStatusInProgress || drive.RoundIndex(a.CurrentRound) != drive.RoundIndex(round)) {
+			continue
+		}
+		result = append(result, a)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
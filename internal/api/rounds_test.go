@@ -0,0 +1,190 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// roundsFixture stores a company, a drive with an Aptitude and an Interview round, and one registered student per
+// roll number. Roll numbers and the company ID are prefixed with prefix so tests do not share records.
+func roundsFixture(t *testing.T, prefix string, rolls ...string) (models.Drive, map[string]models.Student) {
+	t.Helper()
+	company := models.Company{ID: prefix + "-CO", Name: prefix + " Ltd", OfferedSalary: 1200000}
+	storage.ImportCompanies(func(func(string) (models.Company, bool)) ([]models.Company, bool) {
+		return []models.Company{company}, true
+	})
+	drive := storage.AddDrive(models.Drive{CompanyID: company.ID, Rounds: []models.Round{{Name: "Aptitude"}, {Name: "Interview"}}})
+	students := map[string]models.Student{}
+	for _, roll := range rolls {
+		student, err := storage.AddStudent(models.Student{FullName: "Student " + roll, RollNumber: prefix + "-" + roll, CGPA: 8})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := storage.AddApplication(student.ID, student.Version, company.ID, drive.ID, nil, nil); err != nil {
+			t.Fatal(err)
+		}
+		students[roll] = student
+	}
+	return drive, students
+}
+
+// uploadRound posts a CSV upload to the shortlist or results endpoint of a drive round and decodes the report,
+// which a failed upload carries in its problem's report extension.
+func uploadRound(t *testing.T, drive models.Drive, round, kind, query, csv string) (int, RoundReport) {
+	t.Helper()
+	handler, pattern := UploadShortlistHandler, "/drives/{driveID}/rounds/{roundName}/shortlist"
+	if kind == "results" {
+		handler, pattern = UploadRoundResultsHandler, "/drives/{driveID}/rounds/{roundName}/results"
+	}
+	target := fmt.Sprintf("/drives/%d/rounds/%s/%s%s", drive.ID, round, kind, query)
+	rec := serve(t, http.HandlerFunc(handler), http.MethodPost, pattern, target, strings.NewReader(csv))
+
+	var body struct {
+		RoundReport
+		Report *RoundReport `json:"report"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding %s: %v", rec.Body, err)
+	}
+	if body.Report != nil {
+		return rec.Code, *body.Report
+	}
+	return rec.Code, body.RoundReport
+}
+
+// applicationStates returns the status and current round of each fixture student's application to the drive.
+func applicationStates(drive models.Drive, students map[string]models.Student) map[string]string {
+	byStudent := map[int]string{}
+	for _, a := range storage.ApplicationsForDrive(drive.ID) {
+		byStudent[a.StudentID] = strings.TrimSuffix(a.Status+" "+a.CurrentRound, " ")
+	}
+	states := map[string]string{}
+	for roll, s := range students {
+		states[roll] = byStudent[s.ID]
+	}
+	return states
+}
+
+func TestShortlistRejectsCandidatesLeftOff(t *testing.T) {
+	drive, students := roundsFixture(t, "SHORT", "1", "2", "3")
+
+	code, report := uploadRound(t, drive, "aptitude", "shortlist", "", "rollNumber\nSHORT-1\nSHORT-3\n")
+	if code != http.StatusOK || !report.Committed {
+		t.Fatalf("status %d, report %+v", code, report)
+	}
+	if report.Cleared != 2 || report.Rejected != 1 || len(report.NotShortlisted) != 1 ||
+		report.NotShortlisted[0].StudentID != students["2"].ID {
+		t.Errorf("report %+v, want 1 and 3 cleared and 2 rejected as not shortlisted", report)
+	}
+	want := map[string]string{"1": "in_progress Aptitude", "2": "rejected", "3": "in_progress Aptitude"}
+	if got := applicationStates(drive, students); !reflect.DeepEqual(got, want) {
+		t.Errorf("applications = %v, want %v", got, want)
+	}
+}
+
+func TestFinalRoundRecordsOffer(t *testing.T) {
+	drive, students := roundsFixture(t, "FINAL", "1", "2")
+	if code, _ := uploadRound(t, drive, "Aptitude", "shortlist", "", "rollNumber\nFINAL-1\nFINAL-2\n"); code != http.StatusOK {
+		t.Fatalf("shortlist: status %d", code)
+	}
+	results := fmt.Sprintf("studentId,outcome\n%d,cleared\n%d,rejected\n", students["1"].ID, students["2"].ID)
+	if code, _ := uploadRound(t, drive, "Aptitude", "results", "", results); code != http.StatusOK {
+		t.Fatalf("aptitude results: status %d", code)
+	}
+	code, report := uploadRound(t, drive, "Interview", "results", "", fmt.Sprintf("studentId,outcome\n%d,cleared\n", students["1"].ID))
+	if code != http.StatusOK || report.Offered != 1 {
+		t.Fatalf("interview results: status %d, report %+v", code, report)
+	}
+
+	placed, _ := storage.FindStudentByID(students["1"].ID)
+	if !placed.IsPlaced || placed.CurrentSalary != 1200000 || placed.PlacedCompanyID != drive.CompanyID {
+		t.Errorf("student after the final round: placed %v at %v with %q; want the offer recorded",
+			placed.IsPlaced, placed.CurrentSalary, placed.PlacedCompanyID)
+	}
+	if other, _ := storage.FindStudentByID(students["2"].ID); other.IsPlaced {
+		t.Error("a rejected candidate was placed")
+	}
+	want := map[string]string{"1": "offered", "2": "rejected"}
+	if got := applicationStates(drive, students); !reflect.DeepEqual(got, want) {
+		t.Errorf("applications = %v, want %v", got, want)
+	}
+}
+
+func TestRoundUploadDryRunChangesNothing(t *testing.T) {
+	drive, students := roundsFixture(t, "DRY", "1", "2")
+	revision := storage.Revision()
+
+	code, report := uploadRound(t, drive, "Aptitude", "shortlist", "?dryRun=true", "rollNumber\nDRY-1\n")
+	if code != http.StatusOK || !report.DryRun || report.Committed || report.Cleared != 1 || report.Rejected != 1 {
+		t.Fatalf("status %d, report %+v; want the planned outcome without committing", code, report)
+	}
+	want := map[string]string{"1": "applied", "2": "applied"}
+	if got := applicationStates(drive, students); !reflect.DeepEqual(got, want) {
+		t.Errorf("applications = %v, want %v", got, want)
+	}
+	if storage.Revision() != revision {
+		t.Error("a dry run changed the storage revision")
+	}
+}
+
+func TestRoundUploadRejectsInvalidRows(t *testing.T) {
+	drive, students := roundsFixture(t, "BAD", "1", "2")
+	if code, _ := uploadRound(t, drive, "Aptitude", "shortlist", "", "rollNumber\nBAD-1\nBAD-2\n"); code != http.StatusOK {
+		t.Fatalf("shortlist: status %d", code)
+	}
+
+	tests := []struct {
+		name, round, kind, csv string
+		wantErrors             []string // Field error message of each row, "" for a valid row.
+	}{
+		{"re-uploading a completed shortlist", "Aptitude", "shortlist", "rollNumber\nBAD-1\n",
+			[]string{"is waiting on Aptitude, not screening"}},
+		{"results for a later round", "Interview", "results", fmt.Sprintf("studentId,outcome\n%d,cleared\n", students["1"].ID),
+			[]string{"is waiting on Aptitude, not Interview"}},
+		{"one bad row fails the upload", "Aptitude", "results", fmt.Sprintf("studentId,outcome\n%d,cleared\n%d,maybe\nNOPE,cleared\n%d,rejected\n",
+			students["1"].ID, students["2"].ID, students["1"].ID),
+			[]string{"", "outcome must be cleared or rejected", `"NOPE" is not a whole number`, "duplicates the row on line 2"}},
+		{"unknown roll number", "Aptitude", "results", "rollNumber,outcome\nBAD-404,cleared\n",
+			[]string{"does not match any student"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, report := uploadRound(t, drive, tt.round, tt.kind, "", tt.csv)
+			if code != http.StatusBadRequest || report.Committed {
+				t.Fatalf("status %d, committed %v; want 400 and nothing recorded", code, report.Committed)
+			}
+			var got []string
+			for _, row := range report.Rows {
+				message := ""
+				if len(row.Errors) > 0 {
+					message = row.Errors[0].Message
+				}
+				got = append(got, message)
+			}
+			if !reflect.DeepEqual(got, tt.wantErrors) {
+				t.Errorf("row errors = %q, want %q", got, tt.wantErrors)
+			}
+		})
+	}
+	want := map[string]string{"1": "in_progress Aptitude", "2": "in_progress Aptitude"}
+	if got := applicationStates(drive, students); !reflect.DeepEqual(got, want) {
+		t.Errorf("applications after failed uploads = %v, want %v", got, want)
+	}
+}
+
+func TestRoundUploadUnknownRound(t *testing.T) {
+	drive, _ := roundsFixture(t, "ROUND")
+	target := fmt.Sprintf("/drives/%d/rounds/HR/results", drive.ID)
+	rec := serve(t, http.HandlerFunc(UploadRoundResultsHandler), http.MethodPost,
+		"/drives/{driveID}/rounds/{roundName}/results", target, strings.NewReader("studentId,outcome\n"))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-placement-policy/internal/auth"
+	"go-placement-policy/internal/problem"
+)
+
+// SessionTTL is how long a coordinator session token from POST /auth/login stays valid.
+var SessionTTL = 8 * time.Hour
+
+// failedLoginDelay slows down password guessing.
+const failedLoginDelay = 500 * time.Millisecond
+
+// LoginHandler exchanges a coordinator account's name and password for a session token that expires after SessionTTL.
+func LoginHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if req.Name == "" || !auth.VerifyCoordinator(req.Name, req.Password) {
+		slog.WarnContext(r.Context(), "coordinator login failed", "name", req.Name, "remoteAddr", r.RemoteAddr)
+		time.Sleep(failedLoginDelay)
+		problem.Respond(w, r, http.StatusUnauthorized, problem.CodeInvalidCredentials, "Unknown coordinator name or wrong password")
+		return
+	}
+
+	principal := auth.Principal{Role: auth.RoleCoordinator, Name: req.Name}
+	token, expiresAt, err := auth.IssueSession(principal, SessionTTL)
+	if err != nil {
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not generate token")
+		return
+	}
+	slog.InfoContext(r.Context(), "coordinator logged in", "name", req.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{"token": token, "expiresAt": expiresAt, "principal": principal})
+}
+
+// LogoutHandler revokes the token the request was authenticated with. The configured COORDINATOR_TOKEN cannot be
+// revoked this way.
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := auth.FromContext(r.Context()); !ok {
+		problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication required")
+		return
+	}
+	token, _ := auth.BearerToken(r)
+	auth.Revoke(token)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetSessionHandler returns the principal the request was authenticated as, so clients can check a stored token.
+func GetSessionHandler(w http.ResponseWriter, r *http.Request) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication required")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(principal)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"go-placement-policy/internal/stats"
+)
+
+// GetStatsHandler returns placement analytics. The report is cached until students, companies or
+// policies change; its revision doubles as the ETag, so clients can poll with If-None-Match.
+func GetStatsHandler(w http.ResponseWriter, r *http.Request) {
+	report := stats.Current()
+	if notModified(w, r, report.Revision) {
+		return
+	}
+
+	setETag(w, report.Revision)
+	w.Header().Set("Cache-Control", "private, no-cache") // Cacheable, but revalidate since any mutation changes it.
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetPolicyImpactHandler reports, per policy and per reason code, how many student–company pairs and
+// distinct students each policy blocks, allows or had overridden under the active configuration.
+// policy and code narrow the report; details=true adds the affected student IDs for drill-down.
+func GetPolicyImpactHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	details := query.bool("details")
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+	policyFilter := r.URL.Query().Get("policy")
+	codeFilter := r.URL.Query().Get("code")
+
+	cached := stats.CurrentImpact()
+	if notModified(w, r, cached.Revision) {
+		return
+	}
+
+	// Copy the shared report, applying the filters and dropping student IDs unless asked for.
+	withIDs := details != nil && *details
+	trim := func(c stats.ImpactCount) stats.ImpactCount {
+		if !withIDs {
+			c.StudentIDs = nil
+		}
+		return c
+	}
+	report := *cached
+	report.Policies = []stats.PolicyImpact{}
+	for _, p := range cached.Policies {
+		if policyFilter != "" && p.Policy != policyFilter {
+			continue
+		}
+		impact := stats.PolicyImpact{Policy: p.Policy, Blocked: trim(p.Blocked), Allowed: trim(p.Allowed), Overridden: trim(p.Overridden), Reasons: []stats.ReasonImpact{}}
+		for _, reason := range p.Reasons {
+			if codeFilter != "" && reason.Code != codeFilter {
+				continue
+			}
+			impact.Reasons = append(impact.Reasons, stats.ReasonImpact{Code: reason.Code, Blocked: trim(reason.Blocked), Allowed: trim(reason.Allowed), Overridden: trim(reason.Overridden)})
+		}
+		if codeFilter != "" && len(impact.Reasons) == 0 {
+			continue
+		}
+		report.Policies = append(report.Policies, impact)
+	}
+
+	setETag(w, report.Revision)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/stats"
+	"go-placement-policy/internal/storage"
+)
+
+func getImpact(t *testing.T, target, ifNoneMatch string) (*httptest.ResponseRecorder, stats.ImpactReport) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	GetPolicyImpactHandler(rec, req)
+	var report stats.ImpactReport
+	if rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rec, report
+}
+
+func TestPolicyImpactFilters(t *testing.T) {
+	var config models.PolicyConfig
+	config.MaximumCompanies.Enabled = true
+	config.DreamOffer.Enabled = true
+	previous, _, err := storage.ConfigurePolicy(config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.ConfigurePolicy(previous, 0) })
+	if _, err := storage.AddStudent(models.Student{FullName: "Impact Placed", RollNumber: "IMPACT-API-1", IsPlaced: true, DreamOfferAmount: 1}); err != nil {
+		t.Fatal(err)
+	}
+	storage.ImportCompanies(func(func(string) (models.Company, bool)) ([]models.Company, bool) {
+		return []models.Company{{ID: "IMPACT-CO", Name: "Impact Ltd", OfferedSalary: 500000}}, true
+	})
+
+	tests := []struct {
+		name, query  string
+		wantPolicies []string // Sorted.
+		wantIDs      bool
+	}{
+		{"unfiltered", "", []string{models.PolicyDreamOffer, models.PolicyMaximumCompanies}, false},
+		{"by policy", "?policy=dreamOffer", []string{models.PolicyDreamOffer}, false},
+		{"by code", "?code=no_additional_applications", []string{models.PolicyMaximumCompanies}, false},
+		{"unknown policy", "?policy=nope", []string{}, false},
+		{"with details", "?policy=maximumCompanies&details=true", []string{models.PolicyMaximumCompanies}, true},
+	}
+	for _, tt := range tests {
+		rec, report := getImpact(t, "/policies/impact"+tt.query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", tt.name, rec.Code, rec.Body)
+		}
+		policies := []string{}
+		for _, p := range report.Policies {
+			policies = append(policies, p.Policy)
+			if hasIDs := len(p.Blocked.StudentIDs) > 0; hasIDs != tt.wantIDs {
+				t.Errorf("%s: %s includes student IDs: %v, want %v", tt.name, p.Policy, hasIDs, tt.wantIDs)
+			}
+			if code := "no_additional_applications"; strings.Contains(tt.query, code) && (len(p.Reasons) != 1 || p.Reasons[0].Code != code) {
+				t.Errorf("%s: reasons %+v, want only %s", tt.name, p.Reasons, code)
+			}
+		}
+		sort.Strings(policies)
+		if !reflect.DeepEqual(policies, tt.wantPolicies) {
+			t.Errorf("%s: policies %v, want %v", tt.name, policies, tt.wantPolicies)
+		}
+	}
+
+	if rec, _ := getImpact(t, "/policies/impact?details=maybe", ""); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid details: status %d, want 400", rec.Code)
+	}
+}
+
+func TestPolicyImpactETagFollowsTheRevision(t *testing.T) {
+	rec, report := getImpact(t, "/policies/impact", "")
+	etag := rec.Header().Get("ETag")
+	if etag != etagFor(report.Revision) || report.Revision != storage.Revision() {
+		t.Fatalf("ETag %q for revision %d, storage revision %d", etag, report.Revision, storage.Revision())
+	}
+	for _, target := range []string{"/policies/impact", "/policies/impact?policy=dreamOffer"} {
+		if rec, _ := getImpact(t, target, etag); rec.Code != http.StatusNotModified {
+			t.Errorf("%s with the current ETag: status %d, want 304", target, rec.Code)
+		}
+	}
+
+	if _, err := storage.AddStudent(models.Student{FullName: "Impact ETag", RollNumber: "IMPACT-API-2"}); err != nil {
+		t.Fatal(err)
+	}
+	rec, report = getImpact(t, "/policies/impact", etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag || rec.Header().Get("ETag") != etagFor(report.Revision) {
+		t.Errorf("after a change: status %d, ETag %q (was %q); want 200 with a new ETag", rec.Code, rec.Header().Get("ETag"), etag)
+	}
+}
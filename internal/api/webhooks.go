@@ -0,0 +1,248 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/problem"
+	"go-placement-policy/internal/webhooks"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// webhookRequest is the body of POST /webhooks and PUT /webhooks/{webhookID}.
+// Active defaults to true; an empty secret is generated on create and kept on update.
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// webhookIDParam parses the {webhookID} URL parameter. On failure it writes an invalid_path_parameter problem and returns false.
+func webhookIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidPathParam, "Invalid webhook ID format in URL path",
+			problem.FieldError{Field: "webhookID", Message: "must be an integer"})
+		return 0, false
+	}
+	return id, true
+}
+
+// webhookNotFound writes a webhook_not_found problem for the given ID.
+func webhookNotFound(w http.ResponseWriter, r *http.Request, id int) {
+	problem.Respond(w, r, http.StatusNotFound, problem.CodeWebhookNotFound, "Webhook not found for ID: "+strconv.Itoa(id))
+}
+
+// validateWebhook checks a webhook request and converts it to a webhook.
+func validateWebhook(req webhookRequest) (webhooks.Webhook, []problem.FieldError) {
+	var errs []problem.FieldError
+	req.URL = strings.TrimSpace(req.URL)
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, problem.FieldError{Field: "url", Message: "must be an absolute http or https URL"})
+	}
+	if len(req.Events) == 0 {
+		errs = append(errs, problem.FieldError{Field: "events", Message: `must list at least one event type, topic or "*"`})
+	}
+	for i, name := range req.Events {
+		if name != webhooks.AllEvents && !events.Known(name) {
+			errs = append(errs, problem.FieldError{Field: fmt.Sprintf("events[%d]", i), Message: fmt.Sprintf("unknown event type or topic %q", name)})
+		}
+	}
+	hook := webhooks.Webhook{URL: req.URL, Events: req.Events, Secret: req.Secret, Active: req.Active == nil || *req.Active}
+	return hook, errs
+}
+
+// GetWebhooksHandler lists webhook subscriptions. Secrets are never included.
+func GetWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.List())
+}
+
+// GetWebhookHandler returns a single webhook subscription.
+func GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+	hook, found := webhooks.Find(id)
+	if !found {
+		webhookNotFound(w, r, id)
+		return
+	}
+	if notModified(w, r, hook.Version) {
+		return
+	}
+	setETag(w, hook.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// CreateWebhookHandler subscribes a URL to events. The response is the only time the signing secret is returned.
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	hook, fieldErrors := validateWebhook(req)
+	if len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Webhook data failed validation", fieldErrors...)
+		return
+	}
+	if hook.Secret == "" {
+		secret, err := webhooks.GenerateSecret()
+		if err != nil {
+			problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not generate webhook secret")
+			return
+		}
+		hook.Secret = secret
+	}
+
+	hook, err := webhooks.Add(hook)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to save webhook", "error", err)
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not save webhook")
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionWebhookCreate,
+		EntityType: audit.EntityWebhook,
+		EntityID:   strconv.Itoa(hook.ID),
+		After:      audit.Snapshot(hook),
+	})
+
+	setETag(w, hook.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		webhooks.Webhook
+		Secret string `json:"secret"`
+	}{hook, hook.Secret})
+}
+
+// UpdateWebhookHandler replaces a webhook subscription, honouring If-Match. Omitting the secret keeps the current one.
+func UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := ifMatchVersion(w, r)
+	if !ok {
+		return
+	}
+	var req webhookRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	hook, fieldErrors := validateWebhook(req)
+	if len(fieldErrors) > 0 {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeValidationFailed, "Webhook data failed validation", fieldErrors...)
+		return
+	}
+	hook.ID = id
+
+	previous, hook, err := webhooks.Replace(hook, expectedVersion)
+	switch {
+	case errors.Is(err, webhooks.ErrVersionConflict):
+		versionConflict(w, r, "webhook", hook.Version)
+		return
+	case errors.Is(err, webhooks.ErrNotFound):
+		webhookNotFound(w, r, id)
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to save webhook", "webhookId", id, "error", err)
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not save webhook")
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionWebhookUpdate,
+		EntityType: audit.EntityWebhook,
+		EntityID:   strconv.Itoa(id),
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(hook),
+	})
+
+	setETag(w, hook.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(hook)
+}
+
+// DeleteWebhookHandler removes a webhook subscription.
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+	hook, err := webhooks.Delete(id)
+	if errors.Is(err, webhooks.ErrNotFound) {
+		webhookNotFound(w, r, id)
+		return
+	} else if err != nil {
+		slog.ErrorContext(r.Context(), "failed to save webhook deletion", "webhookId", id, "error", err)
+		problem.Respond(w, r, http.StatusInternalServerError, problem.CodeInternal, "Could not delete webhook")
+		return
+	}
+	recordAudit(r, audit.Entry{
+		Action:     audit.ActionWebhookDelete,
+		EntityType: audit.EntityWebhook,
+		EntityID:   strconv.Itoa(id),
+		Before:     audit.Snapshot(hook),
+	})
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetWebhookDeliveriesHandler returns the delivery log, newest first, optionally filtered by webhookId and
+// status (pending, succeeded or dead).
+func GetWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	query := &queryParser{values: r.URL.Query()}
+	webhookID := query.nonNegativeInt("webhookId")
+	status := r.URL.Query().Get("status")
+	if status != "" && status != webhooks.StatusPending && status != webhooks.StatusSucceeded && status != webhooks.StatusDead {
+		query.errors = append(query.errors, problem.FieldError{Field: "status", Message: "must be pending, succeeded or dead"})
+	}
+	if query.respondInvalidQuery(w, r) {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.Deliveries(webhookID, status))
+}
+
+// GetDeadLettersHandler lists deliveries that failed every attempt.
+func GetDeadLettersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.Deliveries(0, webhooks.StatusDead))
+}
+
+// RetryDeliveryHandler starts a new round of attempts for a dead delivery.
+func RetryDeliveryHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "deliveryID"), 10, 64)
+	if err != nil {
+		problem.Respond(w, r, http.StatusBadRequest, problem.CodeInvalidPathParam, "Invalid delivery ID format in URL path",
+			problem.FieldError{Field: "deliveryID", Message: "must be an integer"})
+		return
+	}
+	delivery, err := webhooks.Redeliver(id)
+	switch {
+	case errors.Is(err, webhooks.ErrNotDead):
+		problem.Respond(w, r, http.StatusConflict, problem.CodeDeliveryNotDead, "Only dead deliveries can be retried")
+		return
+	case errors.Is(err, webhooks.ErrQueueFull):
+		problem.Respond(w, r, http.StatusServiceUnavailable, problem.CodeQueueFull, "Too many deliveries are pending; retry later")
+		return
+	case err != nil:
+		problem.Respond(w, r, http.StatusNotFound, problem.CodeDeliveryNotFound, "Delivery not found for ID: "+strconv.FormatInt(id, 10))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(delivery)
+}
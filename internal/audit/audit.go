@@ -0,0 +1,309 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/jsonl"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionPolicyConfigure    = "policy.configure"
+	ActionPolicyPatch        = "policy.patch"
+	ActionPolicyUpdate       = "policy.update"
+	ActionPolicyEnable       = "policy.enable"
+	ActionPolicyDisable      = "policy.disable"
+	ActionPolicyReload       = "policy.reload"
+	ActionStudentCreate      = "student.create"
+	ActionStudentUpdate      = "student.update"
+	ActionCompanyCreate      = "company.create"
+	ActionCompanyUpdate      = "company.update"
+	ActionDriveCreate        = "drive.create"
+	ActionDriveUpdate        = "drive.update"
+	ActionWebhookCreate      = "webhook.create"
+	ActionWebhookUpdate      = "webhook.update"
+	ActionWebhookDelete      = "webhook.delete"
+	ActionPreferencesUpdate  = "notification.preferences"
+	ActionOfferRecord        = "offer.record"
+	ActionApplicationCreate  = "application.create"
+	ActionApplicationDenied  = "application.denied"
+	ActionApplicationRound   = "application.round"
+	ActionEligibilityChecked = "eligibility.check"
+)
+
+// Entity types referenced by audit entries.
+const (
+	EntityPolicy      = "policy"
+	EntityStudent     = "student"
+	EntityCompany     = "company"
+	EntityDrive       = "drive"
+	EntityWebhook     = "webhook"
+	EntityApplication = "application"
+)
+
+// Entry is a single immutable audit record. Before and After hold JSON snapshots of the entity
+// so a decision can be reproduced exactly; either may be empty (e.g. Before on creation).
+type Entry struct {
+	ID         int64           `json:"id"`
+	Timestamp  time.Time       `json:"timestamp"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entityType"`
+	EntityID   string          `json:"entityId"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Reasons    []string        `json:"reasons,omitempty"`
+}
+
+// Filter selects entries in Query. Zero-valued fields match everything.
+type Filter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   string
+	From       time.Time // Inclusive.
+	To         time.Time // Exclusive.
+	AfterID    int64     // Exclusive; lets clients page through the log with the last ID they received.
+	Limit      int       // Maximum number of entries returned; zero means no limit.
+}
+
+// TailSize is the number of recent entries kept in memory. Queries reaching further back read the log file.
+// It is a variable so tests can lower it.
+var TailSize = 10000
+
+// dispatchQueueSize bounds the entries waiting for the listeners. Once it is full, Record waits for them.
+const dispatchQueueSize = 1024
+
+var (
+	mutex     sync.Mutex
+	file      *os.File
+	path      string  // Of file, which queries open separately to read entries older than the tail.
+	entries   []Entry // The most recent entries, oldest first: TailSize of them, or up to twice that between trims.
+	nextID    int64   = 1
+	listeners []func(Entry)
+	dispatch  chan dispatched // Feeds the listeners; nil until the first AddListener.
+)
+
+// dispatched is an entry queued for the listeners registered when it was recorded.
+type dispatched struct {
+	entry     Entry
+	listeners []func(Entry)
+}
+
+// AddListener registers fn to be called with every entry after it has been recorded, e.g. to publish change
+// events. Listeners run on a single dispatch goroutine that receives entries in ID order, so a listener that
+// blocks holds up the others and, once the queue is full, Record.
+func AddListener(fn func(Entry)) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	listeners = append(listeners, fn)
+	if dispatch == nil {
+		dispatch = make(chan dispatched, dispatchQueueSize)
+		go deliver(dispatch)
+	}
+}
+
+// deliver calls the listeners with each queued entry.
+func deliver(queue <-chan dispatched) {
+	for d := range queue {
+		for _, fn := range d.listeners {
+			fn(d.entry)
+		}
+	}
+}
+
+// Open loads existing entries from the JSON Lines file at path and keeps it open for appending.
+// Every later Record is written and synced to this file before it becomes visible to Query.
+// A last line torn by a crash during Record is truncated away; damage anywhere else fails the open.
+// Only the last TailSize entries are kept in memory.
+func Open(logPath string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening audit log %s: %w", logPath, err)
+	}
+
+	loaded := []Entry{}
+	count, lastID := 0, int64(0)
+	repair, err := jsonl.Load(f, func(line []byte) error {
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return err
+		}
+		loaded = trimTail(append(loaded, e))
+		count++
+		lastID = e.ID
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading audit log %s: %w", logPath, err)
+	}
+	if repair.DroppedBytes > 0 {
+		slog.Warn("audit log ended with a torn entry; truncated it", "path", logPath, "droppedBytes", repair.DroppedBytes)
+	}
+
+	if file != nil {
+		file.Close()
+	}
+	file = f
+	path = logPath
+	entries = loaded
+	nextID = lastID + 1
+	slog.Info("audit log opened", "path", logPath, "entries", count)
+	return nil
+}
+
+// trimTail drops the oldest of list's entries, keeping TailSize. It copies only once twice that many have
+// built up, so appending stays cheap.
+func trimTail(list []Entry) []Entry {
+	if len(list) < 2*TailSize {
+		return list
+	}
+	return append([]Entry(nil), list[len(list)-TailSize:]...)
+}
+
+// Snapshot marshals v for use as an entry's Before or After value.
+// Marshalling failures are logged and produce an empty snapshot rather than losing the entry.
+func Snapshot(v interface{}) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Warn("could not snapshot value for audit log", "type", fmt.Sprintf("%T", v), "error", err)
+		return nil
+	}
+	return data
+}
+
+// Record assigns an ID and timestamp to e, appends it to the log and queues it for the listeners.
+// If the log file cannot be written the entry is still kept in memory and the error is returned.
+func Record(e Entry) (Entry, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	e.ID = nextID
+	nextID++
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+	entries = trimTail(append(entries, e))
+	err := write(e)
+	if dispatch != nil {
+		dispatch <- dispatched{e, listeners} // Queued under the lock, so listeners receive entries in ID order.
+	}
+	return e, err
+}
+
+// write appends e to the log file, if one is open. mutex must be held.
+func write(e Entry) error {
+	if file == nil {
+		return nil
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Query returns the entries matching f, oldest first. Entries older than the in-memory tail are read from the
+// log file, which takes a scan from its start.
+func Query(f Filter) []Entry {
+	result := []Entry{}
+	full := func() bool { return f.Limit > 0 && len(result) == f.Limit }
+	var offset int64
+	for {
+		mutex.Lock()
+		oldest := nextID
+		if len(entries) > 0 {
+			oldest = entries[0].ID
+		}
+		if f.AfterID+1 >= oldest || path == "" {
+			for _, e := range entries {
+				if full() {
+					break
+				}
+				if f.matches(e) {
+					result = append(result, e)
+				}
+			}
+			mutex.Unlock()
+			return result
+		}
+		logPath := path
+		mutex.Unlock()
+
+		// Lines are never changed once written, so the file is read without the lock. If the tail moves on
+		// meanwhile, the next pass reads the entries it dropped.
+		var err error
+		offset, err = scanFile(logPath, offset, oldest, func(e Entry) bool {
+			if f.matches(e) {
+				result = append(result, e)
+			}
+			return !full()
+		})
+		if err != nil {
+			slog.Warn("could not read older entries from the audit log", "path", logPath, "error", err)
+		}
+		if full() {
+			return result
+		}
+		f.AfterID = oldest - 1
+	}
+}
+
+// scanFile calls visit with each entry of the log file at logPath from offset on, until it reaches an entry
+// with an ID of at least stop or visit returns false. It returns the offset of the first line not visited.
+func scanFile(logPath string, offset, stop int64, visit func(Entry) bool) (int64, error) {
+	f, err := os.Open(logPath)
+	if err != nil {
+		return offset, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset, err
+	}
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return offset, err
+		}
+		if len(line) == 0 || line[len(line)-1] != '\n' {
+			return offset, nil // The end of the file, or a line still being written.
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return offset, fmt.Errorf("entry at offset %d: %w", offset, err)
+		}
+		if e.ID >= stop {
+			return offset, nil
+		}
+		offset += int64(len(line))
+		if !visit(e) {
+			return offset, nil
+		}
+	}
+}
+
+// matches reports whether e passes every condition of f except Limit.
+func (f Filter) matches(e Entry) bool {
+	return e.ID > f.AfterID &&
+		(f.Actor == "" || e.Actor == f.Actor) &&
+		(f.Action == "" || e.Action == f.Action) &&
+		(f.EntityType == "" || e.EntityType == f.EntityType) &&
+		(f.EntityID == "" || e.EntityID == f.EntityID) &&
+		(f.From.IsZero() || !e.Timestamp.Before(f.From)) &&
+		(f.To.IsZero() || e.Timestamp.Before(f.To))
+}
@@ -0,0 +1,193 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpenTruncatesTornLastEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	content := `{"id":1,"action":"student.create","entityType":"student","entityId":"1"}` + "\n" + `{"id":2,"act`
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(path); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := Query(Filter{}); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("loaded %+v, want only entry 1", got)
+	}
+
+	e, err := Record(Entry{Actor: "coordinator:test", Action: ActionStudentUpdate, EntityType: EntityStudent, EntityID: "1"})
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if e.ID != 2 {
+		t.Errorf("recorded ID %d, want 2", e.ID)
+	}
+	if err := Open(path); err != nil {
+		t.Fatalf("reopening after the repair: %v", err)
+	}
+	if got := Query(Filter{}); len(got) != 2 {
+		t.Errorf("reloaded %d entries, want 2", len(got))
+	}
+}
+
+func TestOpenRejectsCorruptEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	content := `{"id":1}` + "\n" + `not json` + "\n" + `{"id":3}` + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(path); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Open error = %v, want corruption on line 2", err)
+	}
+}
+
+func TestQueryPages(t *testing.T) {
+	if err := Open(filepath.Join(t.TempDir(), "audit.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		action := ActionStudentUpdate
+		if i%2 == 1 {
+			action = ActionCompanyUpdate
+		}
+		if _, err := Record(Entry{Actor: "coordinator:test", Action: action}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{"all", Filter{}, []int64{1, 2, 3, 4, 5}},
+		{"first page", Filter{Limit: 2}, []int64{1, 2}},
+		{"next page", Filter{AfterID: 2, Limit: 2}, []int64{3, 4}},
+		{"last page", Filter{AfterID: 4, Limit: 2}, []int64{5}},
+		{"past the end", Filter{AfterID: 5}, []int64{}},
+		{"filtered page", Filter{Action: ActionStudentUpdate, AfterID: 1, Limit: 1}, []int64{3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Query(tt.filter)
+			ids := make([]int64, len(got))
+			for i, e := range got {
+				ids[i] = e.ID
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("got IDs %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("got IDs %v, want %v", ids, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestQueryReadsEntriesOlderThanTheTail(t *testing.T) {
+	saved := TailSize
+	TailSize = 3
+	t.Cleanup(func() { TailSize = saved })
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		action := ActionStudentUpdate
+		if i%2 == 1 {
+			action = ActionCompanyUpdate
+		}
+		if _, err := Record(Entry{Actor: "coordinator:test", Action: action}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []int64
+	}{
+		{"all", Filter{}, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"first page", Filter{Limit: 4}, []int64{1, 2, 3, 4}},
+		{"page across the tail", Filter{AfterID: 4, Limit: 4}, []int64{5, 6, 7, 8}},
+		{"page in the tail", Filter{AfterID: 8, Limit: 4}, []int64{9, 10}},
+		{"filtered", Filter{Action: ActionCompanyUpdate, AfterID: 1, Limit: 3}, []int64{2, 4, 6}},
+	}
+	check := func(t *testing.T) {
+		t.Helper()
+		mutex.Lock()
+		kept := len(entries)
+		mutex.Unlock()
+		if kept >= 2*TailSize {
+			t.Errorf("%d entries kept in memory, want fewer than %d", kept, 2*TailSize)
+		}
+		for _, tt := range tests {
+			got := Query(tt.filter)
+			ids := make([]int64, len(got))
+			for i, e := range got {
+				ids[i] = e.ID
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("%s: got IDs %v, want %v", tt.name, ids, tt.want)
+			}
+		}
+	}
+	check(t)
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+	check(t)
+}
+
+func TestListenersReceiveEntriesInIDOrder(t *testing.T) {
+	if err := Open(filepath.Join(t.TempDir(), "audit.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	const writers, perWriter = 8, 50
+	received := make(chan int64, writers*perWriter)
+	AddListener(func(e Entry) {
+		if e.Actor == "coordinator:order-test" {
+			select {
+			case received <- e.ID:
+			default: // A listener left from an earlier run of the test.
+			}
+		}
+	})
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWriter; i++ {
+				if _, err := Record(Entry{Actor: "coordinator:order-test", Action: ActionStudentUpdate}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var last int64
+	for i := 0; i < writers*perWriter; i++ {
+		select {
+		case id := <-received:
+			if id <= last {
+				t.Fatalf("listener got entry %d after entry %d", id, last)
+			}
+			last = id
+		case <-time.After(5 * time.Second):
+			t.Fatalf("listener received %d of %d entries", i, writers*perWriter)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"sync"
+)
+
+// Coordinator accounts let placement cell staff log in with a name and password and receive a session token, so
+// no long-lived credential has to be built into the frontend.
+var (
+	accountsMutex sync.RWMutex
+	accounts      = map[string][sha256.Size]byte{} // Name to password digest.
+)
+
+// SetCoordinatorAccounts replaces the coordinator accounts with the given names and passwords.
+func SetCoordinatorAccounts(passwords map[string]string) {
+	accountsMutex.Lock()
+	defer accountsMutex.Unlock()
+	accounts = make(map[string][sha256.Size]byte, len(passwords))
+	for name, password := range passwords {
+		accounts[name] = sha256.Sum256([]byte(password))
+	}
+}
+
+// HasCoordinatorAccounts reports whether any coordinator can log in.
+func HasCoordinatorAccounts() bool {
+	accountsMutex.RLock()
+	defer accountsMutex.RUnlock()
+	return len(accounts) > 0
+}
+
+// VerifyCoordinator reports whether password is the password of the named coordinator account.
+// Digests are compared in constant time, so response times do not reveal how much of a guess was right.
+func VerifyCoordinator(name, password string) bool {
+	accountsMutex.RLock()
+	want, ok := accounts[name]
+	accountsMutex.RUnlock()
+	got := sha256.Sum256([]byte(password))
+	return subtle.ConstantTimeCompare(want[:], got[:]) == 1 && ok
+}
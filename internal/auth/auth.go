@@ -0,0 +1,175 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/problem"
+)
+
+// Role identifies what an authenticated caller is allowed to do.
+type Role string
+
+const (
+	// RoleCoordinator is held by placement cell staff. Coordinators can read and modify all data.
+	RoleCoordinator Role = "coordinator"
+	// RoleStudent is held by an individual student and is confined to the /me endpoints.
+	RoleStudent Role = "student"
+)
+
+// Principal is the identity attached to a request once its bearer token has been verified.
+type Principal struct {
+	Role      Role   `json:"role"`
+	Name      string `json:"name"`
+	StudentID int    `json:"studentId,omitempty"` // Only set for RoleStudent.
+}
+
+type contextKey struct{}
+
+// session is a registered token. Only fixed tokens have a zero expiresAt, meaning they do not expire.
+type session struct {
+	principal Principal
+	expiresAt time.Time
+	fixed     bool // Registered from configuration; it cannot be revoked, since it would only come back on restart.
+}
+
+var (
+	tokensMutex sync.RWMutex
+	tokens      = map[string]session{}
+)
+
+// RegisterToken associates a pre-shared token with a principal, e.g. the coordinator token supplied at startup.
+func RegisterToken(token string, principal Principal) {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	tokens[token] = session{principal: principal, fixed: true}
+}
+
+// IssueSession generates a random bearer token for the principal that expires after ttl, which must be positive.
+// Expired sessions are pruned whenever a new one is issued, so tokens that are never used again do not pile up.
+func IssueSession(principal Principal, ttl time.Duration) (token string, expiresAt time.Time, err error) {
+	if ttl <= 0 {
+		return "", time.Time{}, errors.New("session lifetime must be positive")
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", time.Time{}, err
+	}
+	token = hex.EncodeToString(buf)
+	now := time.Now()
+	expiresAt = now.Add(ttl).UTC()
+
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	for t, s := range tokens {
+		if !s.fixed && now.After(s.expiresAt) {
+			delete(tokens, t)
+		}
+	}
+	tokens[token] = session{principal: principal, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+// Revoke invalidates an issued token, e.g. when its holder logs out. Tokens from RegisterToken are kept.
+func Revoke(token string) {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	if !tokens[token].fixed {
+		delete(tokens, token)
+	}
+}
+
+// RevokeStudent invalidates every token issued to the student and returns how many there were, e.g. when a
+// student token has leaked.
+func RevokeStudent(studentID int) int {
+	tokensMutex.Lock()
+	defer tokensMutex.Unlock()
+	revoked := 0
+	for token, s := range tokens {
+		if !s.fixed && s.principal.Role == RoleStudent && s.principal.StudentID == studentID {
+			delete(tokens, token)
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// lookup returns the principal registered for a token, if any. Expired tokens are removed.
+func lookup(token string) (Principal, bool) {
+	tokensMutex.RLock()
+	s, ok := tokens[token]
+	tokensMutex.RUnlock()
+	if ok && !s.expiresAt.IsZero() && time.Now().After(s.expiresAt) {
+		Revoke(token)
+		return Principal{}, false
+	}
+	return s.principal, ok
+}
+
+// BearerToken returns the token in the request's Authorization header, if it uses the Bearer scheme.
+func BearerToken(r *http.Request) (string, bool) {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return strings.TrimSpace(token), found
+}
+
+// FromContext returns the principal attached to the request context by Authenticate.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextKey{}).(Principal)
+	return p, ok
+}
+
+// Authenticate resolves an "Authorization: Bearer <token>" header into a Principal and stores it on the request context.
+// Requests without the header pass through anonymously; requests with an unknown token are rejected.
+func Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, found := BearerToken(r)
+		if !found {
+			problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authorization header must use the Bearer scheme")
+			return
+		}
+		principal, ok := lookup(token)
+		if !ok {
+			problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Invalid or expired token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, principal)))
+	})
+}
+
+// RequireRole returns middleware that only lets through requests authenticated with the given role.
+func RequireRole(role Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := FromContext(r.Context())
+			if !ok {
+				problem.Respond(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "Authentication required")
+				return
+			}
+			if principal.Role != role {
+				problem.Respond(w, r, http.StatusForbidden, problem.CodeForbidden, "This endpoint requires the "+string(role)+" role")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Actor returns the identifier recorded for this principal in the audit log, e.g. "coordinator:alice" or "student:42".
+func (p Principal) Actor() string {
+	if p.Role == RoleStudent {
+		return fmt.Sprintf("student:%d", p.StudentID)
+	}
+	return string(p.Role) + ":" + p.Name
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIssueSessionExpires(t *testing.T) {
+	principal := Principal{Role: RoleCoordinator, Name: "alice"}
+	token, expiresAt, err := IssueSession(principal, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiresAt.IsZero() {
+		t.Fatal("expiresAt is zero for a session with a ttl")
+	}
+	if got, ok := lookup(token); !ok || got != principal {
+		t.Fatalf("lookup = %+v, %v; want %+v, true", got, ok, principal)
+	}
+
+	tokensMutex.Lock()
+	s := tokens[token]
+	s.expiresAt = time.Now().Add(-time.Second)
+	tokens[token] = s
+	tokensMutex.Unlock()
+
+	if _, ok := lookup(token); ok {
+		t.Fatal("expired session still resolves")
+	}
+	tokensMutex.RLock()
+	_, kept := tokens[token]
+	tokensMutex.RUnlock()
+	if kept {
+		t.Fatal("expired session was not removed")
+	}
+}
+
+func TestRevokeKeepsRegisteredTokens(t *testing.T) {
+	RegisterToken("fixed-token", Principal{Role: RoleCoordinator, Name: "ops"})
+	issued, _, err := IssueSession(Principal{Role: RoleStudent, StudentID: 7}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Revoke("fixed-token")
+	Revoke(issued)
+
+	if _, ok := lookup("fixed-token"); !ok {
+		t.Error("registered token was revoked")
+	}
+	if _, ok := lookup(issued); ok {
+		t.Error("issued token survived Revoke")
+	}
+}
+
+func TestIssueSessionNeedsLifetime(t *testing.T) {
+	if _, _, err := IssueSession(Principal{Role: RoleStudent, StudentID: 1}, 0); err == nil {
+		t.Error("IssueSession issued a token that never expires")
+	}
+}
+
+func TestIssueSessionPrunesExpired(t *testing.T) {
+	stale, _, err := IssueSession(Principal{Role: RoleStudent, StudentID: 8}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokensMutex.Lock()
+	s := tokens[stale]
+	s.expiresAt = time.Now().Add(-time.Second)
+	tokens[stale] = s
+	tokensMutex.Unlock()
+
+	if _, _, err := IssueSession(Principal{Role: RoleStudent, StudentID: 9}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	tokensMutex.RLock()
+	_, kept := tokens[stale]
+	tokensMutex.RUnlock()
+	if kept {
+		t.Error("expired session survived issuing a new one")
+	}
+}
+
+func TestRevokeStudent(t *testing.T) {
+	first, _, _ := IssueSession(Principal{Role: RoleStudent, StudentID: 21}, time.Hour)
+	second, _, _ := IssueSession(Principal{Role: RoleStudent, StudentID: 21}, time.Hour)
+	other, _, _ := IssueSession(Principal{Role: RoleStudent, StudentID: 22}, time.Hour)
+	coordinator, _, _ := IssueSession(Principal{Role: RoleCoordinator, Name: "alice"}, time.Hour)
+
+	if n := RevokeStudent(21); n != 2 {
+		t.Errorf("RevokeStudent revoked %d tokens, want 2", n)
+	}
+	for _, token := range []string{first, second} {
+		if _, ok := lookup(token); ok {
+			t.Error("student token survived RevokeStudent")
+		}
+	}
+	for _, token := range []string{other, coordinator} {
+		if _, ok := lookup(token); !ok {
+			t.Error("RevokeStudent revoked another principal's token")
+		}
+	}
+	if n := RevokeStudent(21); n != 0 {
+		t.Errorf("second RevokeStudent revoked %d tokens, want 0", n)
+	}
+}
+
+func TestVerifyCoordinator(t *testing.T) {
+	SetCoordinatorAccounts(map[string]string{"alice": "s3cret"})
+	t.Cleanup(func() { SetCoordinatorAccounts(nil) })
+
+	tests := []struct {
+		name, password string
+		want           bool
+	}{
+		{"alice", "s3cret", true},
+		{"alice", "wrong", false},
+		{"alice", "", false},
+		{"bob", "s3cret", false},
+		{"bob", "", false},
+	}
+	for _, tt := range tests {
+		if got := VerifyCoordinator(tt.name, tt.password); got != tt.want {
+			t.Errorf("VerifyCoordinator(%q, %q) = %v, want %v", tt.name, tt.password, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	RegisterToken("known", Principal{Role: RoleCoordinator, Name: "ops"})
+	handler := Authenticate(RequireRole(RoleCoordinator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})))
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Basic abc", http.StatusUnauthorized},
+		{"Bearer unknown", http.StatusUnauthorized},
+		{"Bearer known", http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("Authorization %q: status %d, want %d", tt.header, rec.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+// Package changes detects student–company pairs whose eligibility flips after a mutation, e.g. when a policy
+// threshold or a student's salary changes. It keeps the current verdict for every pair, re-evaluates the pairs a
+// change event can affect, and appends every flip to a JSON Lines log so the office can act on the delta.
+// The verdicts are saved next to the log, so flips caused while the server was down are reported on start.
+// Saving rewrites every verdict, so it happens at most once per SaveInterval and on Flush rather than after
+// every event; flips logged since the last save are replayed onto the saved verdicts when the log is opened.
+package changes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/jsonl"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// queueSize bounds the events waiting to be examined; if it overflows, a full re-evaluation is scheduled instead.
+const queueSize = 256
+
+// SaveInterval is how often changed verdicts are written to the verdicts file. Flush writes them immediately.
+var SaveInterval = 10 * time.Second
+
+// Verdict is the outcome of an eligibility check, reduced to what matters for change detection.
+type Verdict struct {
+	Eligible bool     `json:"eligible"`
+	Codes    []string `json:"codes,omitempty"` // Codes of the decisions that blocked the student, e.g. "cgpa_below_minimum".
+}
+
+// Change records one pair gaining or losing eligibility. Cause is the event after which it was detected.
+type Change struct {
+	ID          int64     `json:"id"`
+	DetectedAt  time.Time `json:"detectedAt"`
+	StudentID   int       `json:"studentId"`
+	StudentName string    `json:"studentName"`
+	CompanyID   string    `json:"companyId"`
+	CompanyName string    `json:"companyName"`
+	Previous    Verdict   `json:"previous"`
+	Current     Verdict   `json:"current"`
+	Cause       string    `json:"cause"`             // Event type, e.g. "policy.patch", or CauseStartup or CauseResync.
+	CauseID     string    `json:"causeId,omitempty"` // ID of the changed entity.
+	EventID     int64     `json:"eventId,omitempty"`
+}
+
+// Filter selects changes in Query. Zero-valued fields match everything.
+type Filter struct {
+	AfterID   int64     // Exclusive.
+	Since     time.Time // Inclusive.
+	StudentID int
+	CompanyID string
+	Eligible  *bool // true for gained eligibility, false for lost.
+}
+
+// Causes of flips that are not attributed to a single event.
+const (
+	CauseStartup = "startup" // Found by comparing the verdicts saved at the last run with those at start.
+	CauseResync  = "resync"  // Found by a full re-evaluation after the event queue overflowed.
+)
+
+type pair struct {
+	studentID int
+	companyID string
+}
+
+// savedVerdict is one entry of the verdicts file.
+type savedVerdict struct {
+	StudentID int    `json:"studentId"`
+	CompanyID string `json:"companyId"`
+	Verdict
+}
+
+var (
+	mutex        sync.Mutex
+	file         *os.File
+	verdictsPath string
+	verdicts     = map[pair]Verdict{}
+	dirty        bool // The verdicts changed since they were last saved.
+	changes      []Change
+	nextID       int64 = 1
+
+	queue  = make(chan events.Event, queueSize)
+	resync = make(chan struct{}, 1)
+)
+
+// VerdictsPath returns the file the verdicts are saved to for the change log at logPath, e.g.
+// "data/eligibility_changes.verdicts.json" for "data/eligibility_changes.jsonl".
+func VerdictsPath(logPath string) string {
+	return strings.TrimSuffix(logPath, filepath.Ext(logPath)) + ".verdicts.json"
+}
+
+// Open loads previously detected changes from the JSON Lines file at path and keeps it open for appending.
+// A last line torn by a crash is truncated away; damage anywhere else fails the open. The verdicts saved at
+// VerdictsPath(path), if any, become the baseline Start compares against, updated with the logged changes.
+func Open(path string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening eligibility change log %s: %w", path, err)
+	}
+	loaded := []Change{}
+	repair, err := jsonl.Load(f, func(line []byte) error {
+		var c Change
+		if err := json.Unmarshal(line, &c); err != nil {
+			return err
+		}
+		loaded = append(loaded, c)
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading eligibility change log %s: %w", path, err)
+	}
+	if repair.DroppedBytes > 0 {
+		slog.Warn("eligibility change log ended with a torn entry; truncated it", "path", path, "droppedBytes", repair.DroppedBytes)
+	}
+	saved, err := loadVerdicts(VerdictsPath(path))
+	if err != nil {
+		f.Close()
+		return err
+	}
+	// Changes are logged as they are found but verdicts are saved later, so the log can be ahead of the file.
+	for _, c := range loaded {
+		saved[pair{c.StudentID, c.CompanyID}] = c.Current
+	}
+
+	if file != nil {
+		file.Close()
+	}
+	file = f
+	verdictsPath = VerdictsPath(path)
+	verdicts = saved
+	dirty = false
+	changes = loaded
+	nextID = 1
+	if len(loaded) > 0 {
+		nextID = loaded[len(loaded)-1].ID + 1
+	}
+	return nil
+}
+
+// loadVerdicts reads the verdicts file at path. A missing file yields no verdicts.
+func loadVerdicts(path string) (map[pair]Verdict, error) {
+	loaded := map[pair]Verdict{}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return loaded, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading eligibility verdicts %s: %w", path, err)
+	}
+	var saved []savedVerdict
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("eligibility verdicts %s are corrupt: %w", path, err)
+	}
+	for _, v := range saved {
+		loaded[pair{v.StudentID, v.CompanyID}] = v.Verdict
+	}
+	return loaded, nil
+}
+
+// saveVerdicts replaces the verdicts file with the current verdicts. mutex must be held. The file is written
+// under a temporary name and renamed, so a crash leaves either the old or the new verdicts.
+func saveVerdicts() error {
+	if verdictsPath == "" {
+		return nil
+	}
+	saved := make([]savedVerdict, 0, len(verdicts))
+	for key, v := range verdicts {
+		saved = append(saved, savedVerdict{key.studentID, key.companyID, v})
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	tmp := verdictsPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, verdictsPath)
+}
+
+// Flush saves the verdicts if they changed since the last save. It runs every SaveInterval once Start is called
+// and should be called on shutdown. Failures are logged and the verdicts stay marked for the next save.
+func Flush() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !dirty {
+		return
+	}
+	if err := saveVerdicts(); err != nil {
+		slog.Error("failed to save eligibility verdicts", "path", verdictsPath, "error", err)
+		return
+	}
+	dirty = false
+}
+
+// Start evaluates every pair, reporting flips against the verdicts loaded by Open under CauseStartup, and then
+// re-evaluates affected pairs after every published change event. It must be called before the server accepts
+// requests. Changed verdicts are saved every SaveInterval.
+func Start() {
+	evaluate(storage.AllStudents(), storage.AllCompanies(), events.Event{Type: CauseStartup})
+	events.AddListener(func(e events.Event) {
+		select {
+		case queue <- e:
+		default:
+			// The detector is behind; a full pass still finds every flip, only attributed to CauseResync.
+			slog.Warn("eligibility change queue full; event will be covered by a full re-evaluation", "eventId", e.ID, "eventType", e.Type)
+			select {
+			case resync <- struct{}{}:
+			default:
+			}
+		}
+	})
+	go func() {
+		for range time.Tick(SaveInterval) {
+			Flush()
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case e := <-queue:
+				handle(e)
+			case <-resync:
+				evaluate(storage.AllStudents(), storage.AllCompanies(), events.Event{Type: CauseResync})
+			}
+		}
+	}()
+}
+
+// handle re-evaluates the pairs an event can affect.
+func handle(e events.Event) {
+	students, companies := storage.AllStudents(), storage.AllCompanies()
+	switch e.Topic {
+	case events.TopicStudent, events.TopicOffer, events.TopicApplication:
+		if e.Topic != events.TopicApplication && storage.ActivePolicy().PlacementPercentage.Enabled {
+			break // A placement can move the campus placement percentage, which affects every placed student.
+		}
+		id, err := studentIDOf(e)
+		if err != nil {
+			break
+		}
+		if s, found := storage.FindStudentByID(id); found {
+			students = []models.Student{s}
+		}
+	case events.TopicCompany:
+		if c, found := storage.FindCompanyByID(e.EntityID); found {
+			companies = []models.Company{c}
+		}
+	case events.TopicPolicy:
+	default:
+		return // Drives and other events do not change eligibility.
+	}
+	evaluate(students, companies, e)
+}
+
+// studentIDOf returns the student an event is about. Application events carry the application, not the student.
+func studentIDOf(e events.Event) (int, error) {
+	if e.Topic != events.TopicApplication {
+		return strconv.Atoi(e.EntityID)
+	}
+	var application models.Application
+	err := json.Unmarshal(e.Data, &application)
+	return application.StudentID, err
+}
+
+// evaluate checks every given pair, stores the new verdicts and records those that flipped. Pairs seen for the
+// first time (new students or companies) only set a baseline.
+func evaluate(students []models.Student, companies []models.Company, cause events.Event) {
+	type result struct {
+		key     pair
+		student models.Student
+		company models.Company
+		verdict Verdict
+	}
+	var results []result
+	for _, s := range students {
+		for _, c := range companies {
+			results = append(results, result{pair{s.ID, c.ID}, s, c, verdictOf(eligibility.PerformEligibilityCheck(context.Background(), s, c))})
+		}
+	}
+
+	now := time.Now().UTC()
+	mutex.Lock()
+	var detected []Change
+	updated := false
+	for _, r := range results {
+		previous, seen := verdicts[r.key]
+		verdicts[r.key] = r.verdict
+		if !seen || !sameVerdict(previous, r.verdict) {
+			updated = true
+		}
+		if !seen || previous.Eligible == r.verdict.Eligible {
+			continue
+		}
+		c := Change{ID: nextID, DetectedAt: now, StudentID: r.student.ID, StudentName: r.student.FullName,
+			CompanyID: r.company.ID, CompanyName: r.company.Name, Previous: previous, Current: r.verdict,
+			Cause: cause.Type, CauseID: cause.EntityID, EventID: cause.ID}
+		nextID++
+		changes = append(changes, c)
+		detected = append(detected, c)
+	}
+	persist(detected)
+	if updated {
+		dirty = true
+	}
+	mutex.Unlock()
+
+	for _, c := range detected {
+		data, _ := json.Marshal(c)
+		events.Publish(events.TopicEligibility, events.TypeEligibilityChange, strconv.Itoa(c.StudentID)+":"+c.CompanyID, data)
+	}
+}
+
+// persist appends changes to the log file. mutex must be held. Write failures are logged; the changes stay queryable.
+func persist(detected []Change) {
+	if file == nil || len(detected) == 0 {
+		return
+	}
+	for _, c := range detected {
+		line, err := json.Marshal(c)
+		if err == nil {
+			_, err = file.Write(append(line, '\n'))
+		}
+		if err != nil {
+			slog.Error("failed to persist eligibility change", "changeId", c.ID, "error", err)
+			return
+		}
+	}
+	if err := file.Sync(); err != nil {
+		slog.Error("failed to sync eligibility change log", "error", err)
+	}
+}
+
+// sameVerdict reports whether a and b have the same outcome and blocking codes.
+func sameVerdict(a, b Verdict) bool {
+	if a.Eligible != b.Eligible || len(a.Codes) != len(b.Codes) {
+		return false
+	}
+	for i := range a.Codes {
+		if a.Codes[i] != b.Codes[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// verdictOf reduces an eligibility result to its verdict and the codes of the decisions that blocked it.
+func verdictOf(result models.EligibilityResult) Verdict {
+	v := Verdict{Eligible: result.IsEligible}
+	if !result.IsEligible {
+		for _, d := range result.Decisions {
+			if d.Outcome == models.DecisionBlocked {
+				v.Codes = append(v.Codes, d.Code)
+			}
+		}
+	}
+	return v
+}
+
+// Query returns the changes matching f, oldest first.
+func Query(f Filter) []Change {
+	mutex.Lock()
+	defer mutex.Unlock()
+	result := []Change{}
+	for _, c := range changes {
+		if c.ID <= f.AfterID || (!f.Since.IsZero() && c.DetectedAt.Before(f.Since)) {
+			continue
+		}
+		if (f.StudentID != 0 && c.StudentID != f.StudentID) || (f.CompanyID != "" && c.CompanyID != f.CompanyID) {
+			continue
+		}
+		if f.Eligible != nil && c.Current.Eligible != *f.Eligible {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
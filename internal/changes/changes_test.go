@@ -0,0 +1,140 @@
+package changes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/models"
+)
+
+func TestVerdictsPath(t *testing.T) {
+	if got, want := VerdictsPath("data/eligibility_changes.jsonl"), "data/eligibility_changes.verdicts.json"; got != want {
+		t.Errorf("VerdictsPath = %q, want %q", got, want)
+	}
+}
+
+func TestFlipsWhileDownAreReportedOnStart(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "changes.jsonl")
+	student := models.Student{ID: 1, FullName: "Asha", CGPA: 9}
+	company := models.Company{ID: "C1", Name: "Acme", OfferedSalary: 10}
+
+	// The first run only sets the baseline and saves it.
+	if err := Open(logPath); err != nil {
+		t.Fatal(err)
+	}
+	evaluate([]models.Student{student}, []models.Company{company}, events.Event{Type: CauseStartup})
+	if got := Query(Filter{}); len(got) != 0 {
+		t.Fatalf("baseline recorded changes: %+v", got)
+	}
+	baseline := verdicts[pair{1, "C1"}]
+
+	// Pretend the pair had the opposite verdict when the server stopped.
+	verdicts[pair{1, "C1"}] = Verdict{Eligible: !baseline.Eligible}
+	if err := saveVerdicts(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Open(logPath); err != nil {
+		t.Fatal(err)
+	}
+	evaluate([]models.Student{student}, []models.Company{company}, events.Event{Type: CauseStartup})
+	got := Query(Filter{})
+	if len(got) != 1 {
+		t.Fatalf("got %d changes after restart, want 1", len(got))
+	}
+	if got[0].Cause != CauseStartup || got[0].Current.Eligible != baseline.Eligible || got[0].StudentID != 1 || got[0].CompanyID != "C1" {
+		t.Errorf("unexpected change %+v", got[0])
+	}
+
+	// The change was logged, so a further restart reports nothing new even though the verdicts were not saved since.
+	if err := Open(logPath); err != nil {
+		t.Fatal(err)
+	}
+	evaluate([]models.Student{student}, []models.Company{company}, events.Event{Type: CauseStartup})
+	if got := Query(Filter{}); len(got) != 1 {
+		t.Errorf("got %d changes after the second restart, want 1", len(got))
+	}
+}
+
+func TestOpenTruncatesTornLastChange(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "changes.jsonl")
+	content := `{"id":1,"studentId":1,"companyId":"C1","cause":"policy.patch"}` + "\n" + `{"id":2,"stud`
+	if err := os.WriteFile(logPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(logPath); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if got := Query(Filter{}); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("loaded %+v, want only change 1", got)
+	}
+	if nextID != 2 {
+		t.Errorf("nextID = %d, want 2", nextID)
+	}
+}
+
+func TestOpenRejectsCorruptVerdicts(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "changes.jsonl")
+	if err := os.WriteFile(VerdictsPath(logPath), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(logPath); err == nil {
+		t.Fatal("Open accepted a corrupt verdicts file")
+	}
+}
+
+func TestVerdictsAreSavedOnFlush(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "changes.jsonl")
+	if err := Open(logPath); err != nil {
+		t.Fatal(err)
+	}
+	student := models.Student{ID: 1, FullName: "Asha", CGPA: 9}
+	companies := []models.Company{{ID: "C1", Name: "Acme", OfferedSalary: 10}, {ID: "C2", Name: "Beta", OfferedSalary: 20}}
+
+	evaluate([]models.Student{student}, companies, events.Event{Type: CauseStartup})
+	if _, err := os.Stat(VerdictsPath(logPath)); !os.IsNotExist(err) {
+		t.Fatalf("verdicts were written before a flush (stat error %v)", err)
+	}
+	Flush()
+	saved, err := loadVerdicts(VerdictsPath(logPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("saved %d verdicts, want 2", len(saved))
+	}
+
+	// Nothing changed, so a further flush leaves the file alone.
+	if err := os.Remove(VerdictsPath(logPath)); err != nil {
+		t.Fatal(err)
+	}
+	evaluate([]models.Student{student}, companies, events.Event{Type: CauseResync})
+	Flush()
+	if _, err := os.Stat(VerdictsPath(logPath)); !os.IsNotExist(err) {
+		t.Errorf("unchanged verdicts were saved again (stat error %v)", err)
+	}
+}
+
+func TestOpenAppliesChangesLoggedAfterTheLastSave(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "changes.jsonl")
+	saved := `[{"studentId":1,"companyId":"C1","eligible":true},{"studentId":2,"companyId":"C1","eligible":true}]`
+	if err := os.WriteFile(VerdictsPath(logPath), []byte(saved), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	logged := `{"id":1,"studentId":1,"companyId":"C1","previous":{"eligible":true},"current":{"eligible":false,"codes":["below_target"]}}` + "\n"
+	if err := os.WriteFile(logPath, []byte(logged), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Open(logPath); err != nil {
+		t.Fatal(err)
+	}
+	if v := verdicts[pair{1, "C1"}]; v.Eligible || len(v.Codes) != 1 {
+		t.Errorf("verdict for the logged pair = %+v, want the logged current verdict", v)
+	}
+	if v := verdicts[pair{2, "C1"}]; !v.Eligible {
+		t.Errorf("verdict for an unlogged pair = %+v, want the saved verdict", v)
+	}
+}
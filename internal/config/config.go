@@ -0,0 +1,509 @@
+// Package config loads the server configuration. Every setting has a default and can be set, in increasing order
+// of precedence, in an optional YAML or JSON config file, in an environment variable, or with a command-line flag.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Storage backends. Only the in-memory store exists today; the setting is validated so a config written for a
+// future backend fails loudly instead of silently running in memory.
+const (
+	BackendMemory = "memory"
+)
+
+// Backends lists the supported storage backends.
+var Backends = []string{BackendMemory}
+
+// Notification transports.
+const (
+	TransportSMTP = "smtp"
+	TransportFile = "file"
+	TransportLog  = "log"
+	TransportNone = "none"
+)
+
+// Transports lists the supported notification transports.
+var Transports = []string{TransportSMTP, TransportFile, TransportLog, TransportNone}
+
+// Config is the server configuration. Field names are also the keys of the config file.
+type Config struct {
+	Addr           string   `json:"addr"` // host:port to listen on
+	TLS            TLS      `json:"tls"`
+	AllowedOrigins []string `json:"allowedOrigins"` // CORS origins, or "*" for any
+	DataDir        string   `json:"dataDir"`        // Directory holding students.json and company.json
+	StorageBackend string   `json:"storageBackend"`
+	LogLevel       string   `json:"logLevel"` // debug, info, warn or error
+	Timeouts       Timeouts `json:"timeouts"`
+
+	// Files the server appends to. Left empty, they default to files in DataDir once everything is loaded.
+	AuditLogPath string `json:"auditLogPath"`
+	WebhooksPath string `json:"webhooksPath"` // Webhook subscriptions, including their secrets
+
+	CampusTimezone string      `json:"campusTimezone"` // IANA zone drive days are counted in; empty means the server's zone
+	Eligibility    Eligibility `json:"eligibility"`
+	Notify         Notify      `json:"notify"`
+	PolicyFile     PolicyFile  `json:"policyFile"`
+	Auth           Auth        `json:"auth"`
+}
+
+// Eligibility configures the result cache and the change detector.
+type Eligibility struct {
+	CacheSize   int    `json:"cacheSize"`   // Student–company results kept; 0 disables the cache
+	ChangesPath string `json:"changesPath"` // Eligibility change log; defaults to a file in DataDir
+}
+
+// Notify configures how students are emailed.
+type Notify struct {
+	Transport string `json:"transport"` // smtp, file, log or none
+	File      string `json:"file"`      // Messages are appended here by the file transport; defaults to a file in DataDir
+	From      string `json:"from"`      // Sender address
+	SMTP      SMTP   `json:"smtp"`
+
+	PreferencesPath string `json:"preferencesPath"` // Students' notification preferences; defaults to a file in DataDir
+}
+
+// SMTP is the mail server used by the smtp transport.
+type SMTP struct {
+	Addr     string `json:"addr"` // host:port
+	Username string `json:"username"`
+	Password Secret `json:"password"`
+}
+
+// PolicyFile makes a YAML or JSON file the source of the policy configuration instead of the API.
+type PolicyFile struct {
+	Path         string   `json:"path"`
+	PollInterval Duration `json:"pollInterval"`
+}
+
+// Auth holds the coordinator credentials. At least one account or the token must be set.
+type Auth struct {
+	CoordinatorToken    Secret            `json:"coordinatorToken"`    // Fixed token for scripts
+	CoordinatorAccounts map[string]Secret `json:"coordinatorAccounts"` // Name to password, for POST /auth/login
+	SessionTTL          Duration          `json:"sessionTTL"`          // Lifetime of a login session
+	StudentTokenTTL     Duration          `json:"studentTokenTTL"`     // Lifetime of a student's /me token
+}
+
+// Secret is a credential. It is read like any string but printed masked, so a printed configuration can be
+// shared without leaking it.
+type Secret string
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Secret) MarshalText() ([]byte, error) {
+	if s == "" {
+		return []byte{}, nil
+	}
+	return []byte("********"), nil
+}
+
+// TLS enables HTTPS when both files are set.
+type TLS struct {
+	CertFile string `json:"certFile"`
+	KeyFile  string `json:"keyFile"`
+}
+
+// Enabled reports whether the server should serve HTTPS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" || t.KeyFile != ""
+}
+
+// Timeouts bound how long the server spends on a connection. Zero disables a timeout, except Shutdown.
+type Timeouts struct {
+	Read       Duration `json:"read"`       // Reading an entire request, including the body
+	ReadHeader Duration `json:"readHeader"` // Reading request headers
+	Write      Duration `json:"write"`      // Writing a response; the event stream is exempt
+	Idle       Duration `json:"idle"`       // Keeping an idle keep-alive connection open
+	Shutdown   Duration `json:"shutdown"`   // Waiting for in-flight requests when stopping
+}
+
+// Duration is a time.Duration written as a string such as "15s" in config files and printed configs.
+type Duration time.Duration
+
+// MarshalText implements encoding.TextMarshaler.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: use a value such as 15s or 2m", text)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the configuration used when nothing is set.
+func Default() Config {
+	return Config{
+		Addr:           ":8080",
+		AllowedOrigins: []string{"http://localhost:3000"}, // The React frontend's development server
+		DataDir:        filepath.Join("internal", "data"),
+		StorageBackend: BackendMemory,
+		LogLevel:       "info",
+		Timeouts: Timeouts{
+			Read:       Duration(30 * time.Second),
+			ReadHeader: Duration(10 * time.Second),
+			Write:      Duration(60 * time.Second),
+			Idle:       Duration(2 * time.Minute),
+			Shutdown:   Duration(15 * time.Second),
+		},
+		Eligibility: Eligibility{CacheSize: 20000}, // eligibility.DefaultCacheSize
+		Notify:      Notify{Transport: TransportLog, From: "placements@localhost"},
+		PolicyFile:  PolicyFile{PollInterval: Duration(2 * time.Second)},
+		Auth:        Auth{SessionTTL: Duration(8 * time.Hour), StudentTokenTTL: Duration(7 * 24 * time.Hour)},
+	}
+}
+
+// defaultPaths fills in the files that default to DataDir, once DataDir is final.
+func (c *Config) defaultPaths() {
+	for _, file := range []struct {
+		path *string
+		name string
+	}{
+		{&c.AuditLogPath, "audit.jsonl"},
+		{&c.WebhooksPath, "webhooks.jsonl"},
+		{&c.Eligibility.ChangesPath, "eligibility_changes.jsonl"},
+		{&c.Notify.File, "notifications.eml"},
+		{&c.Notify.PreferencesPath, "notification_preferences.json"},
+	} {
+		if *file.path == "" {
+			*file.path = filepath.Join(c.DataDir, file.name)
+		}
+	}
+}
+
+// setting is one configuration value that can be given as a flag or environment variable.
+type setting struct {
+	flag, env, usage string
+	set              func(c *Config, value string) error
+}
+
+func durationSetting(flagName, env, usage string, field func(c *Config) *Duration) setting {
+	return setting{flagName, env, usage, func(c *Config, v string) error { return field(c).UnmarshalText([]byte(v)) }}
+}
+
+func stringSetting(flagName, env, usage string, field func(c *Config) *string) setting {
+	return setting{flagName, env, usage, func(c *Config, v string) error { *field(c) = v; return nil }}
+}
+
+// parseAccounts reads coordinator accounts written as "name:password,name:password".
+func parseAccounts(v string) (map[string]Secret, error) {
+	accounts := map[string]Secret{}
+	for _, account := range splitList(v) {
+		name, password, found := strings.Cut(account, ":")
+		if !found || name == "" || password == "" {
+			return nil, errors.New("entries must be name:password, separated by commas")
+		}
+		accounts[name] = Secret(password)
+	}
+	return accounts, nil
+}
+
+var settings = []setting{
+	{"addr", "LISTEN_ADDR", "host:port to listen on", func(c *Config, v string) error { c.Addr = v; return nil }},
+	{"tls-cert", "TLS_CERT_FILE", "TLS certificate file; serves HTTPS together with -tls-key", func(c *Config, v string) error { c.TLS.CertFile = v; return nil }},
+	{"tls-key", "TLS_KEY_FILE", "TLS private key file", func(c *Config, v string) error { c.TLS.KeyFile = v; return nil }},
+	{"allowed-origins", "ALLOWED_ORIGINS", "comma-separated CORS origins, or * for any", func(c *Config, v string) error {
+		c.AllowedOrigins = splitList(v)
+		return nil
+	}},
+	{"data-dir", "DATA_DIR", "directory holding students.json and company.json", func(c *Config, v string) error { c.DataDir = v; return nil }},
+	{"storage", "STORAGE_BACKEND", "storage backend: " + strings.Join(Backends, ", "), func(c *Config, v string) error { c.StorageBackend = v; return nil }},
+	{"log-level", "LOG_LEVEL", "minimum log level: debug, info, warn or error", func(c *Config, v string) error { c.LogLevel = v; return nil }},
+	durationSetting("read-timeout", "READ_TIMEOUT", "maximum time to read a request (0 disables)", func(c *Config) *Duration { return &c.Timeouts.Read }),
+	durationSetting("read-header-timeout", "READ_HEADER_TIMEOUT", "maximum time to read request headers (0 disables)", func(c *Config) *Duration { return &c.Timeouts.ReadHeader }),
+	durationSetting("write-timeout", "WRITE_TIMEOUT", "maximum time to write a response (0 disables)", func(c *Config) *Duration { return &c.Timeouts.Write }),
+	durationSetting("idle-timeout", "IDLE_TIMEOUT", "maximum time to keep an idle connection (0 disables)", func(c *Config) *Duration { return &c.Timeouts.Idle }),
+	durationSetting("shutdown-timeout", "SHUTDOWN_TIMEOUT", "maximum time to wait for in-flight requests on shutdown", func(c *Config) *Duration { return &c.Timeouts.Shutdown }),
+	stringSetting("audit-log", "AUDIT_LOG_PATH", "audit log file (default <data-dir>/audit.jsonl)", func(c *Config) *string { return &c.AuditLogPath }),
+	stringSetting("webhooks-file", "WEBHOOKS_PATH", "webhook subscriptions file (default <data-dir>/webhooks.jsonl)", func(c *Config) *string { return &c.WebhooksPath }),
+	stringSetting("campus-timezone", "CAMPUS_TIMEZONE", "IANA time zone drive days are counted in (default the server's)", func(c *Config) *string { return &c.CampusTimezone }),
+	{"eligibility-cache-size", "ELIGIBILITY_CACHE_SIZE", "eligibility results to cache (0 disables)", func(c *Config, v string) error {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", v)
+		}
+		c.Eligibility.CacheSize = size
+		return nil
+	}},
+	stringSetting("eligibility-changes", "ELIGIBILITY_CHANGES_PATH", "eligibility change log file (default <data-dir>/eligibility_changes.jsonl)", func(c *Config) *string { return &c.Eligibility.ChangesPath }),
+	stringSetting("notify-transport", "NOTIFY_TRANSPORT", "notification transport: "+strings.Join(Transports, ", "), func(c *Config) *string { return &c.Notify.Transport }),
+	stringSetting("notify-file", "NOTIFY_FILE", "file the file transport appends messages to (default <data-dir>/notifications.eml)", func(c *Config) *string { return &c.Notify.File }),
+	stringSetting("notify-preferences", "NOTIFY_PREFERENCES_PATH", "file students' notification preferences are saved to (default <data-dir>/notification_preferences.json)", func(c *Config) *string { return &c.Notify.PreferencesPath }),
+	stringSetting("notify-from", "SMTP_FROM", "sender address of notifications", func(c *Config) *string { return &c.Notify.From }),
+	stringSetting("smtp-addr", "SMTP_ADDR", "host:port of the SMTP server", func(c *Config) *string { return &c.Notify.SMTP.Addr }),
+	stringSetting("smtp-username", "SMTP_USERNAME", "SMTP username", func(c *Config) *string { return &c.Notify.SMTP.Username }),
+	{"smtp-password", "SMTP_PASSWORD", "SMTP password; prefer the environment variable", func(c *Config, v string) error { c.Notify.SMTP.Password = Secret(v); return nil }},
+	stringSetting("policy-file", "POLICY_FILE", "YAML or JSON file to manage policies from instead of the API", func(c *Config) *string { return &c.PolicyFile.Path }),
+	durationSetting("policy-file-poll-interval", "POLICY_FILE_POLL_INTERVAL", "how often to check the policy file for changes", func(c *Config) *Duration { return &c.PolicyFile.PollInterval }),
+	{"coordinator-token", "COORDINATOR_TOKEN", "fixed coordinator token for scripts; prefer the environment variable", func(c *Config, v string) error { c.Auth.CoordinatorToken = Secret(v); return nil }},
+	{"coordinator-accounts", "COORDINATOR_ACCOUNTS", "coordinator logins as name:password,...; prefer the environment variable", func(c *Config, v string) error {
+		accounts, err := parseAccounts(v)
+		if err == nil {
+			c.Auth.CoordinatorAccounts = accounts
+		}
+		return err
+	}},
+	durationSetting("session-ttl", "COORDINATOR_SESSION_TTL", "lifetime of a coordinator login session", func(c *Config) *Duration { return &c.Auth.SessionTTL }),
+	durationSetting("student-token-ttl", "STUDENT_TOKEN_TTL", "lifetime of a student token for the /me endpoints", func(c *Config) *Duration { return &c.Auth.StudentTokenTTL }),
+}
+
+// Options are the command-line options that are not configuration settings.
+type Options struct {
+	ConfigFile  string // Path of the config file, if any
+	PrintConfig bool   // Print the effective configuration and exit
+}
+
+// Load builds the configuration from defaults, the config file, environment variables (read with getenv) and
+// the command-line arguments, in that order, then defaults the files kept in DataDir. It does not validate the
+// result; call Validate.
+// For -h/-help it returns flag.ErrHelp after printing usage.
+func Load(args []string, getenv func(string) string) (Config, Options, error) {
+	var opts Options
+	flags := flag.NewFlagSet("placement-api", flag.ContinueOnError)
+	flags.StringVar(&opts.ConfigFile, "config", getenv("CONFIG_FILE"), "optional YAML or JSON config file (env CONFIG_FILE)")
+	flags.BoolVar(&opts.PrintConfig, "print-config", false, "print the effective configuration as JSON and exit")
+
+	// Flag values are collected first and applied last, so they override the file and the environment.
+	type flagValue struct {
+		setting setting
+		value   string
+	}
+	var given []flagValue
+	for _, s := range settings {
+		s := s
+		flags.Func(s.flag, fmt.Sprintf("%s (env %s)", s.usage, s.env), func(v string) error {
+			given = append(given, flagValue{s, v})
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, opts, err
+	}
+	if flags.NArg() > 0 {
+		return Config{}, opts, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	config := Default()
+	if opts.ConfigFile != "" {
+		data, err := os.ReadFile(opts.ConfigFile)
+		if err != nil {
+			return Config{}, opts, fmt.Errorf("reading config file: %w", err)
+		}
+		if err := parseFile(opts.ConfigFile, data, &config); err != nil {
+			return Config{}, opts, err
+		}
+	}
+	for _, s := range settings {
+		if v := getenv(s.env); v != "" {
+			if err := s.set(&config, v); err != nil {
+				return Config{}, opts, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, f := range given {
+		if err := f.setting.set(&config, f.value); err != nil {
+			return Config{}, opts, fmt.Errorf("-%s: %w", f.setting.flag, err)
+		}
+	}
+	config.defaultPaths()
+	return config, opts, nil
+}
+
+// parseFile overlays the settings in a YAML (.yaml/.yml) or JSON (anything else) config file onto config.
+// Both formats use the JSON field names of Config, and unknown fields are rejected so typos are not ignored.
+func parseFile(path string, data []byte, config *Config) error {
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("parsing YAML config file: %w", err)
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("config file: %w", err)
+		}
+		data = converted
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks every setting and reports all problems at once.
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(field, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+
+	if _, port, err := net.SplitHostPort(c.Addr); err != nil || port == "" {
+		invalid("addr", "%q is not a host:port address such as :8080", c.Addr)
+	}
+
+	if c.TLS.Enabled() {
+		if c.TLS.CertFile == "" || c.TLS.KeyFile == "" {
+			invalid("tls", "certFile and keyFile must be set together")
+		}
+		for _, file := range []struct{ field, path string }{{"tls.certFile", c.TLS.CertFile}, {"tls.keyFile", c.TLS.KeyFile}} {
+			if file.path == "" {
+				continue
+			}
+			if _, err := os.Stat(file.path); err != nil {
+				invalid(file.field, "%v", err)
+			}
+		}
+	}
+
+	if len(c.AllowedOrigins) == 0 {
+		invalid("allowedOrigins", "at least one origin is required")
+	}
+	for i, origin := range c.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+			invalid(fmt.Sprintf("allowedOrigins[%d]", i), "%q must be * or a scheme and host such as https://placements.example.edu", origin)
+		}
+	}
+
+	if info, err := os.Stat(c.DataDir); err != nil {
+		invalid("dataDir", "%v", err)
+	} else if !info.IsDir() {
+		invalid("dataDir", "%s is not a directory", c.DataDir)
+	}
+
+	supported := false
+	for _, backend := range Backends {
+		supported = supported || c.StorageBackend == backend
+	}
+	if !supported {
+		invalid("storageBackend", "%q is not supported; use %s", c.StorageBackend, strings.Join(Backends, ", "))
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		invalid("logLevel", "%q must be debug, info, warn or error", c.LogLevel)
+	}
+
+	for _, timeout := range []struct {
+		field string
+		value Duration
+	}{{"timeouts.read", c.Timeouts.Read}, {"timeouts.readHeader", c.Timeouts.ReadHeader}, {"timeouts.write", c.Timeouts.Write}, {"timeouts.idle", c.Timeouts.Idle}} {
+		if timeout.value < 0 {
+			invalid(timeout.field, "must not be negative")
+		}
+	}
+	if c.Timeouts.Shutdown <= 0 {
+		invalid("timeouts.shutdown", "must be positive")
+	}
+
+	// Files are created on demand, but only in a directory that exists.
+	for _, file := range []struct{ field, path string }{
+		{"auditLogPath", c.AuditLogPath}, {"eligibility.changesPath", c.Eligibility.ChangesPath}, {"notify.file", c.Notify.File},
+		{"notify.preferencesPath", c.Notify.PreferencesPath},
+	} {
+		if file.path == "" {
+			invalid(file.field, "must be set")
+		} else if info, err := os.Stat(filepath.Dir(file.path)); err != nil || !info.IsDir() {
+			invalid(file.field, "directory of %s does not exist", file.path)
+		}
+	}
+
+	if c.CampusTimezone != "" {
+		if _, err := time.LoadLocation(c.CampusTimezone); err != nil {
+			invalid("campusTimezone", "%q is not a known time zone such as Asia/Kolkata", c.CampusTimezone)
+		}
+	}
+
+	if c.Eligibility.CacheSize < 0 {
+		invalid("eligibility.cacheSize", "must not be negative")
+	}
+
+	supported = false
+	for _, transport := range Transports {
+		supported = supported || c.Notify.Transport == transport
+	}
+	if !supported {
+		invalid("notify.transport", "%q is not supported; use %s", c.Notify.Transport, strings.Join(Transports, ", "))
+	}
+	if _, err := mail.ParseAddress(c.Notify.From); err != nil {
+		invalid("notify.from", "%q is not an email address", c.Notify.From)
+	}
+	if c.Notify.Transport == TransportSMTP {
+		if _, port, err := net.SplitHostPort(c.Notify.SMTP.Addr); err != nil || port == "" {
+			invalid("notify.smtp.addr", "%q is not a host:port address such as mail.example.edu:587", c.Notify.SMTP.Addr)
+		}
+	}
+
+	if c.PolicyFile.Path != "" {
+		if _, err := os.Stat(c.PolicyFile.Path); err != nil {
+			invalid("policyFile.path", "%v", err)
+		}
+		if c.PolicyFile.PollInterval <= 0 {
+			invalid("policyFile.pollInterval", "must be positive")
+		}
+	}
+
+	// The server never runs without a way for coordinators to authenticate, so it is never left open.
+	if c.Auth.CoordinatorToken == "" && len(c.Auth.CoordinatorAccounts) == 0 {
+		invalid("auth", "set coordinatorAccounts, coordinatorToken or both")
+	}
+	names := make([]string, 0, len(c.Auth.CoordinatorAccounts))
+	for name := range c.Auth.CoordinatorAccounts {
+		names = append(names, name)
+	}
+	sort.Strings(names) // Report problems in a stable order.
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, ":,") || c.Auth.CoordinatorAccounts[name] == "" {
+			invalid("auth.coordinatorAccounts", "account %q needs a name without ':' or ',' and a password", name)
+		}
+	}
+	if c.Auth.SessionTTL <= 0 {
+		invalid("auth.sessionTTL", "must be positive")
+	}
+	if c.Auth.StudentTokenTTL <= 0 {
+		invalid("auth.studentTokenTTL", "must be positive")
+	}
+	return errors.Join(errs...)
+}
+
+// Print writes the configuration as indented JSON, in the format accepted as a config file.
+func (c Config) Print(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// splitList splits a comma-separated list, dropping blanks.
+func splitList(v string) []string {
+	items := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
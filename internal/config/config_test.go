@@ -0,0 +1,272 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// env returns a getenv function backed by the given variables.
+func env(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+// writeFile writes a config file into a temporary directory and returns its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+addr: ":7000"
+logLevel: warn
+dataDir: /srv/data
+timeouts:
+  write: 90s
+`)
+	tests := []struct {
+		name     string
+		args     []string
+		vars     map[string]string
+		wantAddr string
+		wantLog  string
+		wantDir  string
+	}{
+		{"defaults", nil, nil, ":8080", "info", filepath.Join("internal", "data")},
+		{"file over defaults", []string{"-config", file}, nil, ":7000", "warn", "/srv/data"},
+		{"file from the environment", nil, map[string]string{"CONFIG_FILE": file}, ":7000", "warn", "/srv/data"},
+		{"environment over file", []string{"-config", file}, map[string]string{"LISTEN_ADDR": ":7100"}, ":7100", "warn", "/srv/data"},
+		{"flag over environment", []string{"-config", file, "-addr", ":7200"}, map[string]string{"LISTEN_ADDR": ":7100"}, ":7200", "warn", "/srv/data"},
+		{"flag over file", []string{"-config", file, "-log-level", "debug"}, nil, ":7000", "debug", "/srv/data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, _, err := Load(tt.args, env(tt.vars))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if config.Addr != tt.wantAddr || config.LogLevel != tt.wantLog || config.DataDir != tt.wantDir {
+				t.Errorf("got addr %q, logLevel %q, dataDir %q; want %q, %q, %q",
+					config.Addr, config.LogLevel, config.DataDir, tt.wantAddr, tt.wantLog, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsUnsetFileKeysAtTheirDefaults(t *testing.T) {
+	file := writeFile(t, "config.json", `{"timeouts": {"write": "90s"}}`)
+	config, _, err := Load([]string{"-config", file}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := time.Duration(config.Timeouts.Write); got != 90*time.Second {
+		t.Errorf("timeouts.write = %v, want 90s", got)
+	}
+	if got := time.Duration(config.Timeouts.Read); got != 30*time.Second {
+		t.Errorf("timeouts.read = %v, want the 30s default", got)
+	}
+}
+
+func TestLoadDefaultsFilesToDataDir(t *testing.T) {
+	config, _, err := Load([]string{"-data-dir", "/srv/data", "-audit-log", "/var/log/audit.jsonl"}, env(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.AuditLogPath != "/var/log/audit.jsonl" {
+		t.Errorf("auditLogPath = %q, want the flag value", config.AuditLogPath)
+	}
+	if want := filepath.Join("/srv/data", "eligibility_changes.jsonl"); config.Eligibility.ChangesPath != want {
+		t.Errorf("eligibility.changesPath = %q, want %q", config.Eligibility.ChangesPath, want)
+	}
+	if want := filepath.Join("/srv/data", "notifications.eml"); config.Notify.File != want {
+		t.Errorf("notify.file = %q, want %q", config.Notify.File, want)
+	}
+}
+
+func TestLoadRejectsUnknownFileKeys(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+	}{
+		{"yaml top level", "config.yaml", "adress: \":8080\"\n"},
+		{"yaml nested", "config.yml", "timeouts:\n  wirte: 5s\n"},
+		{"json top level", "config.json", `{"adress": ":8080"}`},
+		{"json nested", "config.json", `{"notify": {"smtp": {"host": "mail"}}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load([]string{"-config", writeFile(t, tt.file, tt.content)}, env(nil))
+			if err == nil || !strings.Contains(err.Error(), "unknown field") {
+				t.Errorf("Load error = %v, want an unknown field error", err)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		vars map[string]string
+		want string
+	}{
+		{"bad duration flag", []string{"-write-timeout", "soon"}, nil, "-write-timeout"},
+		{"bad duration variable", nil, map[string]string{"READ_TIMEOUT": "10"}, "READ_TIMEOUT"},
+		{"bad cache size", nil, map[string]string{"ELIGIBILITY_CACHE_SIZE": "lots"}, "ELIGIBILITY_CACHE_SIZE"},
+		{"bad accounts", nil, map[string]string{"COORDINATOR_ACCOUNTS": "alice"}, "COORDINATOR_ACCOUNTS"},
+		{"stray argument", []string{"serve"}, nil, "unexpected arguments"},
+		{"missing config file", []string{"-config", "/does/not/exist.yaml"}, nil, "reading config file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(tt.args, env(tt.vars))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load error = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadHelp(t *testing.T) {
+	_, _, err := Load([]string{"-h"}, env(nil))
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("Load(-h) error = %v, want flag.ErrHelp", err)
+	}
+}
+
+func TestDurationText(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"15s", 15 * time.Second, false},
+		{"2m30s", 150 * time.Second, false},
+		{"1h", time.Hour, false},
+		{"0", 0, false},
+		{"15", 0, true},
+		{"fast", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		var d Duration
+		err := d.UnmarshalText([]byte(tt.text))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("UnmarshalText(%q) error = %v, wantErr %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if err == nil && time.Duration(d) != tt.want {
+			t.Errorf("UnmarshalText(%q) = %v, want %v", tt.text, time.Duration(d), tt.want)
+		}
+	}
+
+	text, err := Duration(90 * time.Second).MarshalText()
+	if err != nil || string(text) != "1m30s" {
+		t.Errorf("MarshalText = %q, %v; want 1m30s", text, err)
+	}
+}
+
+func TestDurationInConfigFiles(t *testing.T) {
+	for _, tt := range []struct{ file, content string }{
+		{"config.yaml", "timeouts:\n  idle: 45s\n"},
+		{"config.json", `{"timeouts": {"idle": "45s"}}`},
+	} {
+		config, _, err := Load([]string{"-config", writeFile(t, tt.file, tt.content)}, env(nil))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.file, err)
+		}
+		if got := time.Duration(config.Timeouts.Idle); got != 45*time.Second {
+			t.Errorf("%s: timeouts.idle = %v, want 45s", tt.file, got)
+		}
+	}
+	if _, _, err := Load([]string{"-config", writeFile(t, "config.json", `{"timeouts": {"idle": 45}}`)}, env(nil)); err == nil {
+		t.Error("a bare number was accepted as a duration")
+	}
+}
+
+// valid returns a configuration that passes Validate, using a temporary data directory.
+func valid(t *testing.T) Config {
+	t.Helper()
+	config := Default()
+	config.DataDir = t.TempDir()
+	config.Auth.CoordinatorToken = "token"
+	config.defaultPaths()
+	return config
+}
+
+func TestValidate(t *testing.T) {
+	if err := valid(t).Validate(); err != nil {
+		t.Fatalf("valid configuration rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		want   []string
+	}{
+		{"bad addr", func(c *Config) { c.Addr = "8080" }, []string{"addr:"}},
+		{"half TLS", func(c *Config) { c.TLS.CertFile = "cert.pem" }, []string{"tls:", "tls.certFile:"}},
+		{"bad origin", func(c *Config) { c.AllowedOrigins = []string{"localhost:3000"} }, []string{"allowedOrigins[0]:"}},
+		{"missing data dir", func(c *Config) { c.DataDir = "/does/not/exist" }, []string{"dataDir:"}},
+		{"unknown backend", func(c *Config) { c.StorageBackend = "postgres" }, []string{"storageBackend:"}},
+		{"bad log level", func(c *Config) { c.LogLevel = "loud" }, []string{"logLevel:"}},
+		{"negative timeout", func(c *Config) { c.Timeouts.Read = Duration(-time.Second) }, []string{"timeouts.read:"}},
+		{"zero shutdown", func(c *Config) { c.Timeouts.Shutdown = 0 }, []string{"timeouts.shutdown:"}},
+		{"audit log in missing directory", func(c *Config) { c.AuditLogPath = "/does/not/exist/audit.jsonl" }, []string{"auditLogPath:"}},
+		{"unknown time zone", func(c *Config) { c.CampusTimezone = "Mars/Olympus" }, []string{"campusTimezone:"}},
+		{"negative cache size", func(c *Config) { c.Eligibility.CacheSize = -1 }, []string{"eligibility.cacheSize:"}},
+		{"unknown transport", func(c *Config) { c.Notify.Transport = "pigeon" }, []string{"notify.transport:"}},
+		{"bad sender", func(c *Config) { c.Notify.From = "placements" }, []string{"notify.from:"}},
+		{"smtp without server", func(c *Config) { c.Notify.Transport = TransportSMTP }, []string{"notify.smtp.addr:"}},
+		{"missing policy file", func(c *Config) { c.PolicyFile.Path = "/does/not/exist.yaml" }, []string{"policyFile.path:"}},
+		{"no credentials", func(c *Config) { c.Auth.CoordinatorToken = "" }, []string{"auth:"}},
+		{"bad account", func(c *Config) { c.Auth.CoordinatorAccounts = map[string]Secret{"a:b": "pw", "bob": ""} }, []string{`"a:b"`, `"bob"`}},
+		{"zero session ttl", func(c *Config) { c.Auth.SessionTTL = 0 }, []string{"auth.sessionTTL:"}},
+		{"negative student token ttl", func(c *Config) { c.Auth.StudentTokenTTL = Duration(-time.Hour) }, []string{"auth.studentTokenTTL:"}},
+		{"several problems", func(c *Config) { c.Addr = ""; c.LogLevel = "" }, []string{"addr:", "logLevel:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := valid(t)
+			tt.change(&config)
+			err := config.Validate()
+			if err == nil {
+				t.Fatal("Validate accepted the configuration")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintMasksSecrets(t *testing.T) {
+	config := valid(t)
+	config.Auth.CoordinatorToken = "tok-123"
+	config.Auth.CoordinatorAccounts = map[string]Secret{"alice": "s3cret"}
+	config.Notify.SMTP.Password = "mailpw"
+
+	var out bytes.Buffer
+	if err := config.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"tok-123", "s3cret", "mailpw"} {
+		if strings.Contains(out.String(), secret) {
+			t.Errorf("printed configuration contains %q", secret)
+		}
+	}
+	if !strings.Contains(out.String(), `"alice": "********"`) {
+		t.Errorf("printed configuration does not show the masked account:\n%s", out.String())
+	}
+}
@@ -0,0 +1,158 @@
+package eligibility
+
+import (
+	"container/list"
+	"slices"
+	"sync"
+
+	"go-placement-policy/internal/metrics"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// DefaultCacheSize is the number of student–company results kept by default, a few megabytes of memory.
+const DefaultCacheSize = 20000
+
+// CacheStats describes the eligibility cache, as reported by GET /eligibility/cache.
+type CacheStats struct {
+	Capacity      int     `json:"capacity"` // 0 means caching is disabled.
+	Size          int     `json:"size"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRatio      float64 `json:"hitRatio"`
+	Evictions     int64   `json:"evictions"`     // Entries dropped to stay within capacity.
+	Invalidations int64   `json:"invalidations"` // Entries dropped because their student, company or the policies changed.
+}
+
+// cacheKey identifies a student–company pair.
+type cacheKey struct {
+	studentID int
+	companyID string
+}
+
+// versions are what a cached result was computed from. A result is only served if all of them are still current.
+type versions struct {
+	student, company, policy, placementStats int64
+}
+
+type cacheEntry struct {
+	key      cacheKey
+	versions versions
+	result   models.EligibilityResult
+}
+
+// resultCache is a least-recently-used cache of eligibility results.
+type resultCache struct {
+	mutex    sync.Mutex
+	capacity int
+	order    *list.List // Front is most recently used; elements hold *cacheEntry.
+	entries  map[cacheKey]*list.Element
+	stats    CacheStats
+}
+
+var cache = &resultCache{capacity: DefaultCacheSize, order: list.New(), entries: map[cacheKey]*list.Element{}}
+
+func init() {
+	// Versions already keep stale results from being served; dropping them early frees their memory.
+	storage.OnStudentChange(func(studentID int) {
+		cache.invalidate(func(k cacheKey) bool { return k.studentID == studentID })
+	})
+	storage.OnCompanyChange(func(companyID string) {
+		cache.invalidate(func(k cacheKey) bool { return k.companyID == companyID })
+	})
+	storage.OnPolicyChange(func() {
+		cache.invalidate(func(cacheKey) bool { return true })
+	})
+
+	metrics.NewCounterFunc("placement_eligibility_cache_hits_total", "Eligibility checks answered from the result cache.",
+		func() float64 { return float64(Stats().Hits) })
+	metrics.NewCounterFunc("placement_eligibility_cache_misses_total", "Eligibility checks that had to be computed.",
+		func() float64 { return float64(Stats().Misses) })
+	metrics.NewGaugeFunc("placement_eligibility_cache_entries", "Results held in the eligibility cache.",
+		func() float64 { return float64(Stats().Size) })
+}
+
+// SetCacheSize changes how many results the cache keeps, evicting the least recently used ones if it shrinks.
+// A size of 0 disables caching.
+func SetCacheSize(size int) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.capacity = max(size, 0)
+	cache.evict()
+}
+
+// Stats returns the cache's counters.
+func Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	s := cache.stats
+	s.Capacity = cache.capacity
+	s.Size = cache.order.Len()
+	if lookups := s.Hits + s.Misses; lookups > 0 {
+		s.HitRatio = float64(s.Hits) / float64(lookups)
+	}
+	return s
+}
+
+// get returns a copy of the cached result for key if it was computed from v.
+func (c *resultCache) get(key cacheKey, v versions) (models.EligibilityResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.capacity == 0 {
+		return models.EligibilityResult{}, false
+	}
+	if e, ok := c.entries[key]; ok && e.Value.(*cacheEntry).versions == v {
+		c.order.MoveToFront(e)
+		c.stats.Hits++
+		return copyResult(e.Value.(*cacheEntry).result), true
+	}
+	c.stats.Misses++
+	return models.EligibilityResult{}, false
+}
+
+// put stores result for key, replacing any result computed from other versions.
+func (c *resultCache) put(key cacheKey, v versions, result models.EligibilityResult) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.capacity == 0 {
+		return
+	}
+	entry := &cacheEntry{key: key, versions: v, result: copyResult(result)}
+	if e, ok := c.entries[key]; ok {
+		e.Value = entry
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	c.evict()
+}
+
+// invalidate drops the entries whose key matches. Callers must not hold c.mutex.
+func (c *resultCache) invalidate(match func(cacheKey) bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for key, e := range c.entries {
+		if match(key) {
+			c.order.Remove(e)
+			delete(c.entries, key)
+			c.stats.Invalidations++
+		}
+	}
+}
+
+// evict drops least recently used entries until the cache is within capacity. c.mutex must be held.
+func (c *resultCache) evict() {
+	for c.order.Len() > c.capacity {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.entries, e.Value.(*cacheEntry).key)
+		c.stats.Evictions++
+	}
+}
+
+// copyResult copies the slices of a result so callers cannot modify a cached one.
+func copyResult(result models.EligibilityResult) models.EligibilityResult {
+	result.Reasons = slices.Clone(result.Reasons)
+	result.Decisions = slices.Clone(result.Decisions)
+	return result
+}
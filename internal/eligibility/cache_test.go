@@ -0,0 +1,180 @@
+package eligibility
+
+import (
+	"container/list"
+	"context"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+func newCache(capacity int) *resultCache {
+	return &resultCache{capacity: capacity, order: list.New(), entries: map[cacheKey]*list.Element{}}
+}
+
+// useCache replaces the package cache for the duration of a test.
+func useCache(t *testing.T, c *resultCache) {
+	t.Helper()
+	saved := cache
+	cache = c
+	t.Cleanup(func() { cache = saved })
+}
+
+func TestCacheServesOnlyMatchingVersions(t *testing.T) {
+	c := newCache(10)
+	key := cacheKey{1, "C1"}
+	stored := versions{student: 3, company: 2, policy: 5, placementStats: 7}
+	c.put(key, stored, models.EligibilityResult{IsEligible: true})
+
+	tests := []struct {
+		name string
+		v    versions
+		hit  bool
+	}{
+		{"same versions", stored, true},
+		{"student changed", versions{4, 2, 5, 7}, false},
+		{"company changed", versions{3, 3, 5, 7}, false},
+		{"policy changed", versions{3, 2, 6, 7}, false},
+		{"placement stats changed", versions{3, 2, 5, 8}, false},
+	}
+	for _, tt := range tests {
+		result, hit := c.get(key, tt.v)
+		if hit != tt.hit {
+			t.Errorf("%s: hit = %v, want %v", tt.name, hit, tt.hit)
+		}
+		if hit && !result.IsEligible {
+			t.Errorf("%s: cached result was not returned", tt.name)
+		}
+	}
+	if c.stats.Hits != 1 || c.stats.Misses != 4 {
+		t.Errorf("hits %d, misses %d; want 1 and 4", c.stats.Hits, c.stats.Misses)
+	}
+
+	// A result for newer versions replaces the old one instead of adding a second entry.
+	c.put(key, versions{4, 2, 5, 7}, models.EligibilityResult{})
+	if c.order.Len() != 1 {
+		t.Errorf("cache holds %d entries for one pair", c.order.Len())
+	}
+	if _, hit := c.get(key, stored); hit {
+		t.Error("result for the replaced versions is still served")
+	}
+}
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newCache(2)
+	v := versions{1, 1, 1, 1}
+	a, b, d := cacheKey{1, "A"}, cacheKey{2, "B"}, cacheKey{3, "D"}
+	c.put(a, v, models.EligibilityResult{})
+	c.put(b, v, models.EligibilityResult{})
+	c.get(a, v) // a is now more recently used than b.
+	c.put(d, v, models.EligibilityResult{})
+
+	if _, hit := c.get(b, v); hit {
+		t.Error("least recently used entry was kept")
+	}
+	for _, key := range []cacheKey{a, d} {
+		if _, hit := c.get(key, v); !hit {
+			t.Errorf("entry %v was evicted", key)
+		}
+	}
+	if c.stats.Evictions != 1 || c.order.Len() != 2 {
+		t.Errorf("evictions %d, size %d; want 1 and 2", c.stats.Evictions, c.order.Len())
+	}
+}
+
+func TestSetCacheSize(t *testing.T) {
+	c := newCache(3)
+	useCache(t, c)
+	v := versions{1, 1, 1, 1}
+	for i := 1; i <= 3; i++ {
+		c.put(cacheKey{i, "A"}, v, models.EligibilityResult{})
+	}
+
+	SetCacheSize(1)
+	if s := Stats(); s.Capacity != 1 || s.Size != 1 || s.Evictions != 2 {
+		t.Errorf("after shrinking: %+v", s)
+	}
+	if _, hit := c.get(cacheKey{3, "A"}, v); !hit {
+		t.Error("shrinking dropped the most recently used entry")
+	}
+
+	SetCacheSize(0)
+	c.put(cacheKey{4, "A"}, v, models.EligibilityResult{})
+	if _, hit := c.get(cacheKey{4, "A"}, v); hit {
+		t.Error("disabled cache served a result")
+	}
+	if s := Stats(); s.Capacity != 0 || s.Size != 0 {
+		t.Errorf("disabled cache: %+v", s)
+	}
+
+	SetCacheSize(-5)
+	if s := Stats(); s.Capacity != 0 {
+		t.Errorf("negative size gave capacity %d, want 0", s.Capacity)
+	}
+}
+
+func TestCachedResultsAreCopies(t *testing.T) {
+	c := newCache(1)
+	key, v := cacheKey{1, "A"}, versions{1, 1, 1, 1}
+	reasons := []string{"original"}
+	c.put(key, v, models.EligibilityResult{Reasons: reasons})
+	reasons[0] = "changed by caller"
+
+	result, _ := c.get(key, v)
+	result.Reasons[0] = "changed by reader"
+	if again, _ := c.get(key, v); again.Reasons[0] != "original" {
+		t.Errorf("cached reasons = %q, want them unaffected by callers", again.Reasons)
+	}
+}
+
+func TestPerformEligibilityCheckCaching(t *testing.T) {
+	c := newCache(10)
+	useCache(t, c)
+	ctx := context.Background()
+	student := models.Student{ID: 1, FullName: "Asha", CGPA: 9, Version: 1}
+	company := models.Company{ID: "C1", Name: "Acme", OfferedSalary: 500000, Version: 1}
+
+	PerformEligibilityCheck(ctx, student, company)
+	PerformEligibilityCheck(ctx, student, company)
+	if s := Stats(); s.Hits != 1 || s.Misses != 1 || s.Size != 1 {
+		t.Fatalf("after two checks of a stored pair: %+v", s)
+	}
+
+	// Records that are not stored, such as what-if students, have version 0 and never reach the cache.
+	PerformEligibilityCheck(ctx, models.Student{ID: 2, CGPA: 9}, company)
+	PerformEligibilityCheck(ctx, student, models.Company{ID: "C2"})
+	if s := Stats(); s.Hits != 1 || s.Misses != 1 || s.Size != 1 {
+		t.Errorf("unstored records touched the cache: %+v", s)
+	}
+
+	// A policy change drops every entry through the storage hook.
+	if _, _, err := storage.UpdatePolicy(0, func(config models.PolicyConfig) (models.PolicyConfig, error) { return config, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if s := Stats(); s.Size != 0 || s.Invalidations != 1 {
+		t.Errorf("after a policy change: %+v", s)
+	}
+}
+
+func TestStudentChangesInvalidateTheirPairs(t *testing.T) {
+	c := newCache(10)
+	useCache(t, c)
+	student, err := storage.AddStudent(models.Student{FullName: "Cache Student", RollNumber: "CACHE-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other := student.ID + 1000
+	v := versions{1, 1, 1, 1}
+	c.put(cacheKey{student.ID, "A"}, v, models.EligibilityResult{})
+	c.put(cacheKey{student.ID, "B"}, v, models.EligibilityResult{})
+	c.put(cacheKey{other, "A"}, v, models.EligibilityResult{})
+
+	if _, ok := storage.UpdateStudent(student.ID, func(s *models.Student) { s.CGPA = 8 }); !ok {
+		t.Fatal("UpdateStudent failed")
+	}
+	if _, hit := c.get(cacheKey{other, "A"}, v); !hit || c.order.Len() != 1 || c.stats.Invalidations != 2 {
+		t.Errorf("updating student %d left %d entries and counted %d invalidations, want only the other student's entry",
+			student.ID, c.order.Len(), c.stats.Invalidations)
+	}
+}
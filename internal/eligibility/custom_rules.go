@@ -0,0 +1,43 @@
+package eligibility
+
+import (
+	"fmt"
+	"log/slog"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/rules"
+)
+
+// applyCustomRules evaluates the enabled custom rules in order and applies the effect of each one whose condition holds.
+// Because rules run after the built-in policies and in sequence, a later rule sees (and can override) earlier decisions.
+func applyCustomRules(result *models.EligibilityResult, student models.Student, company models.Company, config models.PolicyConfig) {
+	env := rules.Env{Student: student, Company: company, OfferCategory: OfferCategory(student, config)}
+	for _, rule := range config.CustomRules {
+		if !rule.Enabled {
+			continue
+		}
+		program, err := rules.CompileCached(rule.Condition)
+		if err != nil {
+			// Rules are validated when saved, so this only happens if validation was bypassed.
+			slog.Warn("skipping custom rule with invalid condition", "rule", rule.Name, "error", err)
+			continue
+		}
+		if !program.Eval(env) {
+			continue
+		}
+
+		detail := rule.Condition
+		if rule.Message != "" {
+			detail = rule.Message
+		}
+		policy := models.CustomRulePolicyPrefix + rule.Name
+		switch rule.Effect {
+		case models.RuleEffectBlock:
+			block(result, policy, rule.Effect, fmt.Sprintf("Blocked by custom rule %q: %s", rule.Name, detail))
+		case models.RuleEffectAllow:
+			allow(result, policy, rule.Effect, fmt.Sprintf("Allowed by custom rule %q: %s", rule.Name, detail))
+		case models.RuleEffectOverride:
+			override(result, policy, rule.Effect, fmt.Sprintf("Allowed by custom rule %q (override): %s", rule.Name, detail))
+		}
+	}
+}
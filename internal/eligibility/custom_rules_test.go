@@ -0,0 +1,90 @@
+package eligibility
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// usePolicy makes config the active policy configuration for the duration of a test.
+func usePolicy(t *testing.T, config models.PolicyConfig) {
+	t.Helper()
+	previous, _, err := storage.ConfigurePolicy(config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.ConfigurePolicy(previous, 0) })
+}
+
+func TestCustomRulesChangeVerdicts(t *testing.T) {
+	placed := models.Student{ID: 1, FullName: "Asha", IsPlaced: true, CurrentSalary: 600000, CGPA: 6.5, DreamOfferAmount: 900000}
+	unplaced := models.Student{ID: 2, FullName: "Bina", CGPA: 9.1}
+	company := models.Company{ID: "C1", Name: "Acme", OfferedSalary: 800000}
+
+	rule := func(name, condition, effect string) models.CustomRule {
+		return models.CustomRule{Name: name, Enabled: true, Condition: condition, Effect: effect}
+	}
+	tests := []struct {
+		name         string
+		dreamOffer   bool
+		rules        []models.CustomRule
+		student      models.Student
+		wantEligible bool
+		wantReasons  []string
+		wantOutcomes []string
+	}{
+		{"block when the condition holds", false,
+			[]models.CustomRule{rule("low-cgpa", "student.cgpa < 7", models.RuleEffectBlock)}, placed,
+			false, []string{`Blocked by custom rule "low-cgpa": student.cgpa < 7`}, []string{models.DecisionBlocked}},
+		{"block does not apply when the condition fails", false,
+			[]models.CustomRule{rule("low-cgpa", "student.cgpa < 7", models.RuleEffectBlock)}, unplaced,
+			true, []string{"Student is unplaced. No active policies currently block this application."}, []string{}},
+		{"allow adds a reason without changing eligibility", false,
+			[]models.CustomRule{{Name: "toppers", Enabled: true, Condition: "student.cgpa >= 9", Effect: models.RuleEffectAllow, Message: "Top of the class."}}, unplaced,
+			true, []string{`Allowed by custom rule "toppers": Top of the class.`}, []string{models.DecisionAllowed}},
+		{"allow keeps an earlier block", true,
+			[]models.CustomRule{rule("any", "true", models.RuleEffectAllow)}, placed,
+			false, nil, []string{models.DecisionBlocked, models.DecisionAllowed}},
+		{"override lifts a built-in block", true,
+			[]models.CustomRule{rule("near-dream", "company.offeredSalary >= student.dreamOffer * 0.8", models.RuleEffectOverride)}, placed,
+			true, []string{`Allowed by custom rule "near-dream" (override): company.offeredSalary >= student.dreamOffer * 0.8`},
+			[]string{models.DecisionOverridden, models.DecisionAllowed}},
+		{"a later rule sees earlier decisions", false,
+			[]models.CustomRule{rule("first", "true", models.RuleEffectBlock), rule("second", "student.isPlaced", models.RuleEffectOverride)}, placed,
+			true, []string{`Allowed by custom rule "second" (override): student.isPlaced`},
+			[]string{models.DecisionOverridden, models.DecisionAllowed}},
+		{"disabled rules are skipped", false,
+			[]models.CustomRule{{Name: "off", Condition: "true", Effect: models.RuleEffectBlock}}, placed,
+			true, []string{"No active policies specifically allow or block this application; student meets general eligibility."}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config models.PolicyConfig
+			config.DreamOffer.Enabled = tt.dreamOffer
+			config.CustomRules = tt.rules
+			usePolicy(t, config)
+
+			result := PerformEligibilityCheck(context.Background(), tt.student, company)
+			if result.IsEligible != tt.wantEligible {
+				t.Errorf("eligible = %v, want %v (reasons %q)", result.IsEligible, tt.wantEligible, result.Reasons)
+			}
+			if tt.wantReasons != nil && !reflect.DeepEqual(result.Reasons, tt.wantReasons) {
+				t.Errorf("reasons = %q, want %q", result.Reasons, tt.wantReasons)
+			}
+			outcomes := []string{}
+			for _, d := range result.Decisions {
+				outcomes = append(outcomes, d.Outcome)
+				if strings.HasPrefix(d.Policy, models.CustomRulePolicyPrefix) && d.Code == "" {
+					t.Errorf("custom rule decision %+v has no code", d)
+				}
+			}
+			if !reflect.DeepEqual(outcomes, tt.wantOutcomes) {
+				t.Errorf("decision outcomes = %v, want %v", outcomes, tt.wantOutcomes)
+			}
+		})
+	}
+}
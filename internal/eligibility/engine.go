@@ -1,16 +1,88 @@
 package eligibility
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"strings"
 
+	"go-placement-policy/internal/metrics"
 	"go-placement-policy/internal/models"
 	"go-placement-policy/internal/storage"
 )
 
+// OfferCategory classifies a placed student's current offer into the L1/L2/L3 tiers defined by the Offer Category Policy.
+// Unplaced students have no tier and an empty string is returned.
+func OfferCategory(student models.Student, config models.PolicyConfig) string {
+	if !student.IsPlaced {
+		return ""
+	}
+	if student.CurrentSalary >= config.OfferCategory.L1ThresholdAmount {
+		return "L1"
+	} else if student.CurrentSalary >= config.OfferCategory.L2ThresholdAmount {
+		return "L2"
+	}
+	return "L3" // Student with offer below L2 threshold or no offer (if IsPlaced is true without salary, though unlikely)
+}
+
+// IsDreamCompany reports whether company is the student's declared dream company. Names match ignoring case and
+// surrounding spaces, so the Dream Company Policy and recommendations agree on what counts as a match.
+func IsDreamCompany(student models.Student, company models.Company) bool {
+	dream := strings.TrimSpace(student.DreamCompanyName)
+	return dream != "" && strings.EqualFold(strings.TrimSpace(company.Name), dream)
+}
+
 // PerformEligibilityCheck evaluates a student's eligibility for a specific company based on active placement policies.
 // It initializes an EligibilityResult and then sequentially applies various policy checks.
 // The order of policy application can matter, especially for overriding policies like DreamCompany.
-func PerformEligibilityCheck(student models.Student, company models.Company) models.EligibilityResult {
+// Results for stored students and companies are cached until the student, company, policies or placement
+// statistics change. Every decision is logged at debug level with ctx, so it carries the request ID if there is one.
+func PerformEligibilityCheck(ctx context.Context, student models.Student, company models.Company) models.EligibilityResult {
+	storage.PolicyConfigMutex.RLock()
+	config := storage.ActivePolicyConfig
+	storage.PolicyConfigMutex.RUnlock()
+
+	var result models.EligibilityResult
+	cached := false
+	if student.Version == 0 || company.Version == 0 {
+		result = CheckWithPolicy(student, company, config) // Not a stored record, e.g. a what-if student.
+	} else {
+		// The statistics version is read before evaluating, so a result racing a placement is stored under the old
+		// version and never served for the new counts.
+		key := cacheKey{student.ID, company.ID}
+		v := versions{student.Version, company.Version, config.Version, storage.PlacementStatsVersion()}
+		if result, cached = cache.get(key, v); !cached {
+			result = CheckWithPolicy(student, company, config)
+			cache.put(key, v, result)
+		}
+	}
+
+	metrics.ObserveEligibility(result)
+	slog.DebugContext(ctx, "eligibility decision",
+		slog.Int("studentId", student.ID),
+		slog.String("companyId", company.ID),
+		slog.Bool("eligible", result.IsEligible),
+		slog.Any("codes", decisionCodes(result)),
+		slog.Int64("policyVersion", config.Version),
+		slog.Bool("cached", cached))
+	return result
+}
+
+// decisionCodes lists the codes of the decisions that determined a result: the blocking ones if the student is
+// ineligible, otherwise all of them.
+func decisionCodes(result models.EligibilityResult) []string {
+	codes := []string{}
+	for _, d := range result.Decisions {
+		if result.IsEligible || d.Outcome == models.DecisionBlocked {
+			codes = append(codes, d.Code)
+		}
+	}
+	return codes
+}
+
+// CheckWithPolicy is PerformEligibilityCheck against the given policy configuration instead of the active one,
+// e.g. to compare verdicts before and after a policy change.
+func CheckWithPolicy(student models.Student, company models.Company, config models.PolicyConfig) models.EligibilityResult {
 	result := models.EligibilityResult{
 		StudentID:   student.ID,
 		StudentName: student.FullName,
@@ -18,12 +90,9 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 		CompanyName: company.Name,
 		IsEligible:  true, // Assume eligible until a policy blocks
 		Reasons:     []string{},
+		Decisions:   []models.PolicyDecision{},
 	}
 
-	storage.PolicyConfigMutex.RLock()
-	config := storage.ActivePolicyConfig
-	storage.PolicyConfigMutex.RUnlock()
-
 	// General approach: Policies primarily target 'placed' students seeking additional opportunities.
 	// Unplaced students are generally eligible unless a specific policy (like a global CGPA minimum for all offers)
 	// explicitly blocks them. Currently, CGPA checks are tied to high-salary positions.
@@ -31,33 +100,22 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 		// Maximum Companies Policy: Limits how many companies a placed student can apply to.
 		if config.MaximumCompanies.Enabled {
 			if config.MaximumCompanies.MaxN == 0 { // Special case: MaxN = 0 means no more applications if already placed.
-				result.IsEligible = false
-				result.Reasons = append(result.Reasons, "Blocked by Maximum Companies Policy: Already placed and 0 additional applications allowed.")
+				block(&result, models.PolicyMaximumCompanies, "no_additional_applications", "Blocked by Maximum Companies Policy: Already placed and 0 additional applications allowed.")
 			} else if student.NumCompaniesApplied >= config.MaximumCompanies.MaxN {
-				result.IsEligible = false
-				result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by Maximum Companies Policy: Already applied to %d companies, max allowed is %d.", student.NumCompaniesApplied, config.MaximumCompanies.MaxN))
+				block(&result, models.PolicyMaximumCompanies, "application_limit_reached", fmt.Sprintf("Blocked by Maximum Companies Policy: Already applied to %d companies, max allowed is %d.", student.NumCompaniesApplied, config.MaximumCompanies.MaxN))
 			}
 		}
 
 		// Offer Category Policy: Restricts applications based on the student's current offer category (L1, L2, L3).
 		if config.OfferCategory.Enabled {
-			var category string
-			if student.CurrentSalary >= config.OfferCategory.L1ThresholdAmount {
-				category = "L1"
-			} else if student.CurrentSalary >= config.OfferCategory.L2ThresholdAmount {
-				category = "L2"
-			} else {
-				category = "L3" // Student with offer below L2 threshold or no offer (if IsPlaced is true without salary, though unlikely)
-			}
+			category := OfferCategory(student, config)
 
 			if category == "L1" { // L1 placed students typically cannot apply further.
-				result.IsEligible = false
-				result.Reasons = append(result.Reasons, "Blocked by Offer Category Policy: L1 placed students cannot apply to any other companies.")
+				block(&result, models.PolicyOfferCategory, "l1_placed", "Blocked by Offer Category Policy: L1 placed students cannot apply to any other companies.")
 			} else if category == "L2" { // L2 placed students need a significant hike to apply for other companies.
 				requiredHikeAmount := student.CurrentSalary * (config.OfferCategory.RequiredHikePercentage / 100.0)
 				if company.OfferedSalary < (student.CurrentSalary + requiredHikeAmount) {
-					result.IsEligible = false
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by Offer Category Policy (L2): Company salary (%.2f) does not meet required hike (%.2f%% over current salary %.2f).", company.OfferedSalary, config.OfferCategory.RequiredHikePercentage, student.CurrentSalary))
+					block(&result, models.PolicyOfferCategory, "l2_insufficient_hike", fmt.Sprintf("Blocked by Offer Category Policy (L2): Company salary (%.2f) does not meet required hike (%.2f%% over current salary %.2f).", company.OfferedSalary, config.OfferCategory.RequiredHikePercentage, student.CurrentSalary))
 				}
 			}
 		}
@@ -69,27 +127,24 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 				// Only block if they were eligible before this check.
 				// If already ineligible, this policy doesn't make them more ineligible, but the reason isn't added unless they were eligible.
 				if result.IsEligible { 
-					result.IsEligible = false
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by Dream Offer Policy: Company salary (%.2f) is less than student's dream offer (%.2f).", company.OfferedSalary, student.DreamOfferAmount))
+					block(&result, models.PolicyDreamOffer, "below_dream_offer", fmt.Sprintf("Blocked by Dream Offer Policy: Company salary (%.2f) is less than student's dream offer (%.2f).", company.OfferedSalary, student.DreamOfferAmount))
 				}
 			} else {
 				// If the offer meets/exceeds the dream amount, it can be an allowing reason, or support existing eligibility.
-				result.Reasons = append(result.Reasons, fmt.Sprintf("Allowed by Dream Offer Policy: Company salary (%.2f) meets or exceeds student's dream offer (%.2f).", company.OfferedSalary, student.DreamOfferAmount))
+				allow(&result, models.PolicyDreamOffer, "meets_dream_offer", fmt.Sprintf("Allowed by Dream Offer Policy: Company salary (%.2f) meets or exceeds student's dream offer (%.2f).", company.OfferedSalary, student.DreamOfferAmount))
 			}
 		}
 
 		// Dream Company Policy: Allows a student to apply to their declared dream company, potentially overriding other blocking policies.
 		if config.DreamCompany.Enabled {
 			// Business Rule: Placed students can apply to their dream company even if other policies would block them.
-			if company.Name == student.DreamCompanyName {
+			if IsDreamCompany(student, company) {
 				if !result.IsEligible { // If previously blocked by another policy, this dream company policy overrides.
-					result.IsEligible = true
-					result.Reasons = []string{} // Clear previous blocking reasons as this is an override.
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Allowed by Dream Company Policy: %s is student's declared dream company.", company.Name))
+					override(&result, models.PolicyDreamCompany, "dream_company_override", fmt.Sprintf("Allowed by Dream Company Policy: %s is student's declared dream company.", company.Name))
 				} else {
 					// If already eligible, add this as a supporting reason.
 					// For simplicity, we'll add it. Deduplication of reasons can be handled later if necessary.
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Allowed by Dream Company Policy: %s is student's declared dream company (already eligible).", company.Name))
+					allow(&result, models.PolicyDreamCompany, "dream_company", fmt.Sprintf("Allowed by Dream Company Policy: %s is student's declared dream company (already eligible).", company.Name))
 				}
 			}
 		}
@@ -101,10 +156,9 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 					// This can make a student ineligible, even if Dream Company policy made them eligible.
 					// The order of policies matters. If CGPA is a hard block regardless of dream status, this logic is correct.
 					// If Dream Company should override CGPA too, this block might need to be conditional on !isDreamCompanyApplication.
-					result.IsEligible = false 
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by CGPA Threshold Policy: CGPA (%.2f) is below minimum (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
+					block(&result, models.PolicyCGPAThreshold, "cgpa_below_minimum", fmt.Sprintf("Blocked by CGPA Threshold Policy: CGPA (%.2f) is below minimum (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
 				} else {
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Allowed by CGPA Threshold Policy: CGPA (%.2f) meets requirement (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
+					allow(&result, models.PolicyCGPAThreshold, "cgpa_meets_minimum", fmt.Sprintf("Allowed by CGPA Threshold Policy: CGPA (%.2f) meets requirement (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
 				}
 			}
 		}
@@ -125,25 +179,29 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 
 			// Business Rule: Placed students cannot apply until overall campus placement meets target percentage.
 			if currentPlacementPercentage < config.PlacementPercentage.TargetPercentage {
-				result.IsEligible = false
-				result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by Placement Percentage Policy: Current overall placement (%.2f%%) is below target (%.2f%%).", currentPlacementPercentage, config.PlacementPercentage.TargetPercentage))
+				block(&result, models.PolicyPlacementPercentage, "below_target", fmt.Sprintf("Blocked by Placement Percentage Policy: Current overall placement (%.2f%%) is below target (%.2f%%).", currentPlacementPercentage, config.PlacementPercentage.TargetPercentage))
 			} else {
-				result.Reasons = append(result.Reasons, fmt.Sprintf("Allowed by Placement Percentage Policy: Current overall placement (%.2f%%) meets or exceeds target (%.2f%%).", currentPlacementPercentage, config.PlacementPercentage.TargetPercentage))
+				allow(&result, models.PolicyPlacementPercentage, "meets_target", fmt.Sprintf("Allowed by Placement Percentage Policy: Current overall placement (%.2f%%) meets or exceeds target (%.2f%%).", currentPlacementPercentage, config.PlacementPercentage.TargetPercentage))
 			}
 		}
 
+		// Custom rules run after the built-in policies so they can refine or override their decisions.
+		applyCustomRules(&result, student, company, config)
+
 	} else { // Logic for unplaced students
 		// Unplaced students are generally less restricted. Only certain policies like CGPA for high-value offers apply.
 		if config.CGPAThreshold.Enabled {
 			if company.OfferedSalary >= config.CGPAThreshold.HighSalaryThreshold {
 				if student.CGPA < config.CGPAThreshold.MinimumCGPA {
-					result.IsEligible = false
-					result.Reasons = append(result.Reasons, fmt.Sprintf("Blocked by CGPA Threshold Policy: CGPA (%.2f) is below minimum (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
+					block(&result, models.PolicyCGPAThreshold, "cgpa_below_minimum", fmt.Sprintf("Blocked by CGPA Threshold Policy: CGPA (%.2f) is below minimum (%.2f) for high-paying offer (%.2f).", student.CGPA, config.CGPAThreshold.MinimumCGPA, company.OfferedSalary))
 				}
 			}
 		}
 
-		if result.IsEligible {
+		// Custom rules run after the built-in policies so they can refine or override their decisions.
+		applyCustomRules(&result, student, company, config)
+
+		if result.IsEligible && len(result.Reasons) == 0 {
 			result.Reasons = append(result.Reasons, "Student is unplaced. No active policies currently block this application.")
 		}
 	}
@@ -160,3 +218,31 @@ func PerformEligibilityCheck(student models.Student, company models.Company) mod
 
 	return result
 }
+
+// block makes the result ineligible and records the reason.
+func block(result *models.EligibilityResult, policy, code, reason string) {
+	result.IsEligible = false
+	result.Reasons = append(result.Reasons, reason)
+	result.Decisions = append(result.Decisions, models.PolicyDecision{Policy: policy, Code: code, Outcome: models.DecisionBlocked})
+}
+
+// allow records a reason supporting eligibility without changing it.
+func allow(result *models.EligibilityResult, policy, code, reason string) {
+	result.Reasons = append(result.Reasons, reason)
+	result.Decisions = append(result.Decisions, models.PolicyDecision{Policy: policy, Code: code, Outcome: models.DecisionAllowed})
+}
+
+// override makes the result eligible and records the reason. Earlier blocking reasons are discarded,
+// but their decisions are kept and marked overridden so reports can tell what the override set aside.
+func override(result *models.EligibilityResult, policy, code, reason string) {
+	if !result.IsEligible {
+		result.IsEligible = true
+		result.Reasons = []string{}
+		for i := range result.Decisions {
+			if result.Decisions[i].Outcome == models.DecisionBlocked {
+				result.Decisions[i].Outcome = models.DecisionOverridden
+			}
+		}
+	}
+	allow(result, policy, code, reason)
+}
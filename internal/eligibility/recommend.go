@@ -0,0 +1,161 @@
+package eligibility
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"go-placement-policy/internal/models"
+)
+
+// Ranking criteria accepted by Recommend.
+const (
+	RankByScore  = "score"  // Weighted blend of the individual criteria (the default).
+	RankByUplift = "uplift" // Largest salary increase over the student's current salary first.
+	RankBySalary = "salary" // Highest offered salary first.
+	// RankByDriveDate puts the most urgent registration deadline first; companies without an upcoming drive go last.
+	RankByDriveDate = "driveDate"
+)
+
+// Weights of the criteria in the blended score. They sum to 1, so scores fall between 0 and 1.
+const (
+	upliftWeight       = 0.5
+	dreamCompanyWeight = 0.3
+	dreamOfferWeight   = 0.2
+)
+
+// Recommendation is an eligible company ranked for a student.
+type Recommendation struct {
+	Rank            int           `json:"rank"`
+	CompanyID       string        `json:"companyId"`
+	CompanyName     string        `json:"companyName"`
+	OfferedSalary   float64       `json:"offeredSalary"`
+	Score           float64       `json:"score"`
+	UpliftPercent   *float64      `json:"upliftPercent"` // Increase over the current salary; nil while the student has no salary.
+	DreamCompany    bool          `json:"dreamCompany"`
+	MeetsDreamOffer bool          `json:"meetsDreamOffer"`
+	Reasons         []string      `json:"reasons"`
+	NextDrive       *models.Drive `json:"nextDrive,omitempty"` // The company's next drive whose registration has not closed, if any.
+}
+
+// Recommendations splits a student's companies into ranked eligible ones and ineligible ones with their reasons.
+type Recommendations struct {
+	StudentID       int                        `json:"studentId"`
+	StudentName     string                     `json:"studentName"`
+	RankedBy        string                     `json:"rankedBy"`
+	Recommendations []Recommendation           `json:"recommendations"`
+	Ineligible      []models.EligibilityResult `json:"ineligible"`
+}
+
+// ValidRankBy reports whether by is a supported ranking criterion.
+func ValidRankBy(by string) bool {
+	return by == RankByScore || by == RankByUplift || by == RankBySalary || by == RankByDriveDate
+}
+
+// Recommend evaluates the student against every company with the active policies and ranks the eligible ones by
+// the given criterion (RankByScore if empty). nextDrives maps company IDs to their next drive still accepting
+// registration (open or upcoming) and may be nil. Ties are broken by offered salary, then company ID.
+//
+// The blended score rewards, in order of weight: salary uplift over CurrentSalary (capped at doubling it; for an
+// unplaced student, the offer relative to the best eligible offer), a match with the declared dream company, and
+// how close the offer comes to the declared dream offer amount.
+func Recommend(ctx context.Context, student models.Student, companies []models.Company, nextDrives map[string]models.Drive, by string) Recommendations {
+	if by == "" {
+		by = RankByScore
+	}
+	result := Recommendations{
+		StudentID:       student.ID,
+		StudentName:     student.FullName,
+		RankedBy:        by,
+		Recommendations: []Recommendation{},
+		Ineligible:      []models.EligibilityResult{},
+	}
+
+	var eligible []models.Company
+	reasons := map[string][]string{}
+	bestOffer := 0.0
+	for _, company := range companies {
+		check := PerformEligibilityCheck(ctx, student, company)
+		if !check.IsEligible {
+			result.Ineligible = append(result.Ineligible, check)
+			continue
+		}
+		eligible = append(eligible, company)
+		reasons[company.ID] = check.Reasons
+		bestOffer = math.Max(bestOffer, company.OfferedSalary)
+	}
+
+	for _, company := range eligible {
+		rec := Recommendation{
+			CompanyID:     company.ID,
+			CompanyName:   company.Name,
+			OfferedSalary: company.OfferedSalary,
+			DreamCompany:  IsDreamCompany(student, company),
+			Reasons:       reasons[company.ID],
+		}
+		if drive, ok := nextDrives[company.ID]; ok {
+			rec.NextDrive = &drive
+		}
+
+		var upliftScore float64
+		if student.CurrentSalary > 0 {
+			uplift := (company.OfferedSalary - student.CurrentSalary) / student.CurrentSalary * 100
+			uplift = math.Round(uplift*100) / 100
+			rec.UpliftPercent = &uplift
+			upliftScore = clamp(uplift/100, 0, 1)
+		} else if bestOffer > 0 {
+			upliftScore = company.OfferedSalary / bestOffer
+		}
+
+		var dreamOfferScore float64
+		if student.DreamOfferAmount > 0 {
+			rec.MeetsDreamOffer = company.OfferedSalary >= student.DreamOfferAmount
+			dreamOfferScore = clamp(company.OfferedSalary/student.DreamOfferAmount, 0, 1)
+		}
+
+		var dreamCompanyScore float64
+		if rec.DreamCompany {
+			dreamCompanyScore = 1
+		}
+		score := upliftWeight*upliftScore + dreamCompanyWeight*dreamCompanyScore + dreamOfferWeight*dreamOfferScore
+		rec.Score = math.Round(score*1000) / 1000
+		result.Recommendations = append(result.Recommendations, rec)
+	}
+
+	primary := func(r Recommendation) float64 {
+		switch by {
+		case RankByUplift:
+			if r.UpliftPercent != nil {
+				return *r.UpliftPercent
+			}
+			return r.OfferedSalary // Without a current salary every offer is pure uplift; larger is better.
+		case RankBySalary:
+			return r.OfferedSalary
+		}
+		return r.Score
+	}
+	sort.SliceStable(result.Recommendations, func(i, j int) bool {
+		a, b := result.Recommendations[i], result.Recommendations[j]
+		if by == RankByDriveDate && (a.NextDrive == nil) != (b.NextDrive == nil) {
+			return a.NextDrive != nil
+		}
+		if by == RankByDriveDate && a.NextDrive != nil && !a.NextDrive.RegistrationClosesAt.Equal(b.NextDrive.RegistrationClosesAt) {
+			return a.NextDrive.RegistrationClosesAt.Before(b.NextDrive.RegistrationClosesAt)
+		}
+		if pa, pb := primary(a), primary(b); pa != pb {
+			return pa > pb
+		}
+		if a.OfferedSalary != b.OfferedSalary {
+			return a.OfferedSalary > b.OfferedSalary
+		}
+		return a.CompanyID < b.CompanyID
+	})
+	for i := range result.Recommendations {
+		result.Recommendations[i].Rank = i + 1
+	}
+	return result
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
@@ -0,0 +1,137 @@
+package eligibility
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestIsDreamCompany(t *testing.T) {
+	tests := []struct {
+		dream, company string
+		want           bool
+	}{
+		{"Infosys", "Infosys", true},
+		{"infosys", "Infosys", true},
+		{" INFOSYS ", "infosys", true},
+		{"Infosys", "Infosys Ltd", false},
+		{"", "", false},
+		{"  ", "", false},
+	}
+	for _, tt := range tests {
+		got := IsDreamCompany(models.Student{DreamCompanyName: tt.dream}, models.Company{Name: tt.company})
+		if got != tt.want {
+			t.Errorf("IsDreamCompany(%q, %q) = %v, want %v", tt.dream, tt.company, got, tt.want)
+		}
+	}
+}
+
+// recommendFixture is a placed L3 student whose dream company, written in another case, offers less than their
+// dream offer, so it is only eligible through the Dream Company Policy override.
+func recommendFixture(t *testing.T) (models.Student, []models.Company) {
+	var config models.PolicyConfig
+	config.DreamOffer.Enabled = true
+	config.DreamCompany.Enabled = true
+	config.OfferCategory.Enabled = true
+	config.OfferCategory.L1ThresholdAmount = 2000000
+	config.OfferCategory.L2ThresholdAmount = 1000000
+	usePolicy(t, config)
+
+	student := models.Student{ID: 1, FullName: "Asha", IsPlaced: true, CurrentSalary: 500000,
+		DreamOfferAmount: 800000, DreamCompanyName: "infosys "}
+	companies := []models.Company{
+		{ID: "E", Name: "Zeta", OfferedSalary: 1000000},
+		{ID: "D", Name: "Low", OfferedSalary: 600000},
+		{ID: "C", Name: "Beta", OfferedSalary: 900000},
+		{ID: "B", Name: "Infosys", OfferedSalary: 750000},
+		{ID: "A", Name: "Acme", OfferedSalary: 1000000},
+	}
+	return student, companies
+}
+
+func TestRecommendScores(t *testing.T) {
+	student, companies := recommendFixture(t)
+	result := Recommend(context.Background(), student, companies, nil, "")
+
+	if len(result.Ineligible) != 1 || result.Ineligible[0].CompanyID != "D" {
+		t.Fatalf("ineligible = %+v, want only D, which is below the dream offer", result.Ineligible)
+	}
+	want := map[string]struct {
+		score  float64
+		uplift float64
+		dream  bool
+		meets  bool
+	}{
+		// 0.5 × uplift (capped at 100%) + 0.3 × dream company + 0.2 × share of the dream offer.
+		"A": {0.7, 100, false, true},
+		"E": {0.7, 100, false, true},
+		"C": {0.6, 80, false, true},
+		"B": {0.738, 50, true, false}, // 0.5×0.5 + 0.3 + 0.2×(750000/800000)
+	}
+	for _, rec := range result.Recommendations {
+		w := want[rec.CompanyID]
+		if rec.Score != w.score || rec.UpliftPercent == nil || *rec.UpliftPercent != w.uplift ||
+			rec.DreamCompany != w.dream || rec.MeetsDreamOffer != w.meets {
+			t.Errorf("%s: score %v, uplift %v, dream %v, meets dream offer %v; want %+v",
+				rec.CompanyID, rec.Score, *rec.UpliftPercent, rec.DreamCompany, rec.MeetsDreamOffer, w)
+		}
+	}
+}
+
+func TestRecommendUnplacedScoresAgainstTheBestOffer(t *testing.T) {
+	usePolicy(t, models.PolicyConfig{})
+	student := models.Student{ID: 2, FullName: "Bina"}
+	companies := []models.Company{{ID: "A", OfferedSalary: 1000000}, {ID: "B", OfferedSalary: 500000}}
+
+	result := Recommend(context.Background(), student, companies, nil, RankByScore)
+	scores := map[string]float64{}
+	for _, rec := range result.Recommendations {
+		if rec.UpliftPercent != nil {
+			t.Errorf("%s: uplift %v for a student without a salary", rec.CompanyID, *rec.UpliftPercent)
+		}
+		scores[rec.CompanyID] = rec.Score
+	}
+	if want := map[string]float64{"A": 0.5, "B": 0.25}; !reflect.DeepEqual(scores, want) {
+		t.Errorf("scores = %v, want %v", scores, want)
+	}
+}
+
+func TestRecommendOrder(t *testing.T) {
+	student, companies := recommendFixture(t)
+	closes := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	nextDrives := map[string]models.Drive{
+		"B": {ID: 2, CompanyID: "B", RegistrationClosesAt: closes.Add(24 * time.Hour)},
+		"C": {ID: 1, CompanyID: "C", RegistrationClosesAt: closes},
+	}
+
+	tests := []struct {
+		by   string
+		want []string
+	}{
+		// A and E tie on score, uplift and salary, so the company ID decides.
+		{RankByScore, []string{"B", "A", "E", "C"}},
+		{RankByUplift, []string{"A", "E", "C", "B"}},
+		{RankBySalary, []string{"A", "E", "C", "B"}},
+		// Companies with a drive come first, soonest deadline first; the rest follow by score.
+		{RankByDriveDate, []string{"C", "B", "A", "E"}},
+	}
+	for _, tt := range tests {
+		result := Recommend(context.Background(), student, companies, nextDrives, tt.by)
+		var got []string
+		for i, rec := range result.Recommendations {
+			got = append(got, rec.CompanyID)
+			if rec.Rank != i+1 {
+				t.Errorf("%s: %s has rank %d at position %d", tt.by, rec.CompanyID, rec.Rank, i+1)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ranked by %s: %v, want %v", tt.by, got, tt.want)
+		}
+		if result.RankedBy != tt.by {
+			t.Errorf("rankedBy = %q, want %q", result.RankedBy, tt.by)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+// Package events fans out change notifications to live subscribers, such as the GET /events Server-Sent Events
+// stream. Recent events are kept in a ring buffer so a client that reconnects with the last event ID it saw can
+// catch up on what it missed.
+package events
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/audit"
+)
+
+// Topics events are published under. Subscribers filter on these.
+const (
+	TopicStudent     = "student"
+	TopicCompany     = "company"
+	TopicDrive       = "drive"
+	TopicApplication = "application"
+	TopicOffer       = "offer"
+	TopicPolicy      = "policy"
+	TopicEligibility = "eligibility"
+)
+
+// Topics lists every topic, for validating subscriber filters.
+var Topics = []string{TopicStudent, TopicCompany, TopicDrive, TopicApplication, TopicOffer, TopicPolicy, TopicEligibility}
+
+// TypeEligibilityChange is published by the change detector when a student gains or loses eligibility for a company.
+const TypeEligibilityChange = "eligibility.change"
+
+// BufferSize is the number of recent events kept for replay.
+const BufferSize = 1024
+
+// subscriberBuffer is how many events may queue for a subscriber before it is considered too slow and dropped.
+const subscriberBuffer = 64
+
+// Event is a change notification. Type is the audit action that caused it (e.g. "student.update") and Data is
+// the entity after the change.
+type Event struct {
+	ID       int64           `json:"id"`
+	Topic    string          `json:"topic"`
+	Type     string          `json:"type"`
+	EntityID string          `json:"entityId"`
+	Time     time.Time       `json:"time"`
+	Data     json.RawMessage `json:"data,omitempty"`
+}
+
+// Subscription receives the events published after it was created. C is closed when the subscription is
+// cancelled or when the subscriber falls too far behind; the client should then reconnect with its last event ID.
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	topics map[string]bool
+}
+
+var (
+	// dispatch serialises Publish so listeners see events in ID order without being called under mutex.
+	dispatch    sync.Mutex
+	mutex       sync.Mutex
+	ring        = make([]Event, BufferSize)
+	count       int   // Number of events in ring, at most BufferSize.
+	nextID      int64 = 1
+	subscribers       = map[*Subscription]bool{}
+	listeners   []func(Event)
+)
+
+// topicForAction maps audit actions that change data to event topics. Actions that only record a decision,
+// such as eligibility checks, are not published.
+var topicForAction = map[string]string{
+	audit.ActionStudentCreate:     TopicStudent,
+	audit.ActionStudentUpdate:     TopicStudent,
+	audit.ActionCompanyCreate:     TopicCompany,
+	audit.ActionCompanyUpdate:     TopicCompany,
+	audit.ActionDriveCreate:       TopicDrive,
+	audit.ActionDriveUpdate:       TopicDrive,
+	audit.ActionApplicationCreate: TopicApplication,
+	audit.ActionApplicationRound:  TopicApplication,
+	audit.ActionOfferRecord:       TopicOffer,
+	audit.ActionPolicyConfigure:   TopicPolicy,
+	audit.ActionPolicyPatch:       TopicPolicy,
+	audit.ActionPolicyUpdate:      TopicPolicy,
+	audit.ActionPolicyEnable:      TopicPolicy,
+	audit.ActionPolicyDisable:     TopicPolicy,
+	audit.ActionPolicyReload:      TopicPolicy,
+}
+
+// AddListener registers fn to be called with every published event, in order, e.g. to queue webhook deliveries.
+// Unlike subscribers, listeners are never dropped, so they must return quickly and must not publish events.
+// They are called without the subscriber lock held, so they may subscribe or register further listeners.
+func AddListener(fn func(Event)) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	listeners = append(listeners, fn)
+}
+
+// Known reports whether name is a topic or an event type that can be published.
+func Known(name string) bool {
+	if _, ok := topicForAction[name]; ok || name == TypeEligibilityChange {
+		return true
+	}
+	for _, topic := range Topics {
+		if topic == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishAudit publishes the change recorded by an audit entry. It is registered with audit.AddListener.
+func PublishAudit(e audit.Entry) {
+	if topic, ok := topicForAction[e.Action]; ok {
+		Publish(topic, e.Action, e.EntityID, e.After)
+	}
+}
+
+// Publish assigns the next event ID, stores the event for replay and delivers it to matching subscribers.
+func Publish(topic, eventType, entityID string, data json.RawMessage) Event {
+	dispatch.Lock()
+	defer dispatch.Unlock()
+	mutex.Lock()
+
+	e := Event{ID: nextID, Topic: topic, Type: eventType, EntityID: entityID, Time: time.Now().UTC(), Data: data}
+	nextID++
+	ring[int(e.ID-1)%BufferSize] = e
+	if count < BufferSize {
+		count++
+	}
+
+	for s := range subscribers {
+		if !s.wants(e) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			// Never block publishers on a slow client; it can resume from its last event ID.
+			delete(subscribers, s)
+			close(s.ch)
+		}
+	}
+	notify := make([]func(Event), len(listeners))
+	copy(notify, listeners)
+	mutex.Unlock()
+
+	for _, fn := range notify {
+		fn(e)
+	}
+	return e
+}
+
+// Subscribe registers a subscriber for the given topics (all topics if empty). If lastEventID is non-zero the
+// buffered events after it are returned for replay, and complete is false when some of them have already been
+// overwritten, in which case the client should reload its data instead of relying on the replay.
+func Subscribe(topics []string, lastEventID int64) (s *Subscription, replay []Event, complete bool) {
+	ch := make(chan Event, subscriberBuffer)
+	s = &Subscription{C: ch, ch: ch}
+	if len(topics) > 0 {
+		s.topics = map[string]bool{}
+		for _, t := range topics {
+			s.topics[t] = true
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	subscribers[s] = true
+
+	complete = true
+	if lastEventID > 0 {
+		last, oldest := nextID-1, nextID-int64(count)
+		// An ID ahead of the last event was issued before a restart; one before the buffer has been overwritten.
+		if lastEventID > last || lastEventID+1 < oldest {
+			complete = false
+		}
+		for id := max(lastEventID+1, oldest); id <= last; id++ {
+			if e := ring[int(id-1)%BufferSize]; s.wants(e) {
+				replay = append(replay, e)
+			}
+		}
+	}
+	return s, replay, complete
+}
+
+// Cancel stops delivery to the subscription and closes its channel.
+func (s *Subscription) Cancel() {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if subscribers[s] {
+		delete(subscribers, s)
+		close(s.ch)
+	}
+}
+
+func (s *Subscription) wants(e Event) bool {
+	return s.topics == nil || s.topics[e.Topic]
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// reset clears the published events, subscribers and listeners, and restores them when the test ends.
+func reset(t *testing.T) {
+	t.Helper()
+	mutex.Lock()
+	savedRing, savedCount, savedNextID, savedSubscribers, savedListeners := ring, count, nextID, subscribers, listeners
+	ring, count, nextID, subscribers, listeners = make([]Event, BufferSize), 0, 1, map[*Subscription]bool{}, nil
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		ring, count, nextID, subscribers, listeners = savedRing, savedCount, savedNextID, savedSubscribers, savedListeners
+		mutex.Unlock()
+	})
+}
+
+func publishN(n int, topic string) {
+	for i := 0; i < n; i++ {
+		Publish(topic, topic+".update", fmt.Sprint(i), nil)
+	}
+}
+
+func ids(events []Event) []int64 {
+	var result []int64
+	for _, e := range events {
+		result = append(result, e.ID)
+	}
+	return result
+}
+
+func TestSubscribeReplaysAfterLastEventID(t *testing.T) {
+	reset(t)
+	Publish(TopicStudent, "student.update", "1", nil)
+	Publish(TopicCompany, "company.update", "C1", nil)
+	Publish(TopicStudent, "student.update", "2", nil)
+	Publish(TopicPolicy, "policy.update", "", nil)
+
+	tests := []struct {
+		name         string
+		topics       []string
+		lastEventID  int64
+		wantIDs      []int64
+		wantComplete bool
+	}{
+		{"new subscriber gets no replay", nil, 0, nil, true},
+		{"events after the last ID", nil, 2, []int64{3, 4}, true},
+		{"filtered by topic", []string{TopicStudent}, 1, []int64{3}, true},
+		{"up to date", nil, 4, nil, true},
+		{"ID from before a restart", nil, 9, nil, false},
+	}
+	for _, tt := range tests {
+		s, replay, complete := Subscribe(tt.topics, tt.lastEventID)
+		s.Cancel()
+		if got := ids(replay); fmt.Sprint(got) != fmt.Sprint(tt.wantIDs) || complete != tt.wantComplete {
+			t.Errorf("%s: replayed %v, complete %v; want %v, %v", tt.name, got, complete, tt.wantIDs, tt.wantComplete)
+		}
+	}
+}
+
+func TestSubscribeAfterTheRingWraps(t *testing.T) {
+	reset(t)
+	publishN(BufferSize+10, TopicStudent) // Events 1 to 10 have been overwritten; 11 is the oldest kept.
+
+	tests := []struct {
+		lastEventID  int64
+		wantFirst    int64
+		wantLen      int
+		wantComplete bool
+	}{
+		{10, 11, BufferSize, true},
+		{9, 11, BufferSize, false}, // Event 10 is gone, so the client must resync.
+		{1, 11, BufferSize, false},
+		{BufferSize, BufferSize + 1, 10, true},
+	}
+	for _, tt := range tests {
+		s, replay, complete := Subscribe(nil, tt.lastEventID)
+		s.Cancel()
+		if len(replay) != tt.wantLen || replay[0].ID != tt.wantFirst || replay[len(replay)-1].ID != BufferSize+10 ||
+			complete != tt.wantComplete {
+			t.Errorf("after %d: replayed %d events from %d, complete %v; want %d from %d, complete %v",
+				tt.lastEventID, len(replay), replay[0].ID, complete, tt.wantLen, tt.wantFirst, tt.wantComplete)
+		}
+	}
+}
+
+func TestSlowSubscribersAreDropped(t *testing.T) {
+	reset(t)
+	slow, _, _ := Subscribe(nil, 0)
+	other, _, _ := Subscribe([]string{TopicCompany}, 0)
+	defer other.Cancel()
+
+	publishN(subscriberBuffer+1, TopicStudent)
+	received := 0
+	for range slow.C {
+		received++
+	}
+	if received != subscriberBuffer {
+		t.Errorf("slow subscriber received %d events before its channel closed, want %d", received, subscriberBuffer)
+	}
+	slow.Cancel() // Cancelling a dropped subscription is harmless.
+
+	Publish(TopicCompany, "company.update", "C1", nil)
+	select {
+	case e := <-other.C:
+		if e.ID != subscriberBuffer+2 {
+			t.Errorf("other subscriber got event %d", e.ID)
+		}
+	default:
+		t.Error("a subscriber to another topic was dropped along with the slow one")
+	}
+}
+
+func TestListenersRunInOrderOutsideTheLock(t *testing.T) {
+	reset(t)
+	var mu sync.Mutex
+	var seen []int64
+	AddListener(func(e Event) {
+		// Subscribing takes the subscriber lock, which would deadlock if listeners were called under it.
+		s, _, _ := Subscribe(nil, 0)
+		s.Cancel()
+		mu.Lock()
+		seen = append(seen, e.ID)
+		mu.Unlock()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				publishN(50, TopicStudent)
+			}()
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publishing did not finish; a listener is blocked on the subscriber lock")
+	}
+
+	if len(seen) != 200 {
+		t.Fatalf("listener saw %d events, want 200", len(seen))
+	}
+	for i, id := range seen {
+		if id != int64(i+1) {
+			t.Fatalf("listener saw event %d at position %d; events must arrive in ID order", id, i)
+		}
+	}
+}
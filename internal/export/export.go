@@ -0,0 +1,168 @@
+// Package export streams tabular data as CSV, JSON Lines or XLSX.
+//
+// Rows are written as they are produced, so the size of an export is bounded by the
+// underlying data rather than by an in-memory copy of the encoded file.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format identifies an export file format.
+type Format string
+
+// Supported formats.
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+	FormatXLSX  Format = "xlsx"
+)
+
+// ParseFormat parses a format name; an empty name means CSV.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(name)); f {
+	case "":
+		return FormatCSV, nil
+	case FormatCSV, FormatJSONL, FormatXLSX:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown export format %q; use csv, jsonl or xlsx", name)
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSONL:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Writer streams rows of a table. Each row holds one value per column, of type string, bool,
+// int, int64, float64, time.Time, []string or nil.
+type Writer interface {
+	WriteRow(values []interface{}) error
+	// Flush pushes buffered rows to the underlying writer.
+	Flush() error
+	// Close finishes the file. It does not close the underlying writer.
+	Close() error
+}
+
+// NewWriter starts a table with the given column names in format f. sheet names the worksheet in XLSX files.
+func NewWriter(w io.Writer, f Format, sheet string, columns []string) (Writer, error) {
+	switch f {
+	case FormatJSONL:
+		return newJSONLWriter(w, columns), nil
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet, columns)
+	}
+	return newCSVWriter(w, columns)
+}
+
+// formatText renders a value as text for CSV cells.
+func formatText(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case []string:
+		return strings.Join(v, "; ")
+	}
+	return fmt.Sprint(v)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, columns []string) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	return cw, cw.w.Write(columns)
+}
+
+// WriteRow writes one CSV record. Text that a spreadsheet would evaluate as a formula is prefixed with
+// an apostrophe, so an exported name such as "=HYPERLINK(...)" stays inert when the file is opened.
+func (cw *csvWriter) WriteRow(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		text := formatText(v)
+		if s, ok := v.(string); ok && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+			text = "'" + text
+		}
+		record[i] = text
+	}
+	return cw.w.Write(record)
+}
+
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+func (cw *csvWriter) Close() error {
+	return cw.Flush()
+}
+
+// jsonlWriter writes one JSON object per line, with keys in column order.
+type jsonlWriter struct {
+	w    *bufio.Writer
+	keys [][]byte
+}
+
+func newJSONLWriter(w io.Writer, columns []string) *jsonlWriter {
+	jw := &jsonlWriter{w: bufio.NewWriter(w)}
+	for _, c := range columns {
+		key, _ := json.Marshal(c)
+		jw.keys = append(jw.keys, key)
+	}
+	return jw
+}
+
+func (jw *jsonlWriter) WriteRow(values []interface{}) error {
+	jw.w.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			jw.w.WriteByte(',')
+		}
+		jw.w.Write(jw.keys[i])
+		jw.w.WriteByte(':')
+		if list, ok := v.([]string); ok && list == nil {
+			v = []string{} // Keep list columns arrays, so consumers need not special-case null.
+		}
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		jw.w.Write(encoded)
+	}
+	_, err := jw.w.WriteString("}\n")
+	return err
+}
+
+func (jw *jsonlWriter) Flush() error {
+	return jw.w.Flush()
+}
+
+func (jw *jsonlWriter) Close() error {
+	return jw.Flush()
+}
@@ -0,0 +1,217 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{"", FormatCSV, false},
+		{"csv", FormatCSV, false},
+		{"JSONL", FormatJSONL, false},
+		{"xlsx", FormatXLSX, false},
+		{"xls", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseFormat(tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// writeTable writes the rows with a writer for format f and returns the encoded file.
+func writeTable(t *testing.T, f Format, columns []string, rows ...[]interface{}) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, f, "Sheet", columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCSVNeutralisesFormulas(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+91 98450", "'+91 98450"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tindented", "'\tindented"},
+		{"\rreturn", "'\rreturn"},
+		{"a=b", "a=b"},
+		{"", ""},
+		{-5, "-5"}, // Numbers are not text, so a leading minus is left alone.
+		{-1.5, "-1.5"},
+		{[]string{"=a", "b"}, "=a; b"},
+	}
+	for _, tt := range tests {
+		data := writeTable(t, FormatCSV, []string{"value", "id"}, []interface{}{tt.value, 1})
+		records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+		if err != nil {
+			t.Fatalf("%q: %v", tt.value, err)
+		}
+		if len(records) != 2 || records[1][0] != tt.want {
+			t.Errorf("%#v exported as %q, want %q", tt.value, records[1:], tt.want)
+		}
+	}
+}
+
+func TestJSONL(t *testing.T) {
+	at := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	data := writeTable(t, FormatJSONL, []string{"id", "roles", "tags", "note", "at", "ok"},
+		[]interface{}{1, []string(nil), []string{"a", "b"}, nil, at, true},
+		[]interface{}{2, []string{}, []string{"c"}, "x\ny", at, false},
+	)
+	want := `{"id":1,"roles":[],"tags":["a","b"],"note":null,"at":"2025-03-01T09:30:00Z","ok":true}
+{"id":2,"roles":[],"tags":["c"],"note":"x\ny","at":"2025-03-01T09:30:00Z","ok":false}
+`
+	if string(data) != want {
+		t.Errorf("JSONL =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "A"}, {25, "Z"}, {26, "AA"}, {27, "AB"}, {51, "AZ"}, {52, "BA"}, {701, "ZZ"}, {702, "AAA"},
+	}
+	for _, tt := range tests {
+		if got := columnName(tt.index); got != tt.want {
+			t.Errorf("columnName(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+// xlsxCell is a cell of the worksheet XML, enough to check references and values.
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+// readXLSX opens the archive and returns its entry names, the sheet name and the worksheet rows.
+func readXLSX(t *testing.T, data []byte) (entries []string, sheet string, rows [][]xlsxCell) {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a valid zip: %v", err)
+	}
+	read := func(name string) []byte {
+		for _, f := range archive.File {
+			if f.Name == name {
+				r, err := f.Open()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer r.Close()
+				body, err := io.ReadAll(r)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return body
+			}
+		}
+		t.Fatalf("archive has no %s", name)
+		return nil
+	}
+	for _, f := range archive.File {
+		entries = append(entries, f.Name)
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(read("xl/workbook.xml"), &workbook); err != nil || len(workbook.Sheets) != 1 {
+		t.Fatalf("workbook: %v, %d sheets", err, len(workbook.Sheets))
+	}
+	var worksheet struct {
+		Rows []struct {
+			Ref   string     `xml:"r,attr"`
+			Cells []xlsxCell `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(read("xl/worksheets/sheet1.xml"), &worksheet); err != nil {
+		t.Fatalf("worksheet is not valid XML: %v", err)
+	}
+	for _, row := range worksheet.Rows {
+		rows = append(rows, row.Cells)
+	}
+	return entries, workbook.Sheets[0].Name, rows
+}
+
+func TestXLSX(t *testing.T) {
+	columns := make([]string, 30)
+	for i := range columns {
+		columns[i] = "col" + columnName(i)
+	}
+	row := make([]interface{}, 30)
+	row[0], row[1], row[2], row[3] = "<Acme & Co>", 42, 7.5, true
+	row[26], row[29] = []string{"x", "y"}, "bad\x00char"
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, FormatXLSX, strings.Repeat("s", 40), columns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(row); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, sheet, rows := readXLSX(t, buf.Bytes())
+	wantEntries := []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/workbook.xml", "xl/worksheets/sheet1.xml"}
+	if !reflect.DeepEqual(entries, wantEntries) {
+		t.Errorf("entries = %v, want %v", entries, wantEntries)
+	}
+	if sheet != strings.Repeat("s", 31) {
+		t.Errorf("sheet name %q, want it cut to 31 characters", sheet)
+	}
+	if len(rows) != 2 || len(rows[0]) != 30 {
+		t.Fatalf("got %d rows with %d header cells, want 2 rows and 30 header cells", len(rows), len(rows[0]))
+	}
+	if h := rows[0][26]; h.Ref != "AA1" || h.Inline != "colAA" {
+		t.Errorf("header cell 26 = %+v, want AA1 colAA", h)
+	}
+	want := []xlsxCell{
+		{Ref: "A2", Type: "inlineStr", Inline: "<Acme & Co>"},
+		{Ref: "B2", Value: "42"},
+		{Ref: "C2", Value: "7.5"},
+		{Ref: "D2", Type: "b", Value: "1"},
+		{Ref: "AA2", Type: "inlineStr", Inline: "x; y"}, // Nil values leave no cell, so refs skip E to Z.
+		{Ref: "AD2", Type: "inlineStr", Inline: "bad\ufffdchar"},
+	}
+	if !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("data row =\n%+v\nwant\n%+v", rows[1], want)
+	}
+}
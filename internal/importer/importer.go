@@ -0,0 +1,312 @@
+// Package importer reads bulk uploads of students, companies and drive round results from CSV or JSON.
+// Columns are matched to canonical field names by name or by an explicit Mapping, and a Parser converts each
+// row's values while collecting every invalid field, so a Report can list all problems before anything is saved.
+package importer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Row actions reported in a Report.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionError  = "error"
+)
+
+// Record is one input row with its values keyed by canonical field name (e.g. "rollNumber").
+// Only fields whose column is present in the input appear in Fields.
+type Record struct {
+	Line   int // 1-based line in a CSV file (the header is line 1), or 1-based element index in a JSON array.
+	Fields map[string]string
+}
+
+// FieldError describes one invalid value in a row.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// RowResult is the outcome of importing one row.
+type RowResult struct {
+	Line   int          `json:"line"`
+	Key    string       `json:"key,omitempty"` // The upsert key of the row, e.g. the roll number.
+	Action string       `json:"action"`        // create, update or error
+	ID     string       `json:"id,omitempty"`  // ID of the existing or newly created record.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// Report summarises an import. Nothing is committed unless every row is valid.
+type Report struct {
+	DryRun    bool        `json:"dryRun"`
+	Committed bool        `json:"committed"`
+	TotalRows int         `json:"totalRows"`
+	Created   int         `json:"created"`
+	Updated   int         `json:"updated"`
+	Failed    int         `json:"failed"`
+	Rows      []RowResult `json:"rows"`
+}
+
+// Add appends a row result and updates the counters.
+func (r *Report) Add(row RowResult) {
+	r.TotalRows++
+	switch row.Action {
+	case ActionCreate:
+		r.Created++
+	case ActionUpdate:
+		r.Updated++
+	default:
+		r.Failed++
+	}
+	r.Rows = append(r.Rows, row)
+}
+
+// Mapping maps canonical field names to the column names used in the input.
+// Fields without an entry are matched to columns by name, ignoring case, spaces, underscores and hyphens.
+type Mapping map[string]string
+
+// ParseMapping parses "field:Column Name" pairs, e.g. from repeated ?map= query parameters.
+func ParseMapping(pairs []string, fields []string) (Mapping, error) {
+	mapping := Mapping{}
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(column) == "" {
+			return nil, fmt.Errorf("column mapping %q must look like field:Column Name", pair)
+		}
+		field = strings.TrimSpace(field)
+		if !contains(fields, field) {
+			return nil, fmt.Errorf("column mapping %q refers to unknown field %q; fields are %s", pair, field, strings.Join(fields, ", "))
+		}
+		mapping[field] = strings.TrimSpace(column)
+	}
+	return mapping, nil
+}
+
+func contains(values []string, v string) bool {
+	for _, candidate := range values {
+		if candidate == v {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeName(s string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+// resolveColumns maps each canonical field to the input column that holds it.
+func resolveColumns(columns []string, fields []string, mapping Mapping) (map[string]string, error) {
+	byNormalized := map[string]string{}
+	for _, column := range columns {
+		byNormalized[normalizeName(column)] = column
+	}
+	resolved := map[string]string{}
+	for _, field := range fields {
+		if wanted, ok := mapping[field]; ok {
+			column, found := byNormalized[normalizeName(wanted)]
+			if !found {
+				return nil, fmt.Errorf("mapped column %q for field %s is not in the input", wanted, field)
+			}
+			resolved[field] = column
+		} else if column, found := byNormalized[normalizeName(field)]; found {
+			resolved[field] = column
+		}
+	}
+	return resolved, nil
+}
+
+// ReadCSV reads a CSV document whose first row is a header and returns one Record per data row.
+func ReadCSV(r io.Reader, fields []string, mapping Mapping) ([]Record, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // Short rows are reported per field instead of failing the whole file.
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("CSV input is empty; expected a header row")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff") // Spreadsheet exports often start with a byte order mark.
+	}
+	columns, err := resolveColumns(header, fields, mapping)
+	if err != nil {
+		return nil, err
+	}
+	position := map[string]int{}
+	for i, column := range header {
+		position[column] = i
+	}
+
+	var records []Record
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(row) {
+			continue
+		}
+		record := Record{Line: line, Fields: map[string]string{}}
+		for field, column := range columns {
+			if i := position[column]; i < len(row) {
+				record.Fields[field] = strings.TrimSpace(row[i])
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func isBlank(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadJSON reads a JSON array of objects and returns one Record per element.
+// Scalar values are converted to strings so they go through the same parsing as CSV cells.
+func ReadJSON(r io.Reader, fields []string, mapping Mapping) ([]Record, error) {
+	var items []map[string]interface{}
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, fmt.Errorf("expected a JSON array of objects: %w", err)
+	}
+
+	var records []Record
+	for i, item := range items {
+		keys := make([]string, 0, len(item))
+		for k := range item {
+			keys = append(keys, k)
+		}
+		columns, err := resolveColumns(keys, fields, mapping)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i+1, err)
+		}
+		record := Record{Line: i + 1, Fields: map[string]string{}}
+		for field, key := range columns {
+			switch v := item[key].(type) {
+			case nil:
+				// Treat null like an empty cell.
+				record.Fields[field] = ""
+			case string:
+				record.Fields[field] = strings.TrimSpace(v)
+			case float64:
+				record.Fields[field] = strconv.FormatFloat(v, 'f', -1, 64)
+			case bool:
+				record.Fields[field] = strconv.FormatBool(v)
+			case []interface{}:
+				// Lists such as company roles are flattened to the same ";"-separated form used in CSV cells.
+				parts := make([]string, 0, len(v))
+				for _, item := range v {
+					s, ok := item.(string)
+					if !ok {
+						return nil, fmt.Errorf("element %d: field %q must be a list of strings", i+1, key)
+					}
+					parts = append(parts, s)
+				}
+				record.Fields[field] = strings.Join(parts, ";")
+			default:
+				return nil, fmt.Errorf("element %d: field %q must be a string, number, boolean or list of strings", i+1, key)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Parser reads typed values out of a Record, collecting an error for every invalid field.
+type Parser struct {
+	Record Record
+	Errors []FieldError
+}
+
+// Has reports whether the input included a column for field.
+func (p *Parser) Has(field string) bool {
+	_, ok := p.Record.Fields[field]
+	return ok
+}
+
+// String returns the trimmed value of field, or "" if absent.
+func (p *Parser) String(field string) string {
+	return p.Record.Fields[field]
+}
+
+// Float parses field as a number; an empty value is 0. Grouping commas ("12,00,000") are accepted.
+func (p *Parser) Float(field string) float64 {
+	raw := strings.ReplaceAll(p.Record.Fields[field], ",", "")
+	if raw == "" {
+		return 0
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		p.Fail(field, fmt.Sprintf("%q is not a number", p.Record.Fields[field]))
+	}
+	return v
+}
+
+// Int parses field as an integer; an empty value is 0.
+func (p *Parser) Int(field string) int {
+	raw := p.Record.Fields[field]
+	if raw == "" {
+		return 0
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		p.Fail(field, fmt.Sprintf("%q is not a whole number", raw))
+	}
+	return v
+}
+
+// Bool parses field as a boolean, accepting true/false, yes/no, y/n and 1/0; an empty value is false.
+func (p *Parser) Bool(field string) bool {
+	switch strings.ToLower(p.Record.Fields[field]) {
+	case "", "false", "no", "n", "0":
+		return false
+	case "true", "yes", "y", "1":
+		return true
+	}
+	p.Fail(field, fmt.Sprintf("%q is not a yes/no value", p.Record.Fields[field]))
+	return false
+}
+
+// List splits field on ";" or "|" into trimmed, non-empty values; an empty value is nil.
+func (p *Parser) List(field string) []string {
+	var values []string
+	for _, part := range strings.FieldsFunc(p.Record.Fields[field], func(r rune) bool { return r == ';' || r == '|' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// Failed reports whether an error has already been recorded for field.
+func (p *Parser) Failed(field string) bool {
+	for _, e := range p.Errors {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Fail records an error for field.
+func (p *Parser) Fail(field, message string) {
+	p.Errors = append(p.Errors, FieldError{Field: field, Message: message})
+}
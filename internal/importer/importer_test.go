@@ -0,0 +1,147 @@
+package importer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testFields = []string{"rollNumber", "fullName", "cgpa", "isPlaced", "roles"}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping([]string{"rollNumber: Roll No", " cgpa :GPA"}, testFields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Mapping{"rollNumber": "Roll No", "cgpa": "GPA"}); !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+
+	for _, pair := range []string{"rollNumber", "rollNumber:", "rollNumber:  ", "salary:Salary"} {
+		if _, err := ParseMapping([]string{pair}, testFields); err == nil {
+			t.Errorf("ParseMapping(%q) succeeded", pair)
+		}
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	input := "\ufeffRoll No,Full_Name, CGPA ,is-placed,Notes\n" +
+		"R1, Asha Rao ,8.4,yes,first\n" +
+		",,,,\n" +
+		"R2,\"Rao, Bina\",7.9\n"
+	records, err := ReadCSV(strings.NewReader(input), testFields, Mapping{"rollNumber": "roll no"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Line: 2, Fields: map[string]string{"rollNumber": "R1", "fullName": "Asha Rao", "cgpa": "8.4", "isPlaced": "yes"}},
+		{Line: 4, Fields: map[string]string{"rollNumber": "R2", "fullName": "Rao, Bina", "cgpa": "7.9"}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	tests := []struct {
+		name, input string
+		mapping     Mapping
+		want        string
+	}{
+		{"empty", "", nil, "empty"},
+		{"missing mapped column", "rollNumber\nR1\n", Mapping{"cgpa": "GPA"}, `mapped column "GPA"`},
+		{"bad quoting", "rollNumber\n\"R1\n", nil, "reading CSV"},
+	}
+	for _, tt := range tests {
+		if _, err := ReadCSV(strings.NewReader(tt.input), testFields, tt.mapping); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one mentioning %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	input := `[
+		{"Roll Number": "R1", "full_name": " Asha ", "cgpa": 8.5, "isPlaced": true, "roles": ["SDE", "Analyst"], "extra": {"x": 1}},
+		{"rollNumber": "R2", "cgpa": null}
+	]`
+	records, err := ReadJSON(strings.NewReader(input), testFields, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Record{
+		{Line: 1, Fields: map[string]string{"rollNumber": "R1", "fullName": "Asha", "cgpa": "8.5", "isPlaced": "true", "roles": "SDE;Analyst"}},
+		{Line: 2, Fields: map[string]string{"rollNumber": "R2", "cgpa": ""}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+}
+
+func TestReadJSONErrors(t *testing.T) {
+	tests := []struct {
+		name, input, want string
+	}{
+		{"not an array", `{"rollNumber": "R1"}`, "expected a JSON array"},
+		{"object value", `[{"rollNumber": {"id": 1}}]`, `element 1: field "rollNumber" must be a string`},
+		{"list of numbers", `[{}, {"roles": [1, 2]}]`, `element 2: field "roles" must be a list of strings`},
+	}
+	for _, tt := range tests {
+		if _, err := ReadJSON(strings.NewReader(tt.input), testFields, nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one mentioning %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestParser(t *testing.T) {
+	p := &Parser{Record: Record{Fields: map[string]string{
+		"salary": "12,00,000", "count": "3", "placed": "Y", "roles": " SDE ; ;Analyst|QA ",
+		"badSalary": "lots", "badCount": "2.5", "badPlaced": "maybe", "empty": "",
+	}}}
+
+	if got := p.Float("salary"); got != 1200000 {
+		t.Errorf("Float(salary) = %v", got)
+	}
+	if got := p.Int("count"); got != 3 {
+		t.Errorf("Int(count) = %v", got)
+	}
+	if !p.Bool("placed") {
+		t.Error("Bool(placed) = false")
+	}
+	if got, want := p.List("roles"), []string{"SDE", "Analyst", "QA"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List(roles) = %q, want %q", got, want)
+	}
+	if p.Float("empty") != 0 || p.Int("empty") != 0 || p.Bool("empty") || p.List("empty") != nil {
+		t.Error("empty value did not parse as the zero value")
+	}
+	if !p.Has("empty") || p.Has("missing") {
+		t.Error("Has does not tell present columns from missing ones")
+	}
+	if len(p.Errors) != 0 {
+		t.Fatalf("valid values produced errors: %+v", p.Errors)
+	}
+
+	p.Float("badSalary")
+	p.Int("badCount")
+	p.Bool("badPlaced")
+	want := []FieldError{
+		{"badSalary", `"lots" is not a number`},
+		{"badCount", `"2.5" is not a whole number`},
+		{"badPlaced", `"maybe" is not a yes/no value`},
+	}
+	if !reflect.DeepEqual(p.Errors, want) {
+		t.Errorf("errors = %+v, want %+v", p.Errors, want)
+	}
+	if !p.Failed("badCount") || p.Failed("count") {
+		t.Error("Failed does not match the recorded errors")
+	}
+}
+
+func TestReportAdd(t *testing.T) {
+	var report Report
+	for _, action := range []string{ActionCreate, ActionUpdate, ActionCreate, ActionError} {
+		report.Add(RowResult{Action: action})
+	}
+	if report.TotalRows != 4 || report.Created != 2 || report.Updated != 1 || report.Failed != 1 || len(report.Rows) != 4 {
+		t.Errorf("report = %+v", report)
+	}
+}
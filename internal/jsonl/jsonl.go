@@ -0,0 +1,70 @@
+// Package jsonl loads the append-only JSON Lines files behind the audit log, the eligibility change log and the
+// webhook subscriptions.
+// Each record is written as one line with a single write, so a crash can at worst leave the last line cut
+// short; Load repairs that case and reports damage anywhere else as corruption.
+package jsonl
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Repair describes what Load changed to make the file appendable again.
+type Repair struct {
+	DroppedBytes   int64 // Length of a torn last line that was truncated away.
+	AddedLineBreak bool  // The last line was complete but lacked its line break, which was added.
+}
+
+// Load calls decode with every line of f, in order. f must be open for reading and appending.
+// If the last line cannot be decoded it is taken to be a write cut short by a crash and truncated away;
+// a line that cannot be decoded anywhere else is returned as an error naming its line number.
+func Load(f *os.File, decode func(line []byte) error) (Repair, error) {
+	var repair Repair
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return repair, err
+	}
+	reader := bufio.NewReader(f)
+	var offset int64
+	var torn error // Decode error of the previous line, reported as corruption if another line follows it.
+	tornAt, tornLine := int64(0), 0
+	for line := 1; ; line++ {
+		data, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return repair, readErr
+		}
+		if len(data) == 0 {
+			break
+		}
+		if torn != nil {
+			return repair, fmt.Errorf("line %d is corrupt: %w", tornLine, torn)
+		}
+		complete := data[len(data)-1] == '\n'
+		if err := decode(bytes.TrimRight(data, "\r\n")); err != nil {
+			torn, tornAt, tornLine = err, offset, line
+		} else if !complete {
+			repair.AddedLineBreak = true
+		}
+		offset += int64(len(data))
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	switch {
+	case torn != nil:
+		repair.DroppedBytes = offset - tornAt
+		if err := f.Truncate(tornAt); err != nil {
+			return repair, fmt.Errorf("truncating torn line %d: %w", tornLine, err)
+		}
+	case repair.AddedLineBreak:
+		if _, err := f.Write([]byte{'\n'}); err != nil {
+			return repair, fmt.Errorf("completing the last line: %w", err)
+		}
+	default:
+		return repair, nil
+	}
+	return repair, f.Sync()
+}
@@ -0,0 +1,92 @@
+package jsonl
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID int `json:"id"`
+}
+
+// load writes content to a fresh file, loads it and returns the decoded IDs, the repair and the file afterwards.
+func load(t *testing.T, content string) ([]int, Repair, string, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var ids []int
+	repair, err := Load(f, func(line []byte) error {
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return err
+		}
+		ids = append(ids, r.ID)
+		return nil
+	})
+	after, readErr := os.ReadFile(path)
+	if readErr != nil {
+		t.Fatal(readErr)
+	}
+	return ids, repair, string(after), err
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		wantIDs     []int
+		wantDropped int64
+		wantFile    string
+	}{
+		{"empty", "", nil, 0, ""},
+		{"complete", "{\"id\":1}\n{\"id\":2}\n", []int{1, 2}, 0, "{\"id\":1}\n{\"id\":2}\n"},
+		{"torn last line", "{\"id\":1}\n{\"id\":", []int{1}, 6, "{\"id\":1}\n"},
+		{"garbage last line with break", "{\"id\":1}\nxx\n", []int{1}, 3, "{\"id\":1}\n"},
+		{"missing last line break", "{\"id\":1}\n{\"id\":2}", []int{1, 2}, 0, "{\"id\":1}\n{\"id\":2}\n"},
+		{"windows line breaks", "{\"id\":1}\r\n", []int{1}, 0, "{\"id\":1}\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids, repair, file, err := load(t, tt.content)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("decoded %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("decoded %v, want %v", ids, tt.wantIDs)
+				}
+			}
+			if repair.DroppedBytes != tt.wantDropped {
+				t.Errorf("DroppedBytes = %d, want %d", repair.DroppedBytes, tt.wantDropped)
+			}
+			if file != tt.wantFile {
+				t.Errorf("file afterwards = %q, want %q", file, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsCorruptionBeforeTheEnd(t *testing.T) {
+	content := "{\"id\":1}\n{\"id\n{\"id\":3}\n"
+	_, _, file, err := load(t, content)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("Load error = %v, want corruption on line 2", err)
+	}
+	if file != content {
+		t.Errorf("file was modified: %q", file)
+	}
+}
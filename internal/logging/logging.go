@@ -0,0 +1,91 @@
+// Package logging configures the process-wide log/slog logger to write JSON lines to stderr. Records logged with a
+// request's context carry the request ID assigned by chi's middleware.RequestID, so every line a request causes, in
+// handlers or in the eligibility engine, can be found by that ID.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// level is the minimum level logged. It can be changed at any time with SetLevel.
+var level = new(slog.LevelVar)
+
+func init() {
+	// Installed at init time so log lines from other packages' init functions, such as the initial data load, are
+	// JSON too. slog.SetDefault also routes the standard log package through this handler.
+	slog.SetDefault(slog.New(contextHandler{slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: level})}))
+}
+
+// SetLevel sets the minimum level logged: debug, info, warn or error.
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.TrimSpace(name))); err != nil {
+		return fmt.Errorf("invalid log level %q: must be debug, info, warn or error", name)
+	}
+	level.Set(l)
+	return nil
+}
+
+// contextHandler adds the request ID from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := middleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("requestId", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// Middleware logs one line per request once it has been served, replacing chi's text middleware.Logger.
+// It must run after middleware.RequestID. Server errors are logged at error level, everything else at info.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logLevel := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				logLevel = slog.LevelError
+			}
+			attrs := []slog.Attr{
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Int("bytes", ww.BytesWritten()),
+				slog.Float64("durationMs", float64(time.Since(start).Microseconds())/1000),
+				slog.String("remoteAddr", r.RemoteAddr),
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				attrs = append(attrs, slog.String("route", rctx.RoutePattern()))
+			}
+			slog.LogAttrs(r.Context(), logLevel, "request", attrs...)
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
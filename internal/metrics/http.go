@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+var (
+	httpRequests = NewCounterVec("placement_http_requests_total",
+		"HTTP requests served, by method, route pattern and status code.", "method", "route", "status")
+	httpDuration = NewHistogramVec("placement_http_request_duration_seconds",
+		"Time taken to serve HTTP requests, by method and route pattern.", DefaultBuckets, "method", "route")
+)
+
+// Middleware counts and times every request. Requests are labelled with the matched chi route pattern, e.g.
+// "/students/{studentID}", rather than the raw path, so IDs do not create a series each; requests that match no
+// route share the "unmatched" label.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		defer func() {
+			route := "unmatched"
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK // Nothing was written; net/http sends 200.
+			}
+			httpRequests.Inc(r.Method, route, strconv.Itoa(status))
+			httpDuration.Observe(time.Since(start).Seconds(), r.Method, route)
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
@@ -0,0 +1,257 @@
+// Package metrics collects counters, gauges and histograms and serves them in the Prometheus text exposition
+// format (version 0.0.4) at GET /metrics. It is deliberately small: metric families are registered once at start-up
+// and series are created on first use, so the output can be checked with nothing more than curl.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// family is one metric name with its help text and every labelled series.
+type family interface {
+	name() string
+	write(w *bufio.Writer)
+}
+
+var (
+	registryMutex sync.Mutex
+	registry      []family
+)
+
+func register(f family) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	for _, existing := range registry {
+		if existing.name() == f.name() {
+			panic("metrics: duplicate metric " + f.name())
+		}
+	}
+	registry = append(registry, f)
+}
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler(w http.ResponseWriter, r *http.Request) {
+	registryMutex.Lock()
+	families := slices.Clone(registry)
+	registryMutex.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	out := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(out)
+	}
+	out.Flush()
+}
+
+// CounterVec is a family of monotonically increasing counters distinguished by label values.
+type CounterVec struct {
+	metricName, help string
+	labels           []string
+
+	mutex  sync.Mutex
+	series map[string]float64 // Keyed by the formatted label set.
+}
+
+// NewCounterVec registers a counter family with the given label names.
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{metricName: name, help: help, labels: labels, series: map[string]float64{}}
+	register(c)
+	return c
+}
+
+// Inc adds one to the counter with the given label values, which must match the family's label names in order.
+func (c *CounterVec) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the counter with the given label values.
+func (c *CounterVec) Add(v float64, values ...string) {
+	key := labelSet(c.labels, values)
+	c.mutex.Lock()
+	c.series[key] += v
+	c.mutex.Unlock()
+}
+
+func (c *CounterVec) name() string { return c.metricName }
+
+func (c *CounterVec) write(w *bufio.Writer) {
+	header(w, c.metricName, c.help, "counter")
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		fmt.Fprintf(w, "%s%s %s\n", c.metricName, key, formatValue(c.series[key]))
+	}
+}
+
+// GaugeFunc is a gauge whose value is read when metrics are scraped.
+type GaugeFunc struct {
+	metricName, help string
+	value            func() float64
+}
+
+// NewGaugeFunc registers a gauge that reports value().
+func NewGaugeFunc(name, help string, value func() float64) *GaugeFunc {
+	g := &GaugeFunc{metricName: name, help: help, value: value}
+	register(g)
+	return g
+}
+
+func (g *GaugeFunc) name() string { return g.metricName }
+
+func (g *GaugeFunc) write(w *bufio.Writer) {
+	header(w, g.metricName, g.help, "gauge")
+	fmt.Fprintf(w, "%s %s\n", g.metricName, formatValue(g.value()))
+}
+
+// CounterFunc is a counter whose value is read when metrics are scraped, for counts kept by another package.
+type CounterFunc struct {
+	metricName, help string
+	value            func() float64
+}
+
+// NewCounterFunc registers a counter that reports value(), which must never decrease.
+func NewCounterFunc(name, help string, value func() float64) *CounterFunc {
+	c := &CounterFunc{metricName: name, help: help, value: value}
+	register(c)
+	return c
+}
+
+func (c *CounterFunc) name() string { return c.metricName }
+
+func (c *CounterFunc) write(w *bufio.Writer) {
+	header(w, c.metricName, c.help, "counter")
+	fmt.Fprintf(w, "%s %s\n", c.metricName, formatValue(c.value()))
+}
+
+// DefaultBuckets are upper bounds, in seconds, suited to HTTP request latencies.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// HistogramVec is a family of histograms distinguished by label values.
+type HistogramVec struct {
+	metricName, help string
+	labels           []string
+	buckets          []float64 // Sorted upper bounds; +Inf is implicit.
+
+	mutex  sync.Mutex
+	series map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // Per bucket, not cumulative; the last element counts observations above every bound.
+	sum    float64
+	count  uint64
+}
+
+// NewHistogramVec registers a histogram family with the given bucket upper bounds and label names.
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{metricName: name, help: help, labels: labels, buckets: sortedBuckets(buckets),
+		series: map[string]*histogram{}}
+	register(h)
+	return h
+}
+
+// Observe records v in the histogram with the given label values.
+func (h *HistogramVec) Observe(v float64, values ...string) {
+	key := labelSet(h.labels, values)
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	i, _ := slices.BinarySearch(h.buckets, v) // First bound >= v; bounds are inclusive.
+	s.counts[i]++
+	s.sum += v
+	s.count++
+}
+
+func (h *HistogramVec) name() string { return h.metricName }
+
+func (h *HistogramVec) write(w *bufio.Writer) {
+	header(w, h.metricName, h.help, "histogram")
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, withLabel(key, "le", formatValue(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.metricName, withLabel(key, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.metricName, key, formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.metricName, key, s.count)
+	}
+}
+
+// labelEscaper escapes label values; the text format only knows these three escapes.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func header(w *bufio.Writer, name, help, kind string) {
+	help = strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// labelSet formats label names and values as {a="x",b="y"}, or "" if there are none.
+func labelSet(names, values []string) string {
+	if len(names) != len(values) {
+		panic(fmt.Sprintf("metrics: got %d label values for labels %v", len(values), names))
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// withLabel appends one more label to a formatted label set.
+func withLabel(set, name, value string) string {
+	label := fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(value))
+	if set == "" {
+		return "{" + label + "}"
+	}
+	return set[:len(set)-1] + "," + label + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	sorted := slices.Clone(buckets)
+	slices.Sort(sorted)
+	return sorted
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Families for the golden test. They are registered like any other, so their names must stay unique.
+var (
+	testRequests = NewCounterVec("test_requests_total", "Requests by route and status.\nSecond line with a \\ backslash.", "route", "status")
+	testRestarts = NewCounterVec("test_restarts_total", "Restarts, without labels.")
+	testDuration = NewHistogramVec("test_duration_seconds", "Durations.", []float64{1, 0.1}, "route")
+	testQueue    = NewGaugeFunc("test_queue_length", "Queued items.", func() float64 { return 2.5 })
+	testHits     = NewCounterFunc("test_hits_total", "Hits counted elsewhere.", func() float64 { return 42 })
+	testInfinite = NewGaugeFunc("test_infinite", "Values without a finite form.", func() float64 { return math.Inf(1) })
+)
+
+func TestExpositionGolden(t *testing.T) {
+	testRequests.Inc("/students", "200")
+	testRequests.Inc("/students", "200")
+	testRequests.Add(0.5, "/students/{studentID}", "404")
+	testRequests.Inc(`/odd"route\with`+"\n", "500")
+	testRestarts.Inc()
+	testDuration.Observe(0.05, "/students")
+	testDuration.Observe(0.1, "/students") // Bounds are inclusive.
+	testDuration.Observe(3, "/students")
+	testDuration.Observe(0.5, "/companies")
+
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	for _, f := range []family{testRequests, testRestarts, testDuration, testQueue, testHits, testInfinite} {
+		f.write(w)
+	}
+	w.Flush()
+
+	golden := filepath.Join("testdata", "exposition.golden")
+	if *update {
+		if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if got := out.String(); got != string(want) {
+		t.Errorf("exposition differs from %s:\n--- got ---\n%s--- want ---\n%s", golden, got, want)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if got := rec.Header().Get("Content-Type"); got != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{"# TYPE test_hits_total counter\ntest_hits_total 42\n", "# TYPE placement_students gauge\n"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("response does not contain %q", want)
+		}
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name did not panic")
+		}
+	}()
+	NewGaugeFunc("test_queue_length", "Again.", func() float64 { return 0 })
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("wrong number of label values did not panic")
+		}
+	}()
+	testRequests.Inc("/students")
+}
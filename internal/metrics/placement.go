@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+var (
+	eligibilityChecks = NewCounterVec("placement_eligibility_checks_total",
+		"Eligibility checks performed, including background re-evaluations, by outcome (eligible or ineligible).", "outcome")
+	eligibilityBlocks = NewCounterVec("placement_eligibility_blocks_total",
+		"Ineligible verdicts by blocking policy. A check blocked by several policies counts once for each.", "policy")
+	policyChanges = NewCounterVec("placement_policy_changes_total",
+		"Changes to the active policy configuration since the server started.")
+)
+
+func init() {
+	storage.OnPolicyChange(func() { policyChanges.Inc() })
+
+	NewGaugeFunc("placement_students", "Students on record.", func() float64 {
+		storage.PlacementStatsMutex.RLock()
+		defer storage.PlacementStatsMutex.RUnlock()
+		return float64(storage.CachedTotalStudents)
+	})
+	NewGaugeFunc("placement_students_placed", "Students with at least one recorded offer.", func() float64 {
+		storage.PlacementStatsMutex.RLock()
+		defer storage.PlacementStatsMutex.RUnlock()
+		return float64(storage.CachedPlacedStudentsCount)
+	})
+	NewGaugeFunc("placement_percentage", "Placed students as a percentage of all students.", func() float64 {
+		storage.PlacementStatsMutex.RLock()
+		defer storage.PlacementStatsMutex.RUnlock()
+		if storage.CachedTotalStudents == 0 {
+			return 0
+		}
+		return float64(storage.CachedPlacedStudentsCount) / float64(storage.CachedTotalStudents) * 100
+	})
+	NewGaugeFunc("placement_companies", "Companies on record.", func() float64 {
+		storage.CompaniesMutex.RLock()
+		defer storage.CompaniesMutex.RUnlock()
+		return float64(len(storage.Companies))
+	})
+}
+
+// ObserveEligibility counts an eligibility verdict and the policies that blocked it.
+func ObserveEligibility(result models.EligibilityResult) {
+	if result.IsEligible {
+		eligibilityChecks.Inc("eligible")
+		return
+	}
+	eligibilityChecks.Inc("ineligible")
+	blocked := map[string]bool{}
+	for _, d := range result.Decisions {
+		if d.Outcome == models.DecisionBlocked && !blocked[d.Policy] {
+			blocked[d.Policy] = true
+			eligibilityBlocks.Inc(d.Policy)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Application statuses. An application moves from applied to in_progress when the student is shortlisted for
+// a drive's first round, and ends as offered (final round cleared, or an offer recorded directly) or rejected.
+const (
+	ApplicationStatusApplied    = "applied"
+	ApplicationStatusInProgress = "in_progress"
+	ApplicationStatusRejected   = "rejected"
+	ApplicationStatusOffered    = "offered"
+)
+
+// ValidApplicationStatus reports whether status is one of the application statuses.
+func ValidApplicationStatus(status string) bool {
+	switch status {
+	case ApplicationStatusApplied, ApplicationStatusInProgress, ApplicationStatusRejected, ApplicationStatusOffered:
+		return true
+	}
+	return false
+}
+
+// Round outcomes recorded in RoundResult.
+const (
+	RoundOutcomeCleared  = "cleared"
+	RoundOutcomeRejected = "rejected"
+)
+
+// ScreeningRound names the stage before a drive's first round; its result is recorded when the first shortlist is uploaded.
+const ScreeningRound = "screening"
+
+// RoundResult is a student's outcome in one interview round.
+type RoundResult struct {
+	Round      string    `json:"round"`
+	Outcome    string    `json:"outcome"` // cleared or rejected
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// Application records a student applying to a company after passing the eligibility check.
+type Application struct {
+	ID           int           `json:"id"`
+	StudentID    int           `json:"studentId"`
+	CompanyID    string        `json:"companyId"`
+	DriveID      int           `json:"driveId,omitempty"` // The drive registered for; zero for companies without scheduled drives.
+	Status       string        `json:"status"`
+	CurrentRound string        `json:"currentRound,omitempty"` // The round the student is waiting on while in_progress.
+	RoundResults []RoundResult `json:"roundResults,omitempty"`
+	AppliedAt    time.Time     `json:"appliedAt"`
+	Reasons      []string      `json:"reasons"` // Eligibility reasons at the time of applying.
+}
+
+// PendingStage returns the stage of drive d whose result the application is waiting on: -1 for screening
+// (applied but not yet shortlisted for the first round), otherwise the index of CurrentRound in d.Rounds.
+// ok is false once the application has been rejected or offered, or if its round is no longer part of the drive.
+func (a Application) PendingStage(d Drive) (stage int, ok bool) {
+	switch a.Status {
+	case ApplicationStatusApplied:
+		return -1, len(d.Rounds) > 0
+	case ApplicationStatusInProgress:
+		stage = d.RoundIndex(a.CurrentRound)
+		return stage, stage >= 0
+	}
+	return 0, false
+}
+
+// Advance records outcome for the application's pending stage of drive d and moves it on: a rejection ends the
+// application, clearing a round moves the student to the next one, and clearing the final round makes it offered.
+func (a *Application) Advance(d Drive, outcome string, at time.Time) error {
+	stage, ok := a.PendingStage(d)
+	if !ok {
+		return fmt.Errorf("application is %s and has no pending round", a.Status)
+	}
+	round := ScreeningRound
+	if stage >= 0 {
+		round = d.Rounds[stage].Name
+	}
+	switch outcome {
+	case RoundOutcomeRejected:
+		a.Status, a.CurrentRound = ApplicationStatusRejected, ""
+	case RoundOutcomeCleared:
+		if stage+1 < len(d.Rounds) {
+			a.Status, a.CurrentRound = ApplicationStatusInProgress, d.Rounds[stage+1].Name
+		} else {
+			a.Status, a.CurrentRound = ApplicationStatusOffered, ""
+		}
+	default:
+		return fmt.Errorf("outcome must be %s or %s", RoundOutcomeCleared, RoundOutcomeRejected)
+	}
+	a.RoundResults = append(a.RoundResults, RoundResult{Round: round, Outcome: outcome, RecordedAt: at})
+	return nil
+}
@@ -5,4 +5,6 @@ type Company struct {
 	ID      string  `json:"id"`
 	Name    string  `json:"name"`
 	OfferedSalary float64 `json:"offeredSalary"`
+	Roles   []string `json:"roles,omitempty"` // Job roles offered in the drive, e.g. "SDE", "Data Analyst".
+	Version int64 `json:"version"` // Incremented on every change; used for ETag/If-Match.
 }
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Drive is a company's visit to campus. Students register for a drive by applying while its registration window is open.
+type Drive struct {
+	ID                   int         `json:"id"`
+	CompanyID            string      `json:"companyId"`
+	Roles                []string    `json:"roles,omitempty"`
+	RegistrationOpensAt  time.Time   `json:"registrationOpensAt"`
+	RegistrationClosesAt time.Time   `json:"registrationClosesAt"`
+	TestDate             *time.Time  `json:"testDate,omitempty"`
+	InterviewDates       []time.Time `json:"interviewDates,omitempty"`
+	Venue                string      `json:"venue,omitempty"`
+	Rounds               []Round     `json:"rounds,omitempty"` // Interview rounds in the order candidates go through them.
+	Version              int64       `json:"version"`          // Incremented on every change; used for ETag/If-Match.
+}
+
+// Round is one selection round of a drive, e.g. "Aptitude", "Technical" or "HR".
+type Round struct {
+	Name string `json:"name"`
+}
+
+// RoundIndex returns the position of the named round (compared case-insensitively), or -1 if the drive has no such round.
+func (d Drive) RoundIndex(name string) int {
+	for i, r := range d.Rounds {
+		if strings.EqualFold(r.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// RegistrationOpen reports whether at falls inside the registration window [opens, closes).
+func (d Drive) RegistrationOpen(at time.Time) bool {
+	return !at.Before(d.RegistrationOpensAt) && at.Before(d.RegistrationClosesAt)
+}
@@ -3,6 +3,7 @@ package models
 // PolicyConfig represents the structure for all configurable policies
 // This is a flexible design to enable/disable and store values for each policy.
 type PolicyConfig struct {
+	Version int64 `json:"version"` // Server-managed; incremented on every change and used for ETag/If-Match.
 	MaximumCompanies struct {
 		Enabled bool `json:"enabled"`
 		MaxN    int  `json:"maxN"` // 0 = no applications, N = max N applications [cite: 4]
@@ -28,6 +29,24 @@ type PolicyConfig struct {
 		L2ThresholdAmount     float64 `json:"l2ThresholdAmount"`     // middle tier [cite: 6]
 		RequiredHikePercentage float64 `json:"requiredHikePercentage"` // for L2 students [cite: 6]
 	} `json:"offerCategory"`
+	CustomRules []CustomRule `json:"customRules,omitempty"` // Evaluated in order after the built-in policies.
+}
+
+// Custom rule effects, applied when a rule's condition evaluates to true.
+const (
+	RuleEffectBlock    = "block"    // Makes the student ineligible.
+	RuleEffectAllow    = "allow"    // Adds a supporting reason without changing eligibility.
+	RuleEffectOverride = "override" // Makes the student eligible, discarding earlier blocking reasons (like the Dream Company Policy).
+)
+
+// CustomRule is a named eligibility condition written in the rule expression language (see internal/rules),
+// e.g. "student.isPlaced && company.offeredSalary < 1.5 * student.currentSalary".
+type CustomRule struct {
+	Name      string `json:"name"`
+	Enabled   bool   `json:"enabled"`
+	Condition string `json:"condition"`
+	Effect    string `json:"effect"`            // block, allow or override
+	Message   string `json:"message,omitempty"` // Optional explanation included in the eligibility reason.
 }
 
 // EligibilityResult represents the output for each student [cite: 10]
@@ -39,4 +58,32 @@ type EligibilityResult struct {
 	IsEligible      bool     `json:"isEligible"`
 	Reasons         []string `json:"reasons"` // List of reasons supporting the decision [cite: 10]
 	PolicySpecifics string   `json:"policySpecifics,omitempty"` // Policy-specific details where applicable [cite: 10]
+	Decisions       []PolicyDecision `json:"decisions"` // Machine-readable form of every policy that took part, including overridden blocks.
+}
+
+// Names of the built-in policies, matching the JSON names of their PolicyConfig sections.
+// Custom rules are reported as CustomRulePolicyPrefix followed by the rule name.
+const (
+	PolicyMaximumCompanies    = "maximumCompanies"
+	PolicyDreamOffer          = "dreamOffer"
+	PolicyDreamCompany        = "dreamCompany"
+	PolicyCGPAThreshold       = "cgpaThreshold"
+	PolicyPlacementPercentage = "placementPercentage"
+	PolicyOfferCategory       = "offerCategory"
+	CustomRulePolicyPrefix    = "customRule:"
+)
+
+// Decision outcomes. A block that a later override discarded is reported as overridden.
+const (
+	DecisionBlocked    = "blocked"
+	DecisionAllowed    = "allowed"
+	DecisionOverridden = "overridden"
+)
+
+// PolicyDecision records one policy's contribution to an eligibility result.
+// Code is a stable identifier for the specific reason, e.g. "l2_insufficient_hike".
+type PolicyDecision struct {
+	Policy  string `json:"policy"`
+	Code    string `json:"code"`
+	Outcome string `json:"outcome"`
 }
@@ -10,5 +10,10 @@ type Student struct {
 	NumCompaniesApplied    int     `json:"companiesApplied"`
 	DreamOfferAmount       float64 `json:"dreamOffer"`
 	DreamCompanyName       string  `json:"dreamCompany"`
+	Department             string  `json:"department,omitempty"`
+	RollNumber             string  `json:"rollNumber,omitempty"` // Institute roll number; unique when set, used as the bulk import key.
+	Email                  string  `json:"email,omitempty"` // Where notifications are sent; students without one are not notified.
+	PlacedCompanyID        string  `json:"placedCompanyId,omitempty"` // Company of the offer recorded through the API, if any.
+	Version                int64   `json:"version"` // Incremented on every change; used for ETag/If-Match.
 	// CurrentOfferCategory   string  `json:"currentOfferCategory"` // L1, L2, L3 derived from CurrentSalary and Policy
 }
@@ -0,0 +1,281 @@
+// Package notify emails students about changes that affect them: a new drive they are eligible for, a policy
+// change that blocks them, or a recorded offer. Change events are turned into messages and sent from background
+// goroutines, so API handlers never wait on the mail server.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// Notification kinds. Students can mute each kind in their preferences.
+const (
+	KindDriveEligible = "driveEligible"
+	KindPolicyBlocked = "policyBlocked"
+	KindOfferRecorded = "offerRecorded"
+)
+
+// Kinds lists every notification kind.
+var Kinds = []string{KindDriveEligible, KindPolicyBlocked, KindOfferRecorded}
+
+// Message is a rendered notification for one student.
+type Message struct {
+	Kind      string
+	StudentID int
+	To        string
+	Subject   string
+	Body      string
+}
+
+// Preferences are a student's notification settings. Students without a stored record get every kind.
+type Preferences struct {
+	StudentID int      `json:"studentId"`
+	Enabled   bool     `json:"enabled"`
+	Muted     []string `json:"muted"` // Kinds the student does not want.
+}
+
+// Wants reports whether the preferences allow a notification of the given kind.
+func (p Preferences) Wants(kind string) bool {
+	if !p.Enabled {
+		return false
+	}
+	for _, muted := range p.Muted {
+		if muted == kind {
+			return false
+		}
+	}
+	return true
+}
+
+// Queue sizes. Events and messages beyond these are dropped with a warning rather than blocking the API.
+const (
+	triggerQueueSize = 256
+	outboxSize       = 1024
+	sendAttempts     = 3
+)
+
+var (
+	preferencesMutex sync.RWMutex
+	preferences      = map[int]Preferences{}
+	preferencesPath  string // File the preferences are saved to; empty keeps them in memory only.
+
+	triggers = make(chan events.Event, triggerQueueSize)
+	outbox   = make(chan Message, outboxSize)
+
+	// lastPolicy is the policy configuration before the most recent policy event, for finding newly blocked students.
+	lastPolicy models.PolicyConfig
+)
+
+// PreferencesFor returns the student's preferences, defaulting to every kind enabled.
+func PreferencesFor(studentID int) Preferences {
+	preferencesMutex.RLock()
+	defer preferencesMutex.RUnlock()
+	if p, ok := preferences[studentID]; ok {
+		return p
+	}
+	return Preferences{StudentID: studentID, Enabled: true, Muted: []string{}}
+}
+
+// OpenPreferences loads the preferences saved in the JSON file at path and saves every later change there.
+// A missing file means no student has changed their preferences yet.
+func OpenPreferences(path string) error {
+	loaded := map[int]Preferences{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading notification preferences %s: %w", path, err)
+	}
+	if err == nil {
+		var saved []Preferences
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return fmt.Errorf("notification preferences %s are corrupt: %w", path, err)
+		}
+		for _, p := range saved {
+			loaded[p.StudentID] = p
+		}
+	}
+
+	preferencesMutex.Lock()
+	defer preferencesMutex.Unlock()
+	preferences = loaded
+	preferencesPath = path
+	return nil
+}
+
+// SetPreferences stores the student's preferences and returns the previous ones. Once OpenPreferences has been
+// called the change is saved first; if saving fails, nothing changes and the error is returned.
+func SetPreferences(p Preferences) (Preferences, error) {
+	if p.Muted == nil {
+		p.Muted = []string{}
+	}
+	preferencesMutex.Lock()
+	defer preferencesMutex.Unlock()
+	previous, ok := preferences[p.StudentID]
+	if !ok {
+		previous = Preferences{StudentID: p.StudentID, Enabled: true, Muted: []string{}}
+	}
+	preferences[p.StudentID] = p
+	if err := savePreferences(); err != nil {
+		if ok {
+			preferences[p.StudentID] = previous
+		} else {
+			delete(preferences, p.StudentID)
+		}
+		return previous, err
+	}
+	return previous, nil
+}
+
+// savePreferences replaces the preferences file with the stored preferences, ordered by student. The file is
+// written under a temporary name and renamed, so a crash leaves either the old or the new version.
+// preferencesMutex must be held for writing.
+func savePreferences() error {
+	if preferencesPath == "" {
+		return nil
+	}
+	saved := make([]Preferences, 0, len(preferences))
+	for _, p := range preferences {
+		saved = append(saved, p)
+	}
+	sort.Slice(saved, func(i, j int) bool { return saved[i].StudentID < saved[j].StudentID })
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := preferencesPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("saving notification preferences: %w", err)
+	}
+	if err := os.Rename(tmp, preferencesPath); err != nil {
+		return fmt.Errorf("saving notification preferences: %w", err)
+	}
+	return nil
+}
+
+// Start begins turning published events into notifications sent through transport.
+func Start(transport Transport) {
+	lastPolicy = storage.ActivePolicy()
+	events.AddListener(func(e events.Event) {
+		select {
+		case triggers <- e:
+		default:
+			slog.Warn("notification queue full; no notifications for event", "eventId", e.ID, "eventType", e.Type)
+		}
+	})
+	go func() {
+		for e := range triggers {
+			handle(e)
+		}
+	}()
+	go func() {
+		for m := range outbox {
+			send(transport, m)
+		}
+	}()
+}
+
+// handle works out who to notify about an event.
+func handle(e events.Event) {
+	switch e.Type {
+	case audit.ActionDriveCreate:
+		var drive models.Drive
+		if json.Unmarshal(e.Data, &drive) == nil {
+			notifyNewDrive(drive)
+		}
+	case audit.ActionOfferRecord:
+		var student models.Student
+		if json.Unmarshal(e.Data, &student) == nil {
+			notifyOffer(student)
+		}
+	default:
+		if e.Topic == events.TopicPolicy {
+			var config models.PolicyConfig
+			if json.Unmarshal(e.Data, &config) == nil {
+				notifyPolicyChange(lastPolicy, config)
+				lastPolicy = config
+			}
+		}
+	}
+}
+
+// notifyNewDrive tells every student eligible for the drive's company that registration is open or upcoming.
+func notifyNewDrive(drive models.Drive) {
+	if !time.Now().Before(drive.RegistrationClosesAt) {
+		return
+	}
+	company, found := storage.FindCompanyByID(drive.CompanyID)
+	if !found {
+		return
+	}
+	for _, student := range storage.AllStudents() {
+		if eligibility.PerformEligibilityCheck(context.Background(), student, company).IsEligible {
+			queue(KindDriveEligible, student, templateData{Student: student, Company: company, Drive: drive})
+		}
+	}
+}
+
+// notifyOffer congratulates the student on the offer from the company they were placed at.
+func notifyOffer(student models.Student) {
+	company, _ := storage.FindCompanyByID(student.PlacedCompanyID)
+	queue(KindOfferRecorded, student, templateData{Student: student, Company: company, Salary: student.CurrentSalary})
+}
+
+// notifyPolicyChange tells each student which companies they were eligible for under before but not under after.
+func notifyPolicyChange(before, after models.PolicyConfig) {
+	companies := storage.AllCompanies()
+	for _, student := range storage.AllStudents() {
+		var blocked []models.EligibilityResult
+		for _, company := range companies {
+			if !eligibility.CheckWithPolicy(student, company, before).IsEligible {
+				continue
+			}
+			if result := eligibility.CheckWithPolicy(student, company, after); !result.IsEligible {
+				blocked = append(blocked, result)
+			}
+		}
+		if len(blocked) > 0 {
+			queue(KindPolicyBlocked, student, templateData{Student: student, Blocked: blocked})
+		}
+	}
+}
+
+// queue renders a notification and hands it to the sender, if the student has an address and wants this kind.
+func queue(kind string, student models.Student, data templateData) {
+	if student.Email == "" || !PreferencesFor(student.ID).Wants(kind) {
+		return
+	}
+	subject, body, err := render(kind, data)
+	if err != nil {
+		slog.Warn("could not render notification", "kind", kind, "studentId", student.ID, "error", err)
+		return
+	}
+	m := Message{Kind: kind, StudentID: student.ID, To: student.Email, Subject: subject, Body: body}
+	select {
+	case outbox <- m:
+	default:
+		slog.Warn("notification outbox full; notification dropped", "kind", kind, "studentId", student.ID)
+	}
+}
+
+// send delivers m, retrying transient failures a few times.
+func send(transport Transport, m Message) {
+	var err error
+	for attempt := 1; attempt <= sendAttempts; attempt++ {
+		if err = transport.Send(m); err == nil {
+			return
+		}
+		time.Sleep(time.Duration(attempt) * time.Second)
+	}
+	slog.Warn("could not send notification", "kind", m.Kind, "studentId", m.StudentID, "attempts", sendAttempts, "error", err)
+}
@@ -0,0 +1,293 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestPreferencesWants(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs Preferences
+		kind  string
+		want  bool
+	}{
+		{"default", Preferences{Enabled: true}, KindOfferRecorded, true},
+		{"disabled", Preferences{Enabled: false}, KindOfferRecorded, false},
+		{"muted kind", Preferences{Enabled: true, Muted: []string{KindDriveEligible}}, KindDriveEligible, false},
+		{"other kind muted", Preferences{Enabled: true, Muted: []string{KindDriveEligible}}, KindPolicyBlocked, true},
+		{"disabled overrides unmuted", Preferences{Enabled: false, Muted: []string{}}, KindPolicyBlocked, false},
+	}
+	for _, tt := range tests {
+		if got := tt.prefs.Wants(tt.kind); got != tt.want {
+			t.Errorf("%s: Wants(%q) = %v, want %v", tt.name, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestPreferencesPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	if err := OpenPreferences(path); err != nil {
+		t.Fatalf("OpenPreferences on a missing file: %v", err)
+	}
+	t.Cleanup(func() { preferencesPath = ""; preferences = map[int]Preferences{} })
+
+	previous, err := SetPreferences(Preferences{StudentID: 7, Enabled: true, Muted: []string{KindDriveEligible}})
+	if err != nil {
+		t.Fatalf("SetPreferences: %v", err)
+	}
+	if !previous.Enabled || len(previous.Muted) != 0 {
+		t.Errorf("previous preferences = %+v, want the default", previous)
+	}
+	if _, err := SetPreferences(Preferences{StudentID: 3, Enabled: false}); err != nil {
+		t.Fatalf("SetPreferences: %v", err)
+	}
+
+	// Reopening stands in for a restart.
+	if err := OpenPreferences(path); err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	if got := PreferencesFor(7); got.Wants(KindDriveEligible) || !got.Wants(KindOfferRecorded) {
+		t.Errorf("student 7 preferences after reopening = %+v", got)
+	}
+	if got := PreferencesFor(3); got.Enabled {
+		t.Errorf("student 3 preferences after reopening = %+v, want disabled", got)
+	}
+	if got := PreferencesFor(99); !got.Enabled {
+		t.Errorf("student without saved preferences = %+v, want the default", got)
+	}
+
+	var saved []Preferences
+	data, _ := os.ReadFile(path)
+	if err := json.Unmarshal(data, &saved); err != nil || len(saved) != 2 || saved[0].StudentID != 3 {
+		t.Errorf("saved file %s is not the two preferences ordered by student (%v)", data, err)
+	}
+}
+
+func TestSetPreferencesKeepsOldValueWhenSavingFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "preferences.json")
+	if err := OpenPreferences(path); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { preferencesPath = ""; preferences = map[int]Preferences{} })
+	if _, err := SetPreferences(Preferences{StudentID: 1, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	preferencesPath = filepath.Join(dir, "missing", "preferences.json")
+	if _, err := SetPreferences(Preferences{StudentID: 1, Enabled: false}); err == nil {
+		t.Fatal("SetPreferences succeeded without a writable file")
+	}
+	if !PreferencesFor(1).Enabled {
+		t.Error("failed change took effect")
+	}
+	if _, err := SetPreferences(Preferences{StudentID: 2, Enabled: false}); err == nil {
+		t.Fatal("SetPreferences succeeded without a writable file")
+	}
+	if !PreferencesFor(2).Enabled {
+		t.Error("failed change for a new student took effect")
+	}
+}
+
+func TestOpenPreferencesRejectsCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "preferences.json")
+	if err := os.WriteFile(path, []byte("[{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := OpenPreferences(path); err == nil {
+		t.Fatal("OpenPreferences accepted a corrupt file")
+	}
+}
+
+func TestRender(t *testing.T) {
+	student := models.Student{ID: 1, FullName: "Asha Rao", Email: "asha@example.edu"}
+	company := models.Company{ID: "C001", Name: "Acme"}
+	closes := time.Date(2025, 3, 14, 17, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		kind        string
+		data        templateData
+		wantSubject string
+		wantBody    []string
+	}{
+		{KindDriveEligible, templateData{Student: student, Company: company,
+			Drive: models.Drive{Roles: []string{"SDE", "Analyst"}, Venue: "Hall A", RegistrationClosesAt: closes}},
+			"New placement drive: Acme",
+			[]string{"Hi Asha Rao,", "Roles: SDE, Analyst", "Registration closes: Fri 14 Mar 2025 17:00 UTC", "Venue: Hall A"}},
+		{KindPolicyBlocked, templateData{Student: student, Blocked: []models.EligibilityResult{
+			{CompanyName: "Acme", Reasons: []string{"CGPA below 8.0.", "Already placed."}}}},
+			"A placement policy change affects your eligibility",
+			[]string{"- Acme: CGPA below 8.0. Already placed."}},
+		{KindOfferRecorded, templateData{Student: student, Company: company, Salary: 1250000},
+			"Congratulations on your offer from Acme",
+			[]string{"Your offer from Acme at 1250000.00 has been recorded."}},
+	}
+	for _, tt := range tests {
+		subject, body, err := render(tt.kind, tt.data)
+		if err != nil {
+			t.Errorf("%s: %v", tt.kind, err)
+			continue
+		}
+		if subject != tt.wantSubject {
+			t.Errorf("%s: subject %q, want %q", tt.kind, subject, tt.wantSubject)
+		}
+		for _, want := range tt.wantBody {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: body does not contain %q:\n%s", tt.kind, want, body)
+			}
+		}
+	}
+}
+
+func TestRenderOmitsEmptyDriveDetails(t *testing.T) {
+	_, body, err := render(KindDriveEligible, templateData{Company: models.Company{Name: "Acme"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(body, "Roles:") || strings.Contains(body, "Venue:") {
+		t.Errorf("body mentions missing roles or venue:\n%s", body)
+	}
+}
+
+func TestRenderSubjectIsOneLine(t *testing.T) {
+	subject, _, err := render(KindOfferRecorded, templateData{Company: models.Company{Name: "Acme\r\nBcc: everyone@example.edu"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.ContainsAny(subject, "\r\n") {
+		t.Errorf("subject contains a line break: %q", subject)
+	}
+}
+
+func TestRenderUnknownKind(t *testing.T) {
+	if _, _, err := render("carrierPigeon", templateData{}); err == nil {
+		t.Error("render accepted an unknown kind")
+	}
+}
+
+func TestFormat(t *testing.T) {
+	m := Message{Kind: KindOfferRecorded, StudentID: 1, To: "asha@example.edu", Subject: "Hello", Body: "Line one\nLine two\r\n"}
+	msg, err := format("placements@example.edu", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(msg)
+
+	header, body, found := strings.Cut(got, "\r\n\r\n")
+	if !found {
+		t.Fatalf("no blank line between header and body:\n%q", got)
+	}
+	for _, want := range []string{
+		"From: placements@example.edu", "To: asha@example.edu", "Subject: Hello", "MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8", "X-Placement-Notification: offerRecorded",
+	} {
+		if !strings.Contains(header+"\r\n", want+"\r\n") {
+			t.Errorf("header does not contain %q:\n%s", want, header)
+		}
+	}
+	if !strings.Contains(header, "\r\nDate: ") {
+		t.Errorf("header has no Date:\n%s", header)
+	}
+	if body != "Line one\r\nLine two\r\n\r\n" {
+		t.Errorf("body = %q, want CRLF line endings", body)
+	}
+	if strings.Contains(strings.ReplaceAll(got, "\r\n", ""), "\n") {
+		t.Error("message contains a bare line feed")
+	}
+}
+
+func TestFormatHeaders(t *testing.T) {
+	tests := []struct {
+		name, to, subject string
+		want              []string
+	}{
+		{"injected header", "asha@example.edu", "Offer from Acme\r\nBcc: x@example.com",
+			[]string{"To: asha@example.edu", "Subject: Offer from Acme Bcc: x@example.com"}},
+		{"injected body", "asha@example.edu", "Acme\n\nYou have won", []string{"Subject: Acme You have won"}},
+		{"other control characters", "asha@example.edu", "Acme\x00\tLtd\x1b", []string{"Subject: Acme Ltd"}},
+		{"non-ASCII subject", "asha@example.edu", "Offer from Café Ltd", []string{"Subject: =?utf-8?q?Offer_from_Caf=C3=A9_Ltd?="}},
+		{"named recipient", "Asha Rao <asha@example.edu>", "Hello", []string{`To: "Asha Rao" <asha@example.edu>`}},
+	}
+	for _, tt := range tests {
+		msg, err := format("placements@example.edu", Message{Kind: KindOfferRecorded, To: tt.to, Subject: tt.subject, Body: "Body"})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		header, _, _ := strings.Cut(string(msg), "\r\n\r\n")
+		lines := strings.Split(header, "\r\n")
+		if len(lines) != 7 {
+			t.Errorf("%s: header has %d lines, want 7:\n%s", tt.name, len(lines), header)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(header+"\r\n", want+"\r\n") {
+				t.Errorf("%s: header does not contain the line %q:\n%s", tt.name, want, header)
+			}
+		}
+	}
+}
+
+func TestFormatRejectsBadRecipients(t *testing.T) {
+	for _, to := range []string{"", "not an address", "asha@example.edu\r\nBcc: x@example.com", "a@example.edu, b@example.edu"} {
+		if _, err := format("placements@example.edu", Message{To: to, Subject: "Hello"}); err == nil {
+			t.Errorf("format accepted recipient %q", to)
+		}
+	}
+	transport := &FileTransport{Path: filepath.Join(t.TempDir(), "outbox.eml")}
+	if err := transport.Send(Message{To: "asha@example.edu\nBcc: x@example.com"}); err == nil {
+		t.Error("FileTransport sent to an invalid recipient")
+	}
+	if _, err := os.Stat(transport.Path); !os.IsNotExist(err) {
+		t.Error("FileTransport wrote a message for an invalid recipient")
+	}
+}
+
+func TestFileTransportAppends(t *testing.T) {
+	transport := &FileTransport{Path: filepath.Join(t.TempDir(), "outbox.eml"), From: "placements@example.edu"}
+	for _, subject := range []string{"First", "Second"} {
+		if err := transport.Send(Message{Kind: KindDriveEligible, To: "asha@example.edu", Subject: subject, Body: "Body"}); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	data, err := os.ReadFile(transport.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := strings.Index(string(data), "Subject: First"), strings.Index(string(data), "Subject: Second")
+	if first < 0 || second < first {
+		t.Errorf("file does not hold both messages in order:\n%s", data)
+	}
+	if got := strings.Count(string(data), "From: placements@example.edu\r\n"); got != 2 {
+		t.Errorf("file holds %d messages, want 2", got)
+	}
+}
+
+func TestLogTransport(t *testing.T) {
+	var out bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&out, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	if err := (LogTransport{}).Send(Message{Kind: KindOfferRecorded, StudentID: 4, To: "asha@example.edu", Subject: "Hello", Body: "secret body"}); err != nil {
+		t.Fatal(err)
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("log output %q: %v", out.String(), err)
+	}
+	if record["msg"] != "notification" || record["kind"] != KindOfferRecorded || record["subject"] != "Hello" || record["studentId"] != float64(4) {
+		t.Errorf("unexpected log record %v", record)
+	}
+	if strings.Contains(out.String(), "secret body") {
+		t.Error("log record contains the message body")
+	}
+}
@@ -0,0 +1,90 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+// templateData is what message templates can refer to. Fields not relevant to a kind are zero.
+type templateData struct {
+	Student models.Student
+	Company models.Company
+	Drive   models.Drive
+	Salary  float64
+	Blocked []models.EligibilityResult // Companies a policy change made the student ineligible for.
+}
+
+// messageTemplate is the subject and body of one kind of notification.
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"date":  func(t time.Time) string { return t.Format("Mon 2 Jan 2006 15:04 MST") },
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"join":  strings.Join,
+}
+
+func mustTemplate(subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New("subject").Funcs(templateFuncs).Parse(subject)),
+		body:    template.Must(template.New("body").Funcs(templateFuncs).Parse(body)),
+	}
+}
+
+var templates = map[string]messageTemplate{
+	KindDriveEligible: mustTemplate(`New placement drive: {{.Company.Name}}`,
+		`Hi {{.Student.FullName}},
+
+{{.Company.Name}} has scheduled a campus drive and you are eligible to apply.
+{{if .Drive.Roles}}Roles: {{join .Drive.Roles ", "}}
+{{end}}Registration closes: {{date .Drive.RegistrationClosesAt}}
+{{if .Drive.Venue}}Venue: {{.Drive.Venue}}
+{{end}}
+Apply from the placement portal before registration closes.
+
+Placement Office
+`),
+	KindPolicyBlocked: mustTemplate(`A placement policy change affects your eligibility`,
+		`Hi {{.Student.FullName}},
+
+After a change to the placement policies you are no longer eligible to apply to:
+{{range .Blocked}}
+- {{.CompanyName}}: {{join .Reasons " "}}{{end}}
+
+Contact the placement office if you think this is wrong.
+
+Placement Office
+`),
+	KindOfferRecorded: mustTemplate(`Congratulations on your offer from {{.Company.Name}}`,
+		`Hi {{.Student.FullName}},
+
+Your offer from {{.Company.Name}} at {{money .Salary}} has been recorded.
+Other companies' eligibility for you may change under the offer category policies.
+
+Placement Office
+`),
+}
+
+// render fills in the template for kind.
+func render(kind string, data templateData) (subject, body string, err error) {
+	t, ok := templates[kind]
+	if !ok {
+		return "", "", fmt.Errorf("no template for notification kind %q", kind)
+	}
+	var s, b bytes.Buffer
+	if err := t.subject.Execute(&s, data); err != nil {
+		return "", "", err
+	}
+	if err := t.body.Execute(&b, data); err != nil {
+		return "", "", err
+	}
+	// Subjects become a header line, so they must not contain line breaks.
+	return strings.Join(strings.Fields(s.String()), " "), b.String(), nil
+}
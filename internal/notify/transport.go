@@ -0,0 +1,115 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Transport delivers a rendered message.
+type Transport interface {
+	Send(m Message) error
+}
+
+// SMTPTransport sends messages through an SMTP relay. Username and Password, if set, are used for PLAIN auth,
+// which net/smtp only allows over TLS or to localhost.
+type SMTPTransport struct {
+	Addr     string // host:port
+	From     string
+	Username string
+	Password string
+}
+
+// Send implements Transport.
+func (t SMTPTransport) Send(m Message) error {
+	var auth smtp.Auth
+	if t.Username != "" {
+		host, _, _ := strings.Cut(t.Addr, ":")
+		auth = smtp.PlainAuth("", t.Username, t.Password, host)
+	}
+	msg, err := format(t.From, m)
+	if err != nil {
+		return err
+	}
+	return smtp.SendMail(t.Addr, auth, t.From, []string{m.To}, msg)
+}
+
+// FileTransport appends each message, in RFC 5322 form, to a file, so local setups can inspect what would be sent.
+type FileTransport struct {
+	Path string
+	From string
+
+	mutex sync.Mutex
+}
+
+// Send implements Transport.
+func (t *FileTransport) Send(m Message) error {
+	msg, err := format(t.From, m)
+	if err != nil {
+		return err
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	f, err := os.OpenFile(t.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(msg, "\r\n"...))
+	return err
+}
+
+// LogTransport writes a one-line summary of each message to the server log.
+type LogTransport struct{}
+
+// Send implements Transport.
+func (LogTransport) Send(m Message) error {
+	slog.Info("notification", "kind", m.Kind, "to", m.To, "studentId", m.StudentID, "subject", m.Subject)
+	return nil
+}
+
+// format renders m as an RFC 5322 message with CRLF line endings. Names in subjects come from imported data, so the
+// recipient must be a single valid address and the subject is reduced to one line before it is written.
+func format(from string, m Message) ([]byte, error) {
+	to, err := mail.ParseAddress(m.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", m.To, err)
+	}
+	recipient := to.Address
+	if to.Name != "" {
+		recipient = to.String()
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", recipient)
+	fmt.Fprintf(&b, "Subject: %s\r\n", headerText(m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&b, "X-Placement-Notification: %s\r\n", m.Kind)
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(m.Body, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return b.Bytes(), nil
+}
+
+// headerText makes s safe to use as a header value: control characters such as CR and LF become spaces, runs of
+// whitespace collapse to one, and non-ASCII text is encoded as RFC 2047 words.
+func headerText(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return ' '
+		}
+		return r
+	}, s)
+	return mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,203 @@
+package policy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/audit"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileStatus describes the state of a watched policy file, as reported by GET /policies/source.
+type FileStatus struct {
+	Path           string     `json:"path"`
+	Checksum       string     `json:"checksum,omitempty"` // SHA-256 of the last successfully applied file contents.
+	LastCheckedAt  time.Time  `json:"lastCheckedAt"`
+	LastAppliedAt  *time.Time `json:"lastAppliedAt,omitempty"`
+	AppliedVersion int64      `json:"appliedVersion,omitempty"` // Policy version created by the last successful reload.
+	LastError      string     `json:"lastError,omitempty"`      // Why the most recent change was rejected; cleared on success.
+	LastErrorAt    *time.Time `json:"lastErrorAt,omitempty"`
+}
+
+// Watcher polls a YAML or JSON policy file and swaps valid changes into the active policy configuration.
+// Invalid files are rejected and the previous configuration stays active.
+type Watcher struct {
+	path     string
+	interval time.Duration
+
+	mutex        sync.Mutex
+	status       FileStatus
+	lastChecksum string // Checksum of the last file contents examined, valid or not, so bad files are reported once.
+}
+
+var activeWatcher struct {
+	sync.RWMutex
+	watcher *Watcher
+}
+
+// FileManaged reports whether the policy configuration is sourced from a watched file.
+// While it is, API mutations are refused so the file in version control stays the single source of truth.
+func FileManaged() bool {
+	activeWatcher.RLock()
+	defer activeWatcher.RUnlock()
+	return activeWatcher.watcher != nil
+}
+
+// ActiveFileStatus returns the status of the watched policy file, if there is one.
+func ActiveFileStatus() (FileStatus, bool) {
+	activeWatcher.RLock()
+	w := activeWatcher.watcher
+	activeWatcher.RUnlock()
+	if w == nil {
+		return FileStatus{}, false
+	}
+	return w.Status(), true
+}
+
+// WatchFile loads the policy file at path immediately, then re-checks it every interval until ctx is cancelled.
+// The returned watcher becomes the process-wide policy source reported by FileManaged and ActiveFileStatus.
+func WatchFile(ctx context.Context, path string, interval time.Duration) *Watcher {
+	w := &Watcher{path: path, interval: interval, status: FileStatus{Path: path}}
+	activeWatcher.Lock()
+	activeWatcher.watcher = w
+	activeWatcher.Unlock()
+
+	w.check()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				w.check()
+			}
+		}
+	}()
+	return w
+}
+
+// Status returns a snapshot of the watcher's state.
+func (w *Watcher) Status() FileStatus {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.status
+}
+
+// check reads the file and applies it if its contents changed since the last check.
+func (w *Watcher) check() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.status.LastCheckedAt = time.Now().UTC()
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		w.lastChecksum = "" // Whatever the file holds once it is readable again must be checked afresh.
+		w.fail(fmt.Errorf("reading policy file: %w", err), "")
+		return
+	}
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	if checksum == w.status.Checksum {
+		// The file holds the applied configuration, e.g. readable again after a failed read or with a rejected
+		// edit reverted, so any earlier error no longer applies.
+		w.lastChecksum = checksum
+		w.clearError()
+		return
+	}
+	if checksum == w.lastChecksum {
+		return // Rejected before; LastError still describes it.
+	}
+
+	config, err := ParseFile(w.path, data)
+	if err != nil {
+		w.fail(err, checksum)
+		return
+	}
+	previous, updated, err := storage.UpdatePolicy(0, func(models.PolicyConfig) (models.PolicyConfig, error) {
+		return config, Validate(config)
+	})
+	if err != nil {
+		w.fail(err, checksum)
+		return
+	}
+
+	w.lastChecksum = checksum
+	w.status.Checksum = checksum
+	appliedAt := w.status.LastCheckedAt
+	w.status.LastAppliedAt = &appliedAt
+	w.status.AppliedVersion = updated.Version
+	w.clearError()
+	slog.Info("policy configuration reloaded from file", "path", w.path, "version", updated.Version)
+
+	if _, err := audit.Record(audit.Entry{
+		Actor:      "file:" + w.path,
+		Action:     audit.ActionPolicyReload,
+		EntityType: audit.EntityPolicy,
+		EntityID:   "active",
+		Before:     audit.Snapshot(previous),
+		After:      audit.Snapshot(updated),
+	}); err != nil {
+		slog.Error("failed to persist audit entry for policy reload", "error", err)
+	}
+}
+
+// clearError forgets the last failure. The caller must hold w.mutex.
+func (w *Watcher) clearError() {
+	w.status.LastError = ""
+	w.status.LastErrorAt = nil
+}
+
+// fail records a rejected reload. The caller must hold w.mutex.
+func (w *Watcher) fail(err error, checksum string) {
+	if checksum != "" {
+		w.lastChecksum = checksum
+	}
+	repeated := w.status.LastError == err.Error()
+	w.status.LastError = err.Error()
+	if repeated {
+		return // Already reported; avoid logging the same failure on every poll.
+	}
+	failedAt := w.status.LastCheckedAt
+	w.status.LastErrorAt = &failedAt
+	slog.Warn("policy file rejected; keeping the active version", "path", w.path, "activeVersion", storage.ActivePolicy().Version, "error", err)
+}
+
+// ParseFile decodes a policy configuration from YAML (.yaml/.yml) or JSON (anything else).
+// Both formats use the JSON field names of models.PolicyConfig, and unknown fields are rejected.
+func ParseFile(path string, data []byte) (models.PolicyConfig, error) {
+	var doc interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return models.PolicyConfig{}, fmt.Errorf("parsing YAML policy file: %w", err)
+		}
+	default:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return models.PolicyConfig{}, fmt.Errorf("parsing JSON policy file: %w", err)
+		}
+	}
+	object, ok := doc.(map[string]interface{})
+	if !ok {
+		return models.PolicyConfig{}, fmt.Errorf("policy file must contain an object at the top level")
+	}
+	delete(object, "version") // Versions are assigned by the server on every reload.
+	config, err := fromMap(object)
+	if err != nil {
+		return models.PolicyConfig{}, fmt.Errorf("policy file: %w", err)
+	}
+	return config, nil
+}
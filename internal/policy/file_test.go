@@ -0,0 +1,66 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/storage"
+)
+
+func TestWatcherClearsReadErrorWhenFileReturns(t *testing.T) {
+	example, err := os.ReadFile("../../policies.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, example, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := &Watcher{path: path, interval: time.Hour, status: FileStatus{Path: path}}
+
+	w.check()
+	applied := w.Status()
+	if applied.LastError != "" || applied.LastAppliedAt == nil {
+		t.Fatalf("example policy file was not applied: %+v", applied)
+	}
+
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	if w.Status().LastError == "" {
+		t.Fatal("missing file did not record an error")
+	}
+
+	if err := os.Rename(path+".moved", path); err != nil {
+		t.Fatal(err)
+	}
+	w.check()
+	status := w.Status()
+	if status.LastError != "" || status.LastErrorAt != nil {
+		t.Errorf("error kept after the file came back unchanged: %q", status.LastError)
+	}
+	if status.AppliedVersion != applied.AppliedVersion || storage.ActivePolicy().Version != applied.AppliedVersion {
+		t.Errorf("unchanged file was applied again: version %d, want %d", status.AppliedVersion, applied.AppliedVersion)
+	}
+}
+
+func TestWatcherKeepsErrorForRejectedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policies.json")
+	if err := os.WriteFile(path, []byte(`{"unknownPolicy": {}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w := &Watcher{path: path, interval: time.Hour, status: FileStatus{Path: path}}
+
+	w.check()
+	first := w.Status()
+	if first.LastError == "" {
+		t.Fatal("invalid policy file was accepted")
+	}
+	w.check()
+	if got := w.Status(); got.LastError != first.LastError {
+		t.Errorf("error for the unchanged invalid file became %q, want %q", got.LastError, first.LastError)
+	}
+}
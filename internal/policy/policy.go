@@ -0,0 +1,272 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/rules"
+)
+
+// Names lists the JSON names of the built-in policy sections of models.PolicyConfig, as used in
+// /policies/{policyName} routes.
+var Names = []string{
+	models.PolicyMaximumCompanies,
+	models.PolicyDreamOffer,
+	models.PolicyDreamCompany,
+	models.PolicyCGPAThreshold,
+	models.PolicyPlacementPercentage,
+	models.PolicyOfferCategory,
+}
+
+// ErrUnknownPolicy is returned when a policy name is not one of Names.
+var ErrUnknownPolicy = errors.New("unknown policy")
+
+// FieldError describes one invalid value in a policy configuration.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// ValidationError collects every problem found in a policy configuration.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + " " + f.Message
+	}
+	return "invalid policy configuration: " + strings.Join(parts, "; ")
+}
+
+// IsKnown reports whether name is a built-in policy section.
+func IsKnown(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the ranges of every policy value and returns a *ValidationError listing all violations, or nil.
+func Validate(config models.PolicyConfig) error {
+	var fields []FieldError
+	check := func(ok bool, field, message string) {
+		if !ok {
+			fields = append(fields, FieldError{Field: field, Message: message})
+		}
+	}
+
+	check(config.MaximumCompanies.MaxN >= 0, "maximumCompanies.maxN", "cannot be negative")
+	check(config.CGPAThreshold.MinimumCGPA >= 0 && config.CGPAThreshold.MinimumCGPA <= 10, "cgpaThreshold.minimumCGPA", "must be between 0 and 10")
+	check(config.CGPAThreshold.HighSalaryThreshold >= 0, "cgpaThreshold.highSalaryThreshold", "cannot be negative")
+	check(config.PlacementPercentage.TargetPercentage >= 0 && config.PlacementPercentage.TargetPercentage <= 100, "placementPercentage.targetPercentage", "must be between 0 and 100")
+	check(config.OfferCategory.L2ThresholdAmount >= 0, "offerCategory.l2ThresholdAmount", "cannot be negative")
+	check(config.OfferCategory.L1ThresholdAmount >= config.OfferCategory.L2ThresholdAmount, "offerCategory.l1ThresholdAmount", "must be at least l2ThresholdAmount")
+	check(config.OfferCategory.RequiredHikePercentage >= 0, "offerCategory.requiredHikePercentage", "cannot be negative")
+
+	seen := map[string]bool{}
+	for i, rule := range config.CustomRules {
+		prefix := fmt.Sprintf("customRules[%d]", i)
+		check(rule.Name != "", prefix+".name", "cannot be empty")
+		check(!seen[rule.Name], prefix+".name", fmt.Sprintf("duplicates another rule named %q", rule.Name))
+		seen[rule.Name] = true
+		check(rule.Effect == models.RuleEffectBlock || rule.Effect == models.RuleEffectAllow || rule.Effect == models.RuleEffectOverride,
+			prefix+".effect", "must be block, allow or override")
+		// Validated configurations are often rejected or short-lived, so they are compiled without filling the cache.
+		if _, err := rules.Compile(rule.Condition); err != nil {
+			check(false, prefix+".condition", err.Error())
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// toMap converts a config into its generic JSON object form.
+func toMap(config models.PolicyConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// fromMap converts a generic JSON object back into a config, rejecting unknown fields so typos are not silently ignored.
+func fromMap(m map[string]interface{}) (models.PolicyConfig, error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return models.PolicyConfig{}, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var config models.PolicyConfig
+	if err := decoder.Decode(&config); err != nil {
+		return models.PolicyConfig{}, err
+	}
+	return config, nil
+}
+
+// mergePatch applies an RFC 7396 JSON merge patch to target and returns the result.
+func mergePatch(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+	return targetObject
+}
+
+// MergePatch applies an RFC 7396 JSON merge patch to config. Members omitted from the patch keep their
+// current values; members set to null are reset to their zero value. The server-managed version is ignored.
+func MergePatch(config models.PolicyConfig, patch []byte) (models.PolicyConfig, error) {
+	var patchDoc interface{}
+	if err := json.Unmarshal(patch, &patchDoc); err != nil {
+		return config, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	patchObject, ok := patchDoc.(map[string]interface{})
+	if !ok {
+		return config, errors.New("invalid merge patch: policy patches must be JSON objects")
+	}
+	delete(patchObject, "version")
+
+	current, err := toMap(config)
+	if err != nil {
+		return config, err
+	}
+	merged, err := fromMap(mergePatch(current, patchObject).(map[string]interface{}))
+	if err != nil {
+		return config, fmt.Errorf("invalid merge patch: %w", err)
+	}
+	merged.Version = config.Version
+	return merged, nil
+}
+
+// ReplaceSection replaces a single named policy section with the given JSON object.
+// Fields omitted from section take their zero value, as with a full PUT.
+func ReplaceSection(config models.PolicyConfig, name string, section []byte) (models.PolicyConfig, error) {
+	if !IsKnown(name) {
+		return config, fmt.Errorf("%w %q", ErrUnknownPolicy, name)
+	}
+	var sectionDoc map[string]interface{}
+	if err := json.Unmarshal(section, &sectionDoc); err != nil {
+		return config, fmt.Errorf("invalid %s policy: %w", name, err)
+	}
+	if sectionDoc == nil {
+		return config, fmt.Errorf("invalid %s policy: body must be a JSON object", name)
+	}
+
+	current, err := toMap(config)
+	if err != nil {
+		return config, err
+	}
+	current[name] = sectionDoc
+	updated, err := fromMap(current)
+	if err != nil {
+		return config, fmt.Errorf("invalid %s policy: %w", name, err)
+	}
+	return updated, nil
+}
+
+// SetEnabled switches a single named policy on or off, leaving its thresholds untouched.
+func SetEnabled(config models.PolicyConfig, name string, enabled bool) (models.PolicyConfig, error) {
+	patch, err := json.Marshal(map[string]interface{}{name: map[string]interface{}{"enabled": enabled}})
+	if err != nil {
+		return config, err
+	}
+	if !IsKnown(name) {
+		return config, fmt.Errorf("%w %q", ErrUnknownPolicy, name)
+	}
+	return MergePatch(config, patch)
+}
+
+// SortedNames returns Names in alphabetical order, for error messages.
+func SortedNames() []string {
+	names := append([]string(nil), Names...)
+	sort.Strings(names)
+	return names
+}
+
+// Changed returns the names of the sections, including "customRules", that differ between two configurations, in
+// alphabetical order. It is used to log what a change did without dumping the whole configuration.
+func Changed(before, after models.PolicyConfig) []string {
+	b, errBefore := toMap(before)
+	a, errAfter := toMap(after)
+	if errBefore != nil || errAfter != nil {
+		return nil
+	}
+	changed := []string{}
+	for name := range union(b, a) {
+		if name != "version" && !reflect.DeepEqual(b[name], a[name]) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// union returns the set of keys present in either map.
+func union(x, y map[string]interface{}) map[string]bool {
+	keys := make(map[string]bool, len(x)+len(y))
+	for k := range x {
+		keys[k] = true
+	}
+	for k := range y {
+		keys[k] = true
+	}
+	return keys
+}
+
+// ErrUnknownRule is returned when a custom rule name does not exist.
+var ErrUnknownRule = errors.New("unknown custom rule")
+
+// UpsertRule adds a custom rule, or replaces the existing rule with the same name in place so its evaluation order is kept.
+func UpsertRule(config models.PolicyConfig, rule models.CustomRule) models.PolicyConfig {
+	updated := append([]models.CustomRule(nil), config.CustomRules...)
+	for i := range updated {
+		if updated[i].Name == rule.Name {
+			updated[i] = rule
+			config.CustomRules = updated
+			return config
+		}
+	}
+	config.CustomRules = append(updated, rule)
+	return config
+}
+
+// DeleteRule removes the named custom rule.
+func DeleteRule(config models.PolicyConfig, name string) (models.PolicyConfig, error) {
+	updated := make([]models.CustomRule, 0, len(config.CustomRules))
+	for _, rule := range config.CustomRules {
+		if rule.Name != name {
+			updated = append(updated, rule)
+		}
+	}
+	if len(updated) == len(config.CustomRules) {
+		return config, fmt.Errorf("%w %q", ErrUnknownRule, name)
+	}
+	config.CustomRules = updated
+	return config, nil
+}
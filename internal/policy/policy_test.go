@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+)
+
+// baseConfig returns a configuration with every section set to non-zero values.
+func baseConfig() models.PolicyConfig {
+	var config models.PolicyConfig
+	config.Version = 4
+	config.MaximumCompanies.Enabled = true
+	config.MaximumCompanies.MaxN = 3
+	config.DreamOffer.Enabled = true
+	config.CGPAThreshold.Enabled = true
+	config.CGPAThreshold.MinimumCGPA = 7.5
+	config.CGPAThreshold.HighSalaryThreshold = 1500000
+	config.OfferCategory.Enabled = true
+	config.OfferCategory.L1ThresholdAmount = 2000000
+	config.OfferCategory.L2ThresholdAmount = 1000000
+	config.OfferCategory.RequiredHikePercentage = 20
+	config.CustomRules = []models.CustomRule{{Name: "no-repeat", Enabled: true, Condition: "student.isPlaced", Effect: models.RuleEffectBlock}}
+	return config
+}
+
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		patch  string
+		change func(c *models.PolicyConfig)
+	}{
+		{"empty patch", `{}`, func(c *models.PolicyConfig) {}},
+		{"one member", `{"cgpaThreshold": {"minimumCGPA": 8}}`, func(c *models.PolicyConfig) { c.CGPAThreshold.MinimumCGPA = 8 }},
+		{"several sections", `{"maximumCompanies": {"enabled": false}, "dreamCompany": {"enabled": true}}`, func(c *models.PolicyConfig) {
+			c.MaximumCompanies.Enabled = false
+			c.DreamCompany.Enabled = true
+		}},
+		{"null resets a member", `{"offerCategory": {"requiredHikePercentage": null}}`, func(c *models.PolicyConfig) { c.OfferCategory.RequiredHikePercentage = 0 }},
+		{"null resets a section", `{"cgpaThreshold": null}`, func(c *models.PolicyConfig) {
+			c.CGPAThreshold.Enabled = false
+			c.CGPAThreshold.MinimumCGPA = 0
+			c.CGPAThreshold.HighSalaryThreshold = 0
+		}},
+		{"arrays are replaced", `{"customRules": [{"name": "cap", "enabled": true, "condition": "true", "effect": "allow"}]}`, func(c *models.PolicyConfig) {
+			c.CustomRules = []models.CustomRule{{Name: "cap", Enabled: true, Condition: "true", Effect: models.RuleEffectAllow}}
+		}},
+		{"version is ignored", `{"version": 99, "dreamOffer": {"enabled": false}}`, func(c *models.PolicyConfig) { c.DreamOffer.Enabled = false }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := baseConfig()
+			tt.change(&want)
+			got, err := MergePatch(baseConfig(), []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("MergePatch: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergePatchErrors(t *testing.T) {
+	tests := []struct {
+		name, patch, want string
+	}{
+		{"not JSON", `{`, "invalid merge patch"},
+		{"not an object", `[1]`, "must be JSON objects"},
+		{"unknown section", `{"cgpa": {"enabled": true}}`, `unknown field "cgpa"`},
+		{"unknown member", `{"cgpaThreshold": {"minimum": 8}}`, `unknown field "minimum"`},
+		{"wrong type", `{"maximumCompanies": {"maxN": "three"}}`, "invalid merge patch"},
+	}
+	for _, tt := range tests {
+		config := baseConfig()
+		got, err := MergePatch(config, []byte(tt.patch))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want one mentioning %q", tt.name, err, tt.want)
+		}
+		if !reflect.DeepEqual(got, config) {
+			t.Errorf("%s: failed patch changed the configuration", tt.name)
+		}
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	got, err := ReplaceSection(baseConfig(), models.PolicyCGPAThreshold, []byte(`{"enabled": true, "minimumCGPA": 6}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := baseConfig()
+	want.CGPAThreshold.MinimumCGPA = 6
+	want.CGPAThreshold.HighSalaryThreshold = 0 // Omitted fields take their zero value.
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+
+	if _, err := ReplaceSection(baseConfig(), "salaryCap", []byte(`{}`)); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("unknown section: error = %v, want ErrUnknownPolicy", err)
+	}
+	for _, body := range []string{`null`, `[]`, `{"enabled": true, "maxN": 2}`} {
+		if _, err := ReplaceSection(baseConfig(), models.PolicyCGPAThreshold, []byte(body)); err == nil {
+			t.Errorf("ReplaceSection accepted %s", body)
+		}
+	}
+}
+
+func TestSetEnabled(t *testing.T) {
+	got, err := SetEnabled(baseConfig(), models.PolicyOfferCategory, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := baseConfig()
+	want.OfferCategory.Enabled = false
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v\nwant %+v", got, want)
+	}
+	if _, err := SetEnabled(baseConfig(), "customRules", true); !errors.Is(err, ErrUnknownPolicy) {
+		t.Errorf("custom rules: error = %v, want ErrUnknownPolicy", err)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	after := baseConfig()
+	after.Version++
+	after.DreamOffer.Enabled = false
+	after.CustomRules = nil
+	if got, want := Changed(baseConfig(), after), []string{"customRules", models.PolicyDreamOffer}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Changed = %v, want %v", got, want)
+	}
+	if got := Changed(baseConfig(), baseConfig()); len(got) != 0 {
+		t.Errorf("Changed of equal configurations = %v", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(baseConfig()); err != nil {
+		t.Fatalf("valid configuration rejected: %v", err)
+	}
+
+	config := baseConfig()
+	config.CGPAThreshold.MinimumCGPA = 11
+	config.OfferCategory.L1ThresholdAmount = 500000
+	config.CustomRules = append(config.CustomRules,
+		models.CustomRule{Name: "no-repeat", Condition: "student.cgpa", Effect: "deny"})
+	err := Validate(config)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate error = %v, want a ValidationError", err)
+	}
+	var fields []string
+	for _, f := range validationErr.Fields {
+		fields = append(fields, f.Field)
+	}
+	want := []string{"cgpaThreshold.minimumCGPA", "offerCategory.l1ThresholdAmount", "customRules[1].name", "customRules[1].effect", "customRules[1].condition"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("invalid fields = %v, want %v", fields, want)
+	}
+}
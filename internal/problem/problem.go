@@ -0,0 +1,122 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+// Stable, machine-readable error codes. Clients should switch on these rather than on Detail text.
+const (
+	CodeInvalidJSON        = "invalid_json"
+	CodeValidationFailed   = "validation_failed"
+	CodeInvalidPathParam   = "invalid_path_parameter"
+	CodeInvalidQueryParam  = "invalid_query_parameter"
+	CodeStudentNotFound    = "student_not_found"
+	CodeCompanyNotFound    = "company_not_found"
+	CodeRouteNotFound      = "route_not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeUnauthenticated    = "unauthenticated"
+	CodeForbidden          = "forbidden"
+	CodeInvalidCredentials = "invalid_credentials"
+	CodeNotEligible        = "not_eligible"
+	CodeAlreadyApplied     = "already_applied"
+	CodeInvalidHeader      = "invalid_header"
+	CodePreconditionFailed = "precondition_failed"
+	CodePolicyNotFound     = "policy_not_found"
+	CodeRuleNotFound       = "rule_not_found"
+	CodePolicyReadOnly     = "policy_read_only"
+	CodeDuplicateRollNo    = "duplicate_roll_number"
+	CodeImportFailed       = "import_failed"
+	CodeDriveNotFound      = "drive_not_found"
+	CodeRegistrationClosed = "registration_closed"
+	CodeScheduleConflict   = "schedule_conflict"
+	CodeRoundNotFound      = "round_not_found"
+	CodeWebhookNotFound    = "webhook_not_found"
+	CodeDeliveryNotFound   = "delivery_not_found"
+	CodeDeliveryNotDead    = "delivery_not_dead"
+	CodeQueueFull          = "queue_full"
+	CodeInternal           = "internal_error"
+)
+
+// FieldError describes a validation failure for a single request field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem is an RFC 7807 problem document. Extensions are serialised as additional top-level members.
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Code       string                 `json:"code"`
+	RequestID  string                 `json:"requestId,omitempty"`
+	Errors     []FieldError           `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// MarshalJSON flattens Extensions into the top-level object as RFC 7807 requires.
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type plain Problem
+	base, err := json.Marshal(plain(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return base, err
+	}
+	merged := map[string]interface{}{}
+	for k, v := range p.Extensions {
+		merged[k] = v
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(base, &fields); err != nil {
+		return nil, err
+	}
+	for k, v := range fields {
+		merged[k] = v // Standard members win over extensions with the same name.
+	}
+	return json.Marshal(merged)
+}
+
+// New builds a problem for the given status and code.
+func New(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "urn:placement:problem:" + code,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Write sends p as an application/problem+json response, filling in the request path and request ID.
+func Write(w http.ResponseWriter, r *http.Request, p Problem) {
+	if p.Instance == "" {
+		p.Instance = r.URL.Path
+	}
+	if p.RequestID == "" {
+		p.RequestID = middleware.GetReqID(r.Context())
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Respond is shorthand for Write(w, r, New(status, code, detail)) with optional field errors.
+func Respond(w http.ResponseWriter, r *http.Request, status int, code, detail string, fieldErrors ...FieldError) {
+	p := New(status, code, detail)
+	p.Errors = fieldErrors
+	Write(w, r, p)
+}
+
+// NotFoundHandler replaces the router's plain-text 404 response.
+func NotFoundHandler(w http.ResponseWriter, r *http.Request) {
+	Respond(w, r, http.StatusNotFound, CodeRouteNotFound, "No route matches "+r.Method+" "+r.URL.Path)
+}
+
+// MethodNotAllowedHandler replaces the router's plain-text 405 response.
+func MethodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
+	Respond(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path)
+}
@@ -0,0 +1,44 @@
+package rules
+
+import "go-placement-policy/internal/models"
+
+// Env is the data a rule is evaluated against.
+type Env struct {
+	Student models.Student
+	Company models.Company
+	// OfferCategory is the student's computed offer tier ("L1", "L2", "L3", or "" when unplaced).
+	OfferCategory string
+}
+
+// field describes a variable that rules can reference.
+type field struct {
+	typ Type
+	get func(Env) interface{}
+}
+
+// fields maps every variable name available to rules to its type and accessor.
+// Names follow the JSON field names of models.Student and models.Company.
+var fields = map[string]field{
+	"student.id":               {TypeNumber, func(e Env) interface{} { return float64(e.Student.ID) }},
+	"student.name":             {TypeString, func(e Env) interface{} { return e.Student.FullName }},
+	"student.cgpa":             {TypeNumber, func(e Env) interface{} { return e.Student.CGPA }},
+	"student.isPlaced":         {TypeBool, func(e Env) interface{} { return e.Student.IsPlaced }},
+	"student.currentSalary":    {TypeNumber, func(e Env) interface{} { return e.Student.CurrentSalary }},
+	"student.companiesApplied": {TypeNumber, func(e Env) interface{} { return float64(e.Student.NumCompaniesApplied) }},
+	"student.dreamOffer":       {TypeNumber, func(e Env) interface{} { return e.Student.DreamOfferAmount }},
+	"student.dreamCompany":     {TypeString, func(e Env) interface{} { return e.Student.DreamCompanyName }},
+	"student.department":       {TypeString, func(e Env) interface{} { return e.Student.Department }},
+	"student.offerCategory":    {TypeString, func(e Env) interface{} { return e.OfferCategory }},
+	"company.id":               {TypeString, func(e Env) interface{} { return e.Company.ID }},
+	"company.name":             {TypeString, func(e Env) interface{} { return e.Company.Name }},
+	"company.offeredSalary":    {TypeNumber, func(e Env) interface{} { return e.Company.OfferedSalary }},
+}
+
+// Variables returns the names of every variable rules may reference.
+func Variables() []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	return names
+}
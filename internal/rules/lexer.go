@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string  // Operator or identifier text, or the unquoted string literal.
+	num  float64 // Value of a number literal.
+	pos  int     // Byte offset in the source, for error messages.
+}
+
+// SyntaxError reports a problem in a rule expression together with the byte offset where it was found.
+type SyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Message)
+}
+
+// operators lists multi-character operators before their single-character prefixes so the longest match wins.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "!"}
+
+// tokenize splits a rule expression into tokens. Positions are byte offsets even when the source holds multi-byte runes.
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(src) {
+		c, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			return nil, &SyntaxError{Pos: i, Message: "invalid UTF-8"}
+		case unicode.IsSpace(c):
+			i += size
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexRune(src[i+1:], c)
+			if end < 0 {
+				return nil, &SyntaxError{Pos: i, Message: "unterminated string literal"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: src[i+1 : i+1+end], pos: i})
+			i += end + 2
+		case isDigit(c) || (c == '.' && i+1 < len(src) && isDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (isDigit(rune(src[i])) || src[i] == '.' || src[i] == '_') {
+				i++
+			}
+			num, err := strconv.ParseFloat(strings.ReplaceAll(src[start:i], "_", ""), 64)
+			if err != nil {
+				return nil, &SyntaxError{Pos: start, Message: fmt.Sprintf("invalid number %q", src[start:i])}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, num: num, text: src[start:i], pos: start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) {
+				r, n := utf8.DecodeRuneInString(src[i:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' {
+					break
+				}
+				i += n
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Pos: i, Message: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// isDigit reports whether c is an ASCII digit; number literals are ASCII only so strconv can parse them.
+func isDigit(c rune) bool {
+	return '0' <= c && c <= '9'
+}
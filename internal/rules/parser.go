@@ -0,0 +1,327 @@
+package rules
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Type is the static type of a rule expression.
+type Type int
+
+const (
+	TypeNumber Type = iota
+	TypeBool
+	TypeString
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeNumber:
+		return "number"
+	case TypeBool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+// expr is a type-checked expression compiled to a closure.
+type expr struct {
+	typ  Type
+	eval func(Env) interface{}
+}
+
+// Program is a compiled, type-checked boolean rule condition.
+type Program struct {
+	source string
+	root   expr
+}
+
+// Source returns the expression the program was compiled from.
+func (p *Program) Source() string {
+	return p.source
+}
+
+// Eval evaluates the condition against env.
+func (p *Program) Eval(env Env) bool {
+	return p.root.eval(env).(bool)
+}
+
+// Compile parses and type-checks a rule condition. The expression must evaluate to a boolean.
+//
+// Supported syntax: number, string ("..." or '...') and boolean literals; the variables listed by Variables;
+// arithmetic (+ - * /), comparison (< <= > >= == !=), logical (&& || !) operators; parentheses;
+// and the functions min(a, b), max(a, b) and abs(a).
+func Compile(source string) (*Program, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q after end of expression", tok.text)}
+	}
+	if root.typ != TypeBool {
+		return nil, &SyntaxError{Pos: 0, Message: fmt.Sprintf("condition must be a boolean expression, got %s", root.typ)}
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// binaryPrecedence gives the binding power of each binary operator; higher binds tighter.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6,
+}
+
+const unaryPrecedence = 7
+
+// parseExpr parses an expression whose binary operators all bind tighter than minPrecedence (precedence climbing).
+func (p *parser) parseExpr(minPrecedence int) (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return expr{}, err
+	}
+	for {
+		tok := p.peek()
+		precedence, isBinary := binaryPrecedence[tok.text]
+		if tok.kind != tokenOperator || !isBinary || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseExpr(precedence)
+		if err != nil {
+			return expr{}, err
+		}
+		left, err = binary(tok, left, right)
+		if err != nil {
+			return expr{}, err
+		}
+	}
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		operand, err := p.parseExpr(unaryPrecedence)
+		if err != nil {
+			return expr{}, err
+		}
+		if tok.text == "!" {
+			if operand.typ != TypeBool {
+				return expr{}, &SyntaxError{Pos: tok.pos, Message: fmt.Sprintf("operator ! needs a bool, got %s", operand.typ)}
+			}
+			return expr{TypeBool, func(e Env) interface{} { return !operand.eval(e).(bool) }}, nil
+		}
+		if operand.typ != TypeNumber {
+			return expr{}, &SyntaxError{Pos: tok.pos, Message: fmt.Sprintf("unary - needs a number, got %s", operand.typ)}
+		}
+		return expr{TypeNumber, func(e Env) interface{} { return -operand.eval(e).(float64) }}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		v := tok.num
+		return expr{TypeNumber, func(Env) interface{} { return v }}, nil
+	case tokenString:
+		v := tok.text
+		return expr{TypeString, func(Env) interface{} { return v }}, nil
+	case tokenLParen:
+		inner, err := p.parseExpr(0)
+		if err != nil {
+			return expr{}, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return expr{}, &SyntaxError{Pos: closing.pos, Message: "expected )"}
+		}
+		return inner, nil
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			v := tok.text == "true"
+			return expr{TypeBool, func(Env) interface{} { return v }}, nil
+		case "min", "max", "abs":
+			return p.parseCall(tok)
+		}
+		f, ok := fields[tok.text]
+		if !ok {
+			known := Variables()
+			sort.Strings(known)
+			return expr{}, &SyntaxError{Pos: tok.pos, Message: fmt.Sprintf("unknown variable %q; available: %s", tok.text, strings.Join(known, ", "))}
+		}
+		return expr{f.typ, f.get}, nil
+	case tokenEOF:
+		return expr{}, &SyntaxError{Pos: tok.pos, Message: "unexpected end of expression"}
+	}
+	return expr{}, &SyntaxError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+}
+
+// parseCall parses the argument list of a built-in numeric function.
+func (p *parser) parseCall(name token) (expr, error) {
+	if open := p.next(); open.kind != tokenLParen {
+		return expr{}, &SyntaxError{Pos: open.pos, Message: fmt.Sprintf("expected ( after %s", name.text)}
+	}
+	var args []expr
+	for {
+		arg, err := p.parseExpr(0)
+		if err != nil {
+			return expr{}, err
+		}
+		if arg.typ != TypeNumber {
+			return expr{}, &SyntaxError{Pos: name.pos, Message: fmt.Sprintf("%s needs number arguments, got %s", name.text, arg.typ)}
+		}
+		args = append(args, arg)
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			break
+		}
+		if tok.kind != tokenComma {
+			return expr{}, &SyntaxError{Pos: tok.pos, Message: "expected , or )"}
+		}
+	}
+
+	want := 2
+	if name.text == "abs" {
+		want = 1
+	}
+	if len(args) != want {
+		return expr{}, &SyntaxError{Pos: name.pos, Message: fmt.Sprintf("%s takes %d argument(s), got %d", name.text, want, len(args))}
+	}
+	switch name.text {
+	case "abs":
+		return expr{TypeNumber, func(e Env) interface{} { return math.Abs(args[0].eval(e).(float64)) }}, nil
+	case "min":
+		return expr{TypeNumber, func(e Env) interface{} { return math.Min(args[0].eval(e).(float64), args[1].eval(e).(float64)) }}, nil
+	default:
+		return expr{TypeNumber, func(e Env) interface{} { return math.Max(args[0].eval(e).(float64), args[1].eval(e).(float64)) }}, nil
+	}
+}
+
+// binary type-checks and builds a binary operation.
+func binary(op token, left, right expr) (expr, error) {
+	mismatch := func(want string) (expr, error) {
+		return expr{}, &SyntaxError{Pos: op.pos, Message: fmt.Sprintf("operator %s needs %s operands, got %s and %s", op.text, want, left.typ, right.typ)}
+	}
+
+	switch op.text {
+	case "&&", "||":
+		if left.typ != TypeBool || right.typ != TypeBool {
+			return mismatch("bool")
+		}
+		if op.text == "&&" {
+			return expr{TypeBool, func(e Env) interface{} { return left.eval(e).(bool) && right.eval(e).(bool) }}, nil
+		}
+		return expr{TypeBool, func(e Env) interface{} { return left.eval(e).(bool) || right.eval(e).(bool) }}, nil
+
+	case "==", "!=":
+		if left.typ != right.typ {
+			return mismatch("matching")
+		}
+		negate := op.text == "!="
+		if left.typ == TypeString {
+			// String equality is case-insensitive, matching how dream company names are compared in list filters.
+			return expr{TypeBool, func(e Env) interface{} {
+				return strings.EqualFold(left.eval(e).(string), right.eval(e).(string)) != negate
+			}}, nil
+		}
+		return expr{TypeBool, func(e Env) interface{} { return (left.eval(e) == right.eval(e)) != negate }}, nil
+
+	case "<", "<=", ">", ">=":
+		if left.typ != TypeNumber || right.typ != TypeNumber {
+			return mismatch("number")
+		}
+		compare := map[string]func(a, b float64) bool{
+			"<":  func(a, b float64) bool { return a < b },
+			"<=": func(a, b float64) bool { return a <= b },
+			">":  func(a, b float64) bool { return a > b },
+			">=": func(a, b float64) bool { return a >= b },
+		}[op.text]
+		return expr{TypeBool, func(e Env) interface{} { return compare(left.eval(e).(float64), right.eval(e).(float64)) }}, nil
+
+	default: // + - * /
+		if left.typ != TypeNumber || right.typ != TypeNumber {
+			return mismatch("number")
+		}
+		arithmetic := map[string]func(a, b float64) float64{
+			"+": func(a, b float64) float64 { return a + b },
+			"-": func(a, b float64) float64 { return a - b },
+			"*": func(a, b float64) float64 { return a * b },
+			"/": func(a, b float64) float64 { return a / b },
+		}[op.text]
+		return expr{TypeNumber, func(e Env) interface{} { return arithmetic(left.eval(e).(float64), right.eval(e).(float64)) }}, nil
+	}
+}
+
+// compiledCacheSize bounds how many programs CompileCached keeps. Active policies hold a handful of rules, so the
+// bound only matters when conditions keep changing.
+const compiledCacheSize = 256
+
+// compiled is a least-recently-used cache of programs keyed by source.
+var compiled = struct {
+	sync.Mutex
+	order   *list.List // Front is most recently used; elements hold *Program.
+	entries map[string]*list.Element
+}{order: list.New(), entries: map[string]*list.Element{}}
+
+// CompileCached is Compile with memoisation, for evaluating the same stored rules on every eligibility check.
+// Programs are immutable, so the cached ones are shared.
+func CompileCached(source string) (*Program, error) {
+	compiled.Lock()
+	if e, ok := compiled.entries[source]; ok {
+		compiled.order.MoveToFront(e)
+		compiled.Unlock()
+		return e.Value.(*Program), nil
+	}
+	compiled.Unlock()
+
+	program, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+
+	compiled.Lock()
+	defer compiled.Unlock()
+	if e, ok := compiled.entries[source]; ok { // Compiled concurrently by another caller.
+		return e.Value.(*Program), nil
+	}
+	compiled.entries[source] = compiled.order.PushFront(program)
+	for compiled.order.Len() > compiledCacheSize {
+		oldest := compiled.order.Back()
+		compiled.order.Remove(oldest)
+		delete(compiled.entries, oldest.Value.(*Program).source)
+	}
+	return program, nil
+}
@@ -0,0 +1,155 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"1 + 2 * 3 == 7", true},
+		{"(1 + 2) * 3 == 9", true},
+		{"10 - 4 - 3 == 3", true},
+		{"12 / 2 / 3 == 2", true},
+		{"-2 * 3 == -6", true},
+		{"1 + 1 < 3 == true", true},
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"!false && false", false},
+		{"!(false && false)", true},
+		{"1 < 2 && 2 < 3 || false", true},
+		{"min(3, 1 + 1) == 2 && max(-1, abs(-4)) == 4", true},
+	}
+	for _, tt := range tests {
+		program, err := Compile(tt.source)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.source, err)
+			continue
+		}
+		if got := program.Eval(Env{}); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	env := Env{
+		Student: models.Student{
+			ID: 7, FullName: "Asha Rao", CGPA: 8.4, IsPlaced: true, CurrentSalary: 900000,
+			NumCompaniesApplied: 3, DreamOfferAmount: 1500000, DreamCompanyName: "Acme", Department: "CSE",
+		},
+		Company:       models.Company{ID: "C001", Name: "ACME", OfferedSalary: 1400000},
+		OfferCategory: "L2",
+	}
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{"student.cgpa >= 8", true},
+		{"student.cgpa >= 8.5", false},
+		{"student.isPlaced && student.offerCategory == 'L2'", true},
+		{`student.dreamCompany == company.name`, true},
+		{`student.department != "cse"`, false},
+		{"company.offeredSalary >= student.currentSalary * 1.5", true},
+		{"company.offeredSalary >= 1_500_000", false},
+		{"student.companiesApplied < 3", false},
+		{"student.id == 7 && company.id == 'C001'", true},
+		{"abs(student.dreamOffer - company.offeredSalary) <= 100000", true},
+		{"student.name == 'Asha Rao' && !student.isPlaced", false},
+		{"company.name == 'Acmé'", false},
+	}
+	for _, tt := range tests {
+		program, err := Compile(tt.source)
+		if err != nil {
+			t.Errorf("Compile(%q): %v", tt.source, err)
+			continue
+		}
+		if got := program.Eval(env); got != tt.want {
+			t.Errorf("%q = %v, want %v", tt.source, got, tt.want)
+		}
+		if program.Source() != tt.source {
+			t.Errorf("Source() = %q, want %q", program.Source(), tt.source)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source  string
+		wantPos int
+		want    string
+	}{
+		{"", 0, "unexpected end of expression"},
+		{"student.cgpa >", 14, "unexpected end of expression"},
+		{"student.cgpa", 0, "must be a boolean"},
+		{"student.cgpa >= 8 )", 18, `unexpected ")"`},
+		{"(student.cgpa >= 8", 18, "expected )"},
+		{"student.gpa >= 8", 0, `unknown variable "student.gpa"`},
+		{"student.cgpa >= 'eight'", 13, "operator >= needs number operands"},
+		{"student.isPlaced && 1", 17, "operator && needs bool operands"},
+		{"student.name == 1", 13, "needs matching operands"},
+		{"!student.cgpa", 0, "operator ! needs a bool"},
+		{"-student.isPlaced", 0, "unary - needs a number"},
+		{"min(1) > 0", 0, "min takes 2 argument(s), got 1"},
+		{"abs('x') > 0", 0, "abs needs number arguments"},
+		{"max 1", 4, "expected ( after max"},
+		{"min(1; 2) > 0", 5, `unexpected character ';'`},
+		{"student.name == 'Asha", 16, "unterminated string literal"},
+		{"1.2.3 > 0", 0, `invalid number "1.2.3"`},
+		{"student.cgpa >= 8 & true", 18, "unexpected character '&'"},
+		// Positions are byte offsets: each "é" is two bytes, so "#" is at byte 13 rather than rune 11.
+		{"'é' == 'é' # x", 13, "unexpected character '#'"},
+		{"étudiant.cgpa > 8", 0, `unknown variable "étudiant.cgpa"`},
+		{"student.cgpa > 8 && €", 20, "unexpected character '€'"},
+		{"student.cgpa > 8 && \xff", 20, "invalid UTF-8"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.source)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Compile(%q) error = %v, want a SyntaxError", tt.source, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.wantPos || !strings.Contains(syntaxErr.Message, tt.want) {
+			t.Errorf("Compile(%q) = %v, want position %d and a message containing %q", tt.source, err, tt.wantPos, tt.want)
+		}
+	}
+}
+
+func TestCompileCachedIsBounded(t *testing.T) {
+	first, err := CompileCached("student.cgpa > 0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := CompileCached("student.cgpa > 0"); again != first {
+		t.Error("CompileCached compiled the same source twice")
+	}
+
+	for i := 0; i < compiledCacheSize+50; i++ {
+		if _, err := CompileCached(fmt.Sprintf("student.cgpa > %d", i+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := CompileCached("student.cgpa >"); err == nil {
+		t.Error("CompileCached accepted an invalid source")
+	}
+
+	compiled.Lock()
+	size := compiled.order.Len()
+	_, keptOldest := compiled.entries["student.cgpa > 0"]
+	_, keptNewest := compiled.entries[fmt.Sprintf("student.cgpa > %d", compiledCacheSize+50)]
+	compiled.Unlock()
+	if size != compiledCacheSize {
+		t.Errorf("cache holds %d programs, want %d", size, compiledCacheSize)
+	}
+	if keptOldest || !keptNewest {
+		t.Errorf("kept least recently used = %v, kept most recent = %v; want false, true", keptOldest, keptNewest)
+	}
+}
@@ -0,0 +1,130 @@
+package schedule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go-placement-policy/internal/models"
+)
+
+// eventDuration is the length given to tests and interviews, whose end times are not recorded.
+const eventDuration = time.Hour
+
+// Event is one calendar entry.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time // Equal to Start for instants such as a registration deadline.
+}
+
+// DriveEvents returns the calendar entries for a drive: the registration deadline, the test and each interview.
+// companyName labels the entries; if empty, the company ID is used.
+func DriveEvents(d models.Drive, companyName string) []Event {
+	if companyName == "" {
+		companyName = d.CompanyID
+	}
+	description := ""
+	if len(d.Roles) > 0 {
+		description = "Roles: " + strings.Join(d.Roles, ", ")
+	}
+	uid := func(kind string) string {
+		return fmt.Sprintf("drive-%d-%s@placement", d.ID, kind)
+	}
+
+	events := []Event{{
+		UID:         uid("registration"),
+		Summary:     companyName + " drive: registration closes",
+		Description: description,
+		Start:       d.RegistrationClosesAt,
+		End:         d.RegistrationClosesAt,
+	}}
+	if d.TestDate != nil {
+		events = append(events, Event{
+			UID:         uid("test"),
+			Summary:     companyName + " drive: test",
+			Description: description,
+			Location:    d.Venue,
+			Start:       *d.TestDate,
+			End:         d.TestDate.Add(eventDuration),
+		})
+	}
+	for i, t := range d.InterviewDates {
+		events = append(events, Event{
+			UID:         uid(fmt.Sprintf("interview-%d", i+1)),
+			Summary:     fmt.Sprintf("%s drive: interview round %d", companyName, i+1),
+			Description: description,
+			Location:    d.Venue,
+			Start:       t,
+			End:         t.Add(eventDuration),
+		})
+	}
+	return events
+}
+
+// WriteICS writes events as an RFC 5545 iCalendar document named calendarName.
+// stamp is used as DTSTAMP for every event.
+func WriteICS(w io.Writer, calendarName string, events []Event, stamp time.Time) error {
+	out := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(out, name+":"+value)
+	}
+	utc := func(t time.Time) string {
+		return t.UTC().Format("20060102T150405Z")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//College Placement Policy System//Drives//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	line("X-WR-CALNAME", escapeText(calendarName))
+	for _, e := range events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", utc(stamp))
+		line("DTSTART", utc(e.Start))
+		if e.End.After(e.Start) {
+			line("DTEND", utc(e.End))
+		}
+		line("SUMMARY", escapeText(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escapeText(e.Description))
+		}
+		if e.Location != "" {
+			line("LOCATION", escapeText(e.Location))
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return out.Flush()
+}
+
+// escapeText escapes a TEXT property value.
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line terminated by CRLF, folding it so no physical line exceeds 75 octets
+// and never splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space.
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,105 @@
+package schedule
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestWriteFolded(t *testing.T) {
+	tests := []struct {
+		name, line string
+		want       []string
+	}{
+		{"short", "SUMMARY:Acme", []string{"SUMMARY:Acme"}},
+		{"exactly 75 octets", strings.Repeat("a", 75), []string{strings.Repeat("a", 75)}},
+		{"76 octets", strings.Repeat("a", 76), []string{strings.Repeat("a", 75), " a"}},
+		{"several continuations", strings.Repeat("b", 75+74+10), []string{strings.Repeat("b", 75), " " + strings.Repeat("b", 74), " " + strings.Repeat("b", 10)}},
+		// "é" is two octets starting at offset 74, so the first line stops before it rather than splitting it.
+		{"multi-byte rune at the boundary", strings.Repeat("c", 74) + "éd", []string{strings.Repeat("c", 74), " éd"}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w := bufio.NewWriter(&buf)
+		writeFolded(w, tt.line)
+		w.Flush()
+
+		got := buf.String()
+		if !strings.HasSuffix(got, "\r\n") {
+			t.Errorf("%s: output %q does not end with CRLF", tt.name, got)
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(got, "\r\n"), "\r\n")
+		if strings.Join(lines, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("%s: lines %q, want %q", tt.name, lines, tt.want)
+		}
+		if unfolded := strings.ReplaceAll(strings.TrimSuffix(got, "\r\n"), "\r\n ", ""); unfolded != tt.line {
+			t.Errorf("%s: unfolding gives %q, want %q", tt.name, unfolded, tt.line)
+		}
+	}
+}
+
+func TestWriteFoldedKeepsRunesWhole(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("日本語テキスト", 20)
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	writeFolded(w, line)
+	w.Flush()
+
+	for i, physical := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(physical) > 75 {
+			t.Errorf("line %d is %d octets long", i+1, len(physical))
+		}
+		if !utf8.ValidString(physical) {
+			t.Errorf("line %d splits a UTF-8 sequence: %q", i+1, physical)
+		}
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	got := escapeText("Hall A, Block 2; back\\side\nfloor 3\r\nroom 4")
+	want := `Hall A\, Block 2\; back\\side\nfloor 3\nroom 4`
+	if got != want {
+		t.Errorf("escapeText = %q, want %q", got, want)
+	}
+}
+
+func TestWriteICS(t *testing.T) {
+	closes := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	test := time.Date(2025, 3, 14, 4, 30, 0, 0, time.UTC)
+	drive := models.Drive{ID: 9, CompanyID: "C001", Roles: []string{"SDE", "Analyst"}, Venue: "Hall A",
+		RegistrationClosesAt: closes, TestDate: &test, InterviewDates: []time.Time{test.Add(24 * time.Hour)}}
+
+	var buf bytes.Buffer
+	if err := WriteICS(&buf, "Acme, drives", DriveEvents(drive, "Acme"), closes); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\nVERSION:2.0\r\n",
+		"X-WR-CALNAME:Acme\\, drives\r\n",
+		"UID:drive-9-registration@placement\r\nDTSTAMP:20250310T120000Z\r\nDTSTART:20250310T120000Z\r\nSUMMARY:Acme drive: registration closes\r\n",
+		"UID:drive-9-test@placement\r\nDTSTAMP:20250310T120000Z\r\nDTSTART:20250314T043000Z\r\nDTEND:20250314T053000Z\r\n",
+		"SUMMARY:Acme drive: interview round 1\r\nDESCRIPTION:Roles: SDE\\, Analyst\r\nLOCATION:Hall A\r\n",
+		"END:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("calendar does not contain %q:\n%s", want, got)
+		}
+	}
+	if n := strings.Count(got, "BEGIN:VEVENT"); n != 3 {
+		t.Errorf("calendar has %d events, want 3", n)
+	}
+	if strings.Contains(strings.ReplaceAll(got, "\r\n", ""), "\n") {
+		t.Error("calendar contains a bare line feed")
+	}
+	registration := got[strings.Index(got, "UID:drive-9-registration"):strings.Index(got, "UID:drive-9-test")]
+	if strings.Contains(registration, "DTEND") || strings.Contains(registration, "LOCATION") {
+		t.Errorf("registration deadline has an end time or location:\n%s", registration)
+	}
+}
@@ -0,0 +1,66 @@
+// Package schedule works out drive days, registration conflicts and iCalendar feeds for campus drives.
+package schedule
+
+import (
+	"sort"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+// Location is the campus time zone. Drive times are stored as absolute instants; they are converted to
+// Location to decide which calendar day an event falls on.
+var Location = time.Local
+
+// dayFormat renders calendar days in reports.
+const dayFormat = "2006-01-02"
+
+// Days returns the campus-local calendar days (YYYY-MM-DD) on which the drive holds its test or interviews, sorted.
+// Registration deadlines do not occupy a day.
+func Days(d models.Drive) []string {
+	set := map[string]bool{}
+	if d.TestDate != nil {
+		set[d.TestDate.In(Location).Format(dayFormat)] = true
+	}
+	for _, t := range d.InterviewDates {
+		set[t.In(Location).Format(dayFormat)] = true
+	}
+	days := make([]string, 0, len(set))
+	for day := range set {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+	return days
+}
+
+// Conflict names another drive that shares at least one day with the drive being checked.
+type Conflict struct {
+	DriveID   int      `json:"driveId"`
+	CompanyID string   `json:"companyId"`
+	Days      []string `json:"days"`
+}
+
+// Conflicts returns the drives among others that hold an event on the same day as drive.
+// drive itself is skipped if it appears in others.
+func Conflicts(drive models.Drive, others []models.Drive) []Conflict {
+	days := map[string]bool{}
+	for _, day := range Days(drive) {
+		days[day] = true
+	}
+	var conflicts []Conflict
+	for _, other := range others {
+		if other.ID == drive.ID {
+			continue
+		}
+		var shared []string
+		for _, day := range Days(other) {
+			if days[day] {
+				shared = append(shared, day)
+			}
+		}
+		if len(shared) > 0 {
+			conflicts = append(conflicts, Conflict{DriveID: other.ID, CompanyID: other.CompanyID, Days: shared})
+		}
+	}
+	return conflicts
+}
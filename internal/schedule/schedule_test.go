@@ -0,0 +1,53 @@
+package schedule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestDaysUseCampusTimeZone(t *testing.T) {
+	saved := Location
+	Location = time.FixedZone("IST", 5*3600+1800)
+	t.Cleanup(func() { Location = saved })
+
+	test := time.Date(2025, 3, 14, 20, 0, 0, 0, time.UTC) // 01:30 on the 15th in IST.
+	drive := models.Drive{
+		RegistrationClosesAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+		TestDate:             &test,
+		InterviewDates:       []time.Time{test.Add(2 * time.Hour), time.Date(2025, 3, 13, 6, 0, 0, 0, time.UTC)},
+	}
+	if got, want := Days(drive), []string{"2025-03-13", "2025-03-15"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Days = %v, want %v", got, want)
+	}
+	if got := Days(models.Drive{RegistrationClosesAt: test}); len(got) != 0 {
+		t.Errorf("Days of a drive with only a deadline = %v", got)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	saved := Location
+	Location = time.UTC
+	t.Cleanup(func() { Location = saved })
+
+	day := func(d int) *time.Time {
+		at := time.Date(2025, 3, d, 9, 0, 0, 0, time.UTC)
+		return &at
+	}
+	drive := models.Drive{ID: 1, CompanyID: "C001", TestDate: day(14), InterviewDates: []time.Time{*day(15)}}
+	others := []models.Drive{
+		drive,
+		{ID: 2, CompanyID: "C002", TestDate: day(15)},
+		{ID: 3, CompanyID: "C003", TestDate: day(16)},
+		{ID: 4, CompanyID: "C004", InterviewDates: []time.Time{*day(14), *day(15)}},
+	}
+	want := []Conflict{
+		{DriveID: 2, CompanyID: "C002", Days: []string{"2025-03-15"}},
+		{DriveID: 4, CompanyID: "C004", Days: []string{"2025-03-14", "2025-03-15"}},
+	}
+	if got := Conflicts(drive, others); !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,186 @@
+package stats
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// ImpactCount counts the student–company pairs, and the distinct students among them, for one outcome.
+type ImpactCount struct {
+	Pairs      int   `json:"pairs"`
+	Students   int   `json:"students"`
+	StudentIDs []int `json:"studentIds,omitempty"` // Sorted; only included when the report is requested with details.
+}
+
+// ReasonImpact breaks a policy's impact down by reason code.
+type ReasonImpact struct {
+	Code       string      `json:"code"`
+	Blocked    ImpactCount `json:"blocked"`
+	Allowed    ImpactCount `json:"allowed"`
+	Overridden ImpactCount `json:"overridden"`
+}
+
+// PolicyImpact summarises how often one policy (or custom rule) blocked, allowed or was overridden.
+type PolicyImpact struct {
+	Policy     string         `json:"policy"`
+	Blocked    ImpactCount    `json:"blocked"`
+	Allowed    ImpactCount    `json:"allowed"`
+	Overridden ImpactCount    `json:"overridden"`
+	Reasons    []ReasonImpact `json:"reasons"`
+}
+
+// ImpactReport is the result of running the eligibility engine over every student–company pair.
+type ImpactReport struct {
+	Revision      int64          `json:"revision"`
+	GeneratedAt   time.Time      `json:"generatedAt"`
+	Students      int            `json:"students"`
+	Companies     int            `json:"companies"`
+	Pairs         int            `json:"pairs"`
+	EligiblePairs int            `json:"eligiblePairs"`
+	Policies      []PolicyImpact `json:"policies"`
+}
+
+// tally accumulates an ImpactCount.
+type tally struct {
+	pairs    int
+	students map[int]struct{}
+}
+
+func (t *tally) add(studentID int) {
+	if t.students == nil {
+		t.students = map[int]struct{}{}
+	}
+	t.pairs++
+	t.students[studentID] = struct{}{}
+}
+
+func (t *tally) count() ImpactCount {
+	c := ImpactCount{Pairs: t.pairs, Students: len(t.students), StudentIDs: make([]int, 0, len(t.students))}
+	for id := range t.students {
+		c.StudentIDs = append(c.StudentIDs, id)
+	}
+	sort.Ints(c.StudentIDs)
+	return c
+}
+
+// outcomeTallies holds one tally per decision outcome.
+type outcomeTallies map[string]*tally
+
+func (o outcomeTallies) add(outcome string, studentID int) {
+	if o[outcome] == nil {
+		o[outcome] = &tally{}
+	}
+	o[outcome].add(studentID)
+}
+
+func (o outcomeTallies) count(outcome string) ImpactCount {
+	if t := o[outcome]; t != nil {
+		return t.count()
+	}
+	return ImpactCount{StudentIDs: []int{}}
+}
+
+var (
+	impactMutex  sync.Mutex
+	cachedImpact *ImpactReport
+)
+
+// CurrentImpact returns the policy impact report for the current data revision, recomputing it only after
+// students, companies or policies change. The report includes student IDs; it is shared and must not be modified.
+func CurrentImpact() *ImpactReport {
+	impactMutex.Lock()
+	defer impactMutex.Unlock()
+
+	revision := storage.Revision()
+	if cachedImpact != nil && cachedImpact.Revision == revision {
+		return cachedImpact
+	}
+	report := ComputeImpact(storage.AllStudents(), storage.AllCompanies())
+	report.Revision = revision
+	cachedImpact = &report
+	return cachedImpact
+}
+
+// ComputeImpact evaluates every student against every company with the active policies and aggregates
+// the decisions per policy and per reason code. A pair counts once per policy, code and outcome even if
+// the policy contributed more than one decision to it.
+func ComputeImpact(students []models.Student, companies []models.Company) ImpactReport {
+	report := ImpactReport{
+		GeneratedAt: time.Now().UTC(),
+		Students:    len(students),
+		Companies:   len(companies),
+		Policies:    []PolicyImpact{},
+	}
+
+	type reasonKey struct{ policy, code string }
+	byPolicy := map[string]outcomeTallies{}
+	byReason := map[reasonKey]outcomeTallies{}
+	type seenKey struct{ policy, code, outcome string }
+
+	for _, student := range students {
+		for _, company := range companies {
+			result := eligibility.PerformEligibilityCheck(context.Background(), student, company)
+			report.Pairs++
+			if result.IsEligible {
+				report.EligiblePairs++
+			}
+
+			seen := map[seenKey]bool{}
+			for _, d := range result.Decisions {
+				if key := (seenKey{d.Policy, "", d.Outcome}); !seen[key] {
+					seen[key] = true
+					if byPolicy[d.Policy] == nil {
+						byPolicy[d.Policy] = outcomeTallies{}
+					}
+					byPolicy[d.Policy].add(d.Outcome, student.ID)
+				}
+				if key := (seenKey{d.Policy, d.Code, d.Outcome}); !seen[key] {
+					seen[key] = true
+					rk := reasonKey{d.Policy, d.Code}
+					if byReason[rk] == nil {
+						byReason[rk] = outcomeTallies{}
+					}
+					byReason[rk].add(d.Outcome, student.ID)
+				}
+			}
+		}
+	}
+
+	for policyName, tallies := range byPolicy {
+		impact := PolicyImpact{
+			Policy:     policyName,
+			Blocked:    tallies.count(models.DecisionBlocked),
+			Allowed:    tallies.count(models.DecisionAllowed),
+			Overridden: tallies.count(models.DecisionOverridden),
+			Reasons:    []ReasonImpact{},
+		}
+		for key, reasonTallies := range byReason {
+			if key.policy != policyName {
+				continue
+			}
+			impact.Reasons = append(impact.Reasons, ReasonImpact{
+				Code:       key.code,
+				Blocked:    reasonTallies.count(models.DecisionBlocked),
+				Allowed:    reasonTallies.count(models.DecisionAllowed),
+				Overridden: reasonTallies.count(models.DecisionOverridden),
+			})
+		}
+		sort.Slice(impact.Reasons, func(i, j int) bool { return impact.Reasons[i].Code < impact.Reasons[j].Code })
+		report.Policies = append(report.Policies, impact)
+	}
+	// Most blocking policies first, so the answer to "what blocks students most" is at the top.
+	sort.Slice(report.Policies, func(i, j int) bool {
+		a, b := report.Policies[i], report.Policies[j]
+		if a.Blocked.Pairs != b.Blocked.Pairs {
+			return a.Blocked.Pairs > b.Blocked.Pairs
+		}
+		return a.Policy < b.Policy
+	})
+	return report
+}
@@ -0,0 +1,73 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+func TestComputeImpact(t *testing.T) {
+	var config models.PolicyConfig
+	config.MaximumCompanies.Enabled = true
+	config.MaximumCompanies.MaxN = 2
+	config.DreamOffer.Enabled = true
+	config.DreamCompany.Enabled = true
+	previous, _, err := storage.ConfigurePolicy(config, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { storage.ConfigurePolicy(previous, 0) })
+
+	students := []models.Student{
+		// At the application limit, so blocked everywhere except at the dream company, which overrides the block.
+		{ID: 1, IsPlaced: true, NumCompaniesApplied: 2, DreamCompanyName: "Acme"},
+		// Blocked only where the salary is below the dream offer.
+		{ID: 2, IsPlaced: true, DreamOfferAmount: 1000000},
+		// Unplaced students are not touched by these policies.
+		{ID: 3},
+	}
+	companies := []models.Company{{ID: "A", Name: "Acme", OfferedSalary: 800000}, {ID: "B", Name: "Beta", OfferedSalary: 1200000}}
+	report := ComputeImpact(students, companies)
+
+	if report.Students != 3 || report.Companies != 2 || report.Pairs != 6 || report.EligiblePairs != 4 {
+		t.Errorf("report covers %d students, %d companies, %d pairs with %d eligible; want 3, 2, 6 and 4",
+			report.Students, report.Companies, report.Pairs, report.EligiblePairs)
+	}
+	count := func(pairs int, ids ...int) ImpactCount {
+		return ImpactCount{Pairs: pairs, Students: len(ids), StudentIDs: append([]int{}, ids...)}
+	}
+	want := []PolicyImpact{
+		{Policy: models.PolicyDreamOffer, Blocked: count(1, 2), Allowed: count(3, 1, 2), Overridden: count(0),
+			Reasons: []ReasonImpact{
+				{Code: "below_dream_offer", Blocked: count(1, 2), Allowed: count(0), Overridden: count(0)},
+				{Code: "meets_dream_offer", Blocked: count(0), Allowed: count(3, 1, 2), Overridden: count(0)},
+			}},
+		{Policy: models.PolicyMaximumCompanies, Blocked: count(1, 1), Allowed: count(0), Overridden: count(1, 1),
+			Reasons: []ReasonImpact{
+				{Code: "application_limit_reached", Blocked: count(1, 1), Allowed: count(0), Overridden: count(1, 1)},
+			}},
+		{Policy: models.PolicyDreamCompany, Blocked: count(0), Allowed: count(1, 1), Overridden: count(0),
+			Reasons: []ReasonImpact{
+				{Code: "dream_company_override", Blocked: count(0), Allowed: count(1, 1), Overridden: count(0)},
+			}},
+	}
+	if !reflect.DeepEqual(report.Policies, want) {
+		t.Errorf("policies =\n%+v\nwant\n%+v", report.Policies, want)
+	}
+}
+
+func TestCurrentImpactRefreshesAfterAMutation(t *testing.T) {
+	first := CurrentImpact()
+	if again := CurrentImpact(); again != first {
+		t.Error("impact report was recomputed without a data change")
+	}
+	if _, err := storage.AddStudent(models.Student{FullName: "Impact Student", RollNumber: "IMPACT-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if refreshed := CurrentImpact(); refreshed == first || refreshed.Revision != storage.Revision() || refreshed.Students != first.Students+1 {
+		t.Errorf("after adding a student: revision %d (was %d), %d students (was %d); want a fresh report",
+			refreshed.Revision, first.Revision, refreshed.Students, first.Students)
+	}
+}
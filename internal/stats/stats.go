@@ -0,0 +1,338 @@
+// Package stats computes placement analytics from the storage layer.
+package stats
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/eligibility"
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+// unassignedDepartment labels students without a department in the per-department breakdown.
+const unassignedDepartment = "Unassigned"
+
+// Summary describes a set of salaries. Fields are zero when Count is zero.
+type Summary struct {
+	Count  int     `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+}
+
+// Bucket is one histogram bin covering [From, To).
+type Bucket struct {
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+	Count int     `json:"count"`
+}
+
+// TierCounts counts placed students by offer category under the active Offer Category Policy thresholds.
+type TierCounts struct {
+	L1       int `json:"L1"`
+	L2       int `json:"L2"`
+	L3       int `json:"L3"`
+	Unplaced int `json:"unplaced"`
+}
+
+// CompanyHires counts offers recorded against a company.
+type CompanyHires struct {
+	CompanyID     string  `json:"companyId"`
+	CompanyName   string  `json:"companyName"`
+	Hires         int     `json:"hires"`
+	AverageSalary float64 `json:"averageSalary"`
+}
+
+// Department summarises placements within one department.
+type Department struct {
+	Department          string  `json:"department"`
+	TotalStudents       int     `json:"totalStudents"`
+	PlacedStudents      int     `json:"placedStudents"`
+	PlacementPercentage float64 `json:"placementPercentage"`
+	AverageCGPA         float64 `json:"averageCgpa"`
+	Salary              Summary `json:"salary"`
+}
+
+// Correlation relates CGPA to current salary across placed students with a salary.
+// The coefficients are nil when there are fewer than two such students or either value is constant.
+type Correlation struct {
+	SampleSize int      `json:"sampleSize"`
+	Pearson    *float64 `json:"pearson"`
+	Spearman   *float64 `json:"spearman"`
+}
+
+// Report is the full set of placement analytics at one data revision.
+type Report struct {
+	Revision            int64          `json:"revision"`
+	GeneratedAt         time.Time      `json:"generatedAt"`
+	TotalStudents       int            `json:"totalStudents"`
+	PlacedStudents      int            `json:"placedStudents"`
+	PlacementPercentage float64        `json:"placementPercentage"`
+	Salary              Summary        `json:"salary"`
+	SalaryHistogram     []Bucket       `json:"salaryHistogram"`
+	CGPAHistogram       []Bucket       `json:"cgpaHistogram"`
+	OfferTiers          TierCounts     `json:"offerTiers"`
+	CompanyHires        []CompanyHires `json:"companyHires"`
+	UnattributedHires   int            `json:"unattributedHires"` // Placed students with no recorded company, e.g. from the initial data file.
+	Departments         []Department   `json:"departments"`
+	CGPASalary          Correlation    `json:"cgpaSalaryCorrelation"`
+}
+
+var (
+	cacheMutex sync.Mutex
+	cached     *Report
+)
+
+// Current returns the report for the current data revision, computing it only if the data changed
+// since the last call. The returned report is shared and must not be modified.
+func Current() *Report {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	revision := storage.Revision()
+	if cached != nil && cached.Revision == revision {
+		return cached
+	}
+	// Read the revision before the data: if a write lands in between, the report is tagged with the
+	// older revision and recomputed on the next call rather than being served as current.
+	report := Compute(storage.AllStudents(), storage.AllCompanies(), storage.ActivePolicy())
+	report.Revision = revision
+	cached = &report
+	return cached
+}
+
+// Compute builds a report from the given data.
+func Compute(students []models.Student, companies []models.Company, config models.PolicyConfig) Report {
+	report := Report{
+		GeneratedAt:   time.Now().UTC(),
+		TotalStudents: len(students),
+		CompanyHires:  []CompanyHires{},
+		Departments:   []Department{},
+	}
+
+	companyNames := map[string]string{}
+	for _, c := range companies {
+		companyNames[c.ID] = c.Name
+	}
+
+	var salaries, cgpas, salaryCGPAs []float64
+	hireSalaries := map[string][]float64{}
+	departments := map[string][]models.Student{}
+	for _, s := range students {
+		cgpas = append(cgpas, s.CGPA)
+		department := strings.TrimSpace(s.Department)
+		if department == "" {
+			department = unassignedDepartment
+		}
+		departments[department] = append(departments[department], s)
+
+		switch eligibility.OfferCategory(s, config) {
+		case "L1":
+			report.OfferTiers.L1++
+		case "L2":
+			report.OfferTiers.L2++
+		case "L3":
+			report.OfferTiers.L3++
+		default:
+			report.OfferTiers.Unplaced++
+		}
+		if !s.IsPlaced {
+			continue
+		}
+		report.PlacedStudents++
+		if s.PlacedCompanyID != "" {
+			hireSalaries[s.PlacedCompanyID] = append(hireSalaries[s.PlacedCompanyID], s.CurrentSalary)
+		} else {
+			report.UnattributedHires++
+		}
+		if s.CurrentSalary > 0 {
+			salaries = append(salaries, s.CurrentSalary)
+			salaryCGPAs = append(salaryCGPAs, s.CGPA)
+		}
+	}
+
+	report.PlacementPercentage = percentage(report.PlacedStudents, report.TotalStudents)
+	report.Salary = summarize(salaries)
+	report.SalaryHistogram = histogram(salaries, niceStep(report.Salary.Max/10), 0)
+	report.CGPAHistogram = histogram(cgpas, 1, 10)
+	report.CGPASalary = correlate(salaryCGPAs, salaries)
+
+	for id, paid := range hireSalaries {
+		name, known := companyNames[id]
+		if !known {
+			name = id // The company has since been removed or renamed its ID; keep the hires visible.
+		}
+		report.CompanyHires = append(report.CompanyHires, CompanyHires{
+			CompanyID:     id,
+			CompanyName:   name,
+			Hires:         len(paid),
+			AverageSalary: summarize(paid).Mean,
+		})
+	}
+	sort.Slice(report.CompanyHires, func(i, j int) bool {
+		a, b := report.CompanyHires[i], report.CompanyHires[j]
+		if a.Hires != b.Hires {
+			return a.Hires > b.Hires
+		}
+		return a.CompanyID < b.CompanyID
+	})
+
+	for name, members := range departments {
+		department := Department{Department: name, TotalStudents: len(members)}
+		var paid []float64
+		var cgpaSum float64
+		for _, s := range members {
+			cgpaSum += s.CGPA
+			if s.IsPlaced {
+				department.PlacedStudents++
+				if s.CurrentSalary > 0 {
+					paid = append(paid, s.CurrentSalary)
+				}
+			}
+		}
+		department.PlacementPercentage = percentage(department.PlacedStudents, department.TotalStudents)
+		department.AverageCGPA = round(cgpaSum / float64(len(members)))
+		department.Salary = summarize(paid)
+		report.Departments = append(report.Departments, department)
+	}
+	sort.Slice(report.Departments, func(i, j int) bool {
+		return report.Departments[i].Department < report.Departments[j].Department
+	})
+	return report
+}
+
+// percentage returns part as a percentage of whole, rounded to two decimals, or 0 for an empty whole.
+func percentage(part, whole int) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return round(float64(part) / float64(whole) * 100)
+}
+
+// round rounds to two decimal places for presentation.
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+func summarize(values []float64) Summary {
+	if len(values) == 0 {
+		return Summary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + median) / 2
+	}
+	return Summary{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Max:    sorted[len(sorted)-1],
+		Mean:   round(sum / float64(len(sorted))),
+		Median: median,
+	}
+}
+
+// niceStep rounds a raw bucket width up to 1, 2, 2.5 or 5 times a power of ten, so histogram edges are
+// round numbers such as 500000 rather than 437812.
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if step := factor * magnitude; step >= raw {
+			return step
+		}
+	}
+	return 10 * magnitude
+}
+
+// histogram counts values in consecutive buckets of the given width, starting at 0 and ending at the
+// bucket holding the largest value. Empty buckets in between are kept so the bins are contiguous.
+// If upper is positive, values at or above it are counted in the last bucket below upper, so that e.g.
+// a CGPA of exactly 10 lands in [9, 10).
+func histogram(values []float64, width, upper float64) []Bucket {
+	buckets := []Bucket{}
+	for _, v := range values {
+		if v < 0 {
+			continue
+		}
+		index := int(v / width)
+		if upper > 0 && v >= upper {
+			index = int(upper/width) - 1
+		}
+		for len(buckets) <= index {
+			from := float64(len(buckets)) * width
+			buckets = append(buckets, Bucket{From: from, To: from + width})
+		}
+		buckets[index].Count++
+	}
+	return buckets
+}
+
+func correlate(xs, ys []float64) Correlation {
+	c := Correlation{SampleSize: len(xs)}
+	c.Pearson = pearson(xs, ys)
+	c.Spearman = pearson(ranks(xs), ranks(ys))
+	return c
+}
+
+// pearson returns the Pearson correlation coefficient of xs and ys, or nil if it is undefined.
+func pearson(xs, ys []float64) *float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 {
+		return nil
+	}
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := math.Round(cov/math.Sqrt(varX*varY)*1000) / 1000
+	return &r
+}
+
+// ranks returns the rank of each value (1-based), giving tied values the average of their ranks,
+// for use in the Spearman coefficient.
+func ranks(values []float64) []float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(a, b int) bool { return values[order[a]] < values[order[b]] })
+
+	result := make([]float64, len(values))
+	for start := 0; start < len(order); {
+		end := start
+		for end+1 < len(order) && values[order[end+1]] == values[order[start]] {
+			end++
+		}
+		rank := float64(start+end)/2 + 1
+		for k := start; k <= end; k++ {
+			result[order[k]] = rank
+		}
+		start = end + 1
+	}
+	return result
+}
@@ -0,0 +1,138 @@
+package stats
+
+import (
+	"reflect"
+	"testing"
+
+	"go-placement-policy/internal/models"
+	"go-placement-policy/internal/storage"
+)
+
+func TestHistogram(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		width  float64
+		upper  float64
+		want   []Bucket
+	}{
+		{"no values", nil, 1, 0, []Bucket{}},
+		{"edges start a new bucket", []float64{0, 499999, 500000, 1200000}, 500000, 0,
+			[]Bucket{{0, 500000, 2}, {500000, 1000000, 1}, {1000000, 1500000, 1}}},
+		{"empty buckets in between are kept", []float64{0.5, 3.5}, 1, 0,
+			[]Bucket{{0, 1, 1}, {1, 2, 0}, {2, 3, 0}, {3, 4, 1}}},
+		{"negative values are skipped", []float64{-1, 0.2}, 1, 0, []Bucket{{0, 1, 1}}},
+		{"the upper bound falls in the last bucket", []float64{10, 9.5, 8}, 1, 10,
+			[]Bucket{{0, 1, 0}, {1, 2, 0}, {2, 3, 0}, {3, 4, 0}, {4, 5, 0}, {5, 6, 0}, {6, 7, 0}, {7, 8, 0}, {8, 9, 1}, {9, 10, 2}}},
+	}
+	for _, tt := range tests {
+		if got := histogram(tt.values, tt.width, tt.upper); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: histogram = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNiceStep(t *testing.T) {
+	tests := []struct{ raw, want float64 }{
+		{0, 1},
+		{-5, 1},
+		{100, 100},
+		{120000, 200000},
+		{230000, 250000},
+		{437812, 500000},
+		{510000, 1000000},
+	}
+	for _, tt := range tests {
+		if got := niceStep(tt.raw); got != tt.want {
+			t.Errorf("niceStep(%v) = %v, want %v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Summary
+	}{
+		{"empty", nil, Summary{}},
+		{"single", []float64{7}, Summary{Count: 1, Min: 7, Max: 7, Mean: 7, Median: 7}},
+		{"odd count", []float64{3, 1, 2}, Summary{Count: 3, Min: 1, Max: 3, Mean: 2, Median: 2}},
+		{"even count", []float64{4, 1, 3, 2}, Summary{Count: 4, Min: 1, Max: 4, Mean: 2.5, Median: 2.5}},
+		{"mean is rounded", []float64{1, 1, 2}, Summary{Count: 3, Min: 1, Max: 2, Mean: 1.33, Median: 1}},
+	}
+	for _, tt := range tests {
+		values := append([]float64(nil), tt.values...)
+		if got := summarize(tt.values); got != tt.want {
+			t.Errorf("%s: summarize = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(values, tt.values) {
+			t.Errorf("%s: summarize reordered its input", tt.name)
+		}
+	}
+}
+
+func TestRanks(t *testing.T) {
+	tests := []struct{ values, want []float64 }{
+		{[]float64{}, []float64{}},
+		{[]float64{30, 10, 20}, []float64{3, 1, 2}},
+		{[]float64{3, 1, 3, 2}, []float64{3.5, 1, 3.5, 2}},
+		{[]float64{5, 5, 5}, []float64{2, 2, 2}},
+	}
+	for _, tt := range tests {
+		if got := ranks(tt.values); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ranks(%v) = %v, want %v", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestCorrelate(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name         string
+		xs, ys       []float64
+		wantPearson  *float64
+		wantSpearman *float64
+	}{
+		{"too few samples", []float64{1}, []float64{2}, nil, nil},
+		{"constant values", []float64{1, 1, 1}, []float64{1, 2, 3}, nil, nil},
+		{"perfect", []float64{1, 2, 3}, []float64{10, 20, 30}, value(1), value(1)},
+		{"monotonic but not linear", []float64{1, 2, 3, 4}, []float64{1, 10, 100, 1000}, value(0.824), value(1)},
+		{"inverse", []float64{1, 2, 3}, []float64{30, 20, 10}, value(-1), value(-1)},
+		// CGPA ranks 1, 2.5, 2.5, 4 against salary ranks 1, 2, 3, 4.
+		{"ties share their average rank", []float64{6, 7, 7, 9}, []float64{10, 20, 30, 40}, value(0.923), value(0.949)},
+	}
+	for _, tt := range tests {
+		c := correlate(tt.xs, tt.ys)
+		if c.SampleSize != len(tt.xs) || !reflect.DeepEqual(c.Pearson, tt.wantPearson) || !reflect.DeepEqual(c.Spearman, tt.wantSpearman) {
+			t.Errorf("%s: n=%d pearson %v spearman %v; want %v and %v", tt.name, c.SampleSize,
+				deref(c.Pearson), deref(c.Spearman), deref(tt.wantPearson), deref(tt.wantSpearman))
+		}
+	}
+}
+
+func deref(v *float64) interface{} {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+func TestCurrentRefreshesAfterAMutation(t *testing.T) {
+	first := Current()
+	if again := Current(); again != first {
+		t.Error("report was recomputed without a data change")
+	}
+	if first.Revision != storage.Revision() {
+		t.Errorf("report revision %d, storage revision %d", first.Revision, storage.Revision())
+	}
+
+	if _, err := storage.AddStudent(models.Student{FullName: "Stats Student", RollNumber: "STATS-1", CGPA: 7}); err != nil {
+		t.Fatal(err)
+	}
+	refreshed := Current()
+	if refreshed == first || refreshed.Revision != storage.Revision() || refreshed.TotalStudents != first.TotalStudents+1 {
+		t.Errorf("after adding a student: revision %d (was %d), %d students (was %d); want a fresh report",
+			refreshed.Revision, first.Revision, refreshed.TotalStudents, first.TotalStudents)
+	}
+}
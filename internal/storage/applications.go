@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+// ApplicationsForStudent returns all applications submitted by the given student.
+func ApplicationsForStudent(studentID int) []models.Application {
+	ApplicationsMutex.RLock()
+	defer ApplicationsMutex.RUnlock()
+	result := []models.Application{}
+	for _, a := range Applications {
+		if a.StudentID == studentID {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// ApplicationsForDrive returns all applications registered for the given drive.
+func ApplicationsForDrive(driveID int) []models.Application {
+	ApplicationsMutex.RLock()
+	defer ApplicationsMutex.RUnlock()
+	result := []models.Application{}
+	for _, a := range Applications {
+		if a.DriveID == driveID {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// AddApplication stores a new application for the student and company (and drive, if driveID is non-zero) and
+// increments the student's NumCompaniesApplied counter, which feeds the Maximum Companies Policy.
+// It returns ErrAlreadyApplied if the student has already applied to the same drive, or to the company when no drive
+// is given. check, if non-nil, is called with the student's existing applications while the applications lock is
+// held, so checks such as schedule conflicts cannot race with another application; an error from it is returned as is.
+// studentVersion is the version of the student record the caller checked eligibility against. If the student has
+// changed since, e.g. because a concurrent application raised NumCompaniesApplied, nothing is stored and
+// ErrVersionConflict is returned, so the Maximum Companies Policy cannot be exceeded by racing requests.
+func AddApplication(studentID int, studentVersion int64, companyID string, driveID int, reasons []string, check func(existing []models.Application) error) (models.Application, error) {
+	ApplicationsMutex.Lock()
+	var existing []models.Application
+	for _, a := range Applications {
+		if a.StudentID != studentID {
+			continue
+		}
+		if a.CompanyID == companyID && a.DriveID == driveID {
+			ApplicationsMutex.Unlock()
+			return models.Application{}, ErrAlreadyApplied
+		}
+		existing = append(existing, a)
+	}
+	if check != nil {
+		if err := check(existing); err != nil {
+			ApplicationsMutex.Unlock()
+			return models.Application{}, err
+		}
+	}
+	// The counter is raised while the applications lock is held, so the version check and the new application
+	// take effect together.
+	if _, err := UpdateStudentIfVersion(studentID, studentVersion, func(s *models.Student) {
+		s.NumCompaniesApplied++
+	}); err != nil {
+		ApplicationsMutex.Unlock()
+		return models.Application{}, err
+	}
+	application := models.Application{
+		ID:        nextApplicationID,
+		StudentID: studentID,
+		CompanyID: companyID,
+		DriveID:   driveID,
+		Status:    models.ApplicationStatusApplied,
+		AppliedAt: time.Now().UTC(),
+		Reasons:   reasons,
+	}
+	nextApplicationID++
+	Applications = append(Applications, application)
+	ApplicationsMutex.Unlock()
+	return application, nil
+}
+
+// AllApplications returns a copy of every application in submission order.
+func AllApplications() []models.Application {
+	ApplicationsMutex.RLock()
+	defer ApplicationsMutex.RUnlock()
+	result := make([]models.Application, len(Applications))
+	copy(result, Applications)
+	return result
+}
+
+// ProgressDriveApplications applies a round update to a drive's applications atomically. plan runs while
+// ApplicationsMutex is held for writing and receives copies of the drive's applications; it returns the
+// applications to store back (matched by ID) and whether to commit them. Nothing changes unless commit is true.
+// The stored applications are returned in plan order, along with the version each one replaced.
+func ProgressDriveApplications(driveID int, plan func(applications []models.Application) (batch []models.Application, commit bool)) (stored []models.Application, previous []models.Application) {
+	ApplicationsMutex.Lock()
+	defer ApplicationsMutex.Unlock()
+
+	positions := map[int]int{}
+	var current []models.Application
+	for i, a := range Applications {
+		if a.DriveID != driveID {
+			continue
+		}
+		positions[a.ID] = i
+		// Copy the round results so plan can append to them without touching the stored slice.
+		a.RoundResults = append([]models.RoundResult(nil), a.RoundResults...)
+		current = append(current, a)
+	}
+	batch, commit := plan(current)
+	if !commit {
+		return nil, nil
+	}
+	for _, a := range batch {
+		pos, exists := positions[a.ID]
+		if !exists {
+			continue
+		}
+		previous = append(previous, Applications[pos])
+		Applications[pos] = a
+		stored = append(stored, a)
+	}
+	return stored, previous
+}
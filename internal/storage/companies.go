@@ -0,0 +1,76 @@
+package storage
+
+import "go-placement-policy/internal/models"
+
+// FindCompanyByID returns a copy of the company with the given ID.
+func FindCompanyByID(id string) (models.Company, bool) {
+	CompaniesMutex.RLock()
+	defer CompaniesMutex.RUnlock()
+	if pos, ok := companyPositions[id]; ok {
+		return Companies[pos], true
+	}
+	return models.Company{}, false
+}
+
+// AllCompanies returns a copy of every company in storage order.
+func AllCompanies() []models.Company {
+	CompaniesMutex.RLock()
+	defer CompaniesMutex.RUnlock()
+	result := make([]models.Company, len(Companies))
+	copy(result, Companies)
+	return result
+}
+
+// ListCompanies returns a copy of every company matching the filter, in storage order.
+func ListCompanies(f CompanyFilter) []models.Company {
+	CompaniesMutex.RLock()
+	defer CompaniesMutex.RUnlock()
+	result := []models.Company{}
+	for _, c := range Companies {
+		if f.Matches(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// ImportCompanies applies a bulk upsert keyed by company ID atomically. plan runs while CompaniesMutex is held
+// for writing and returns the companies to store and whether to commit them; a company whose ID already exists
+// replaces it under the next version, any other is added with version 1. Nothing is stored unless commit is true.
+// The stored copies are returned in plan order, along with the record each one replaced (zero for additions).
+func ImportCompanies(plan func(lookup func(id string) (models.Company, bool)) (batch []models.Company, commit bool)) (stored []models.Company, previous []models.Company) {
+	CompaniesMutex.Lock()
+	lookup := func(id string) (models.Company, bool) {
+		if pos, ok := companyPositions[id]; ok {
+			return Companies[pos], true
+		}
+		return models.Company{}, false
+	}
+	batch, commit := plan(lookup)
+	if !commit {
+		CompaniesMutex.Unlock()
+		return nil, nil
+	}
+
+	for _, company := range batch {
+		var before models.Company
+		if pos, exists := companyPositions[company.ID]; exists {
+			before = Companies[pos]
+			company.Version = before.Version + 1
+			Companies[pos] = company
+		} else {
+			company.Version = 1
+			companyPositions[company.ID] = len(Companies)
+			Companies = append(Companies, company)
+		}
+		stored = append(stored, company)
+		previous = append(previous, before)
+	}
+	bumpRevision()
+	CompaniesMutex.Unlock()
+
+	for _, company := range stored {
+		companyChanged(company.ID)
+	}
+	return stored, previous
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"go-placement-policy/internal/models"
+)
+
+var (
+	// Drives holds the scheduled campus drives, in creation order.
+	DrivesMutex sync.RWMutex
+	Drives      []models.Drive
+	nextDriveID = 1
+)
+
+// FindDriveByID returns a copy of the drive with the given ID.
+func FindDriveByID(id int) (models.Drive, bool) {
+	DrivesMutex.RLock()
+	defer DrivesMutex.RUnlock()
+	for _, d := range Drives {
+		if d.ID == id {
+			return d, true
+		}
+	}
+	return models.Drive{}, false
+}
+
+// ListDrives returns copies of the drives for the given company (all drives if companyID is empty),
+// ordered by registration closing time.
+func ListDrives(companyID string) []models.Drive {
+	DrivesMutex.RLock()
+	result := []models.Drive{}
+	for _, d := range Drives {
+		if companyID == "" || d.CompanyID == companyID {
+			result = append(result, d)
+		}
+	}
+	DrivesMutex.RUnlock()
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].RegistrationClosesAt.Before(result[j].RegistrationClosesAt)
+	})
+	return result
+}
+
+// AddDrive stores a new drive under a fresh ID with version 1 and returns the stored copy.
+func AddDrive(drive models.Drive) models.Drive {
+	DrivesMutex.Lock()
+	drive.ID = nextDriveID
+	nextDriveID++
+	drive.Version = 1
+	Drives = append(Drives, drive)
+	DrivesMutex.Unlock()
+
+	bumpRevision()
+	return drive
+}
+
+// ReplaceDrive overwrites the drive with the same ID under the next version and returns the previous and new copies.
+// If expectedVersion is non-zero and does not match, nothing changes and ErrVersionConflict is returned.
+func ReplaceDrive(drive models.Drive, expectedVersion int64) (previous models.Drive, updated models.Drive, err error) {
+	DrivesMutex.Lock()
+	defer DrivesMutex.Unlock()
+	for i := range Drives {
+		if Drives[i].ID != drive.ID {
+			continue
+		}
+		previous = Drives[i]
+		if expectedVersion != 0 && previous.Version != expectedVersion {
+			return previous, previous, ErrVersionConflict
+		}
+		drive.Version = previous.Version + 1
+		Drives[i] = drive
+		bumpRevision()
+		return previous, drive, nil
+	}
+	return models.Drive{}, models.Drive{}, ErrNotFound
+}
@@ -0,0 +1,14 @@
+package storage
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when the record being updated does not exist.
+	ErrNotFound = errors.New("record not found")
+	// ErrVersionConflict is returned when an update was conditioned on a version that is no longer current.
+	ErrVersionConflict = errors.New("record was modified concurrently; version mismatch")
+	// ErrDuplicateRollNumber is returned when a student would share a roll number with another student.
+	ErrDuplicateRollNumber = errors.New("another student already has this roll number")
+	// ErrAlreadyApplied is returned when a student applies to the same company (or drive) twice.
+	ErrAlreadyApplied = errors.New("student has already applied")
+)
@@ -0,0 +1,58 @@
+package storage
+
+import "sync"
+
+// Change hooks let packages that cache data derived from storage, such as eligibility results, drop entries as
+// soon as the records they were derived from change. Hooks are called after the change is stored and its lock
+// released, so they may read storage; they must not register further hooks.
+var (
+	hooksMutex   sync.RWMutex
+	studentHooks []func(studentID int)
+	companyHooks []func(companyID string)
+	policyHooks  []func()
+)
+
+// OnStudentChange registers fn to be called with the ID of every created or updated student.
+func OnStudentChange(fn func(studentID int)) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	studentHooks = append(studentHooks, fn)
+}
+
+// OnCompanyChange registers fn to be called with the ID of every created or updated company.
+func OnCompanyChange(fn func(companyID string)) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	companyHooks = append(companyHooks, fn)
+}
+
+// OnPolicyChange registers fn to be called whenever the active policy configuration is replaced.
+func OnPolicyChange(fn func()) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	policyHooks = append(policyHooks, fn)
+}
+
+func studentChanged(studentID int) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	for _, fn := range studentHooks {
+		fn(studentID)
+	}
+}
+
+func companyChanged(companyID string) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	for _, fn := range companyHooks {
+		fn(companyID)
+	}
+}
+
+func policyChanged() {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	for _, fn := range policyHooks {
+		fn()
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/models"
+)
+
+// withinSecond fails the test if fn does not return promptly, which is how a hook deadlock shows up.
+func withinSecond(t *testing.T, what string, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("%s did not return; a change hook is blocked on a storage lock", what)
+	}
+}
+
+func TestHooksCanReadStorage(t *testing.T) {
+	var policyVersions []int64
+	var companyNames []string
+	var studentNames []string
+	OnPolicyChange(func() { policyVersions = append(policyVersions, ActivePolicy().Version) })
+	OnCompanyChange(func(id string) {
+		company, _ := FindCompanyByID(id)
+		companyNames = append(companyNames, company.Name)
+	})
+	OnStudentChange(func(id int) {
+		student, _ := FindStudentByID(id)
+		studentNames = append(studentNames, student.FullName)
+	})
+
+	var updated models.PolicyConfig
+	withinSecond(t, "UpdatePolicy", func() {
+		_, updated, _ = UpdatePolicy(0, func(c models.PolicyConfig) (models.PolicyConfig, error) { return c, nil })
+	})
+	withinSecond(t, "ImportCompanies", func() {
+		ImportCompanies(func(func(string) (models.Company, bool)) ([]models.Company, bool) {
+			return []models.Company{{ID: "HOOK1", Name: "Hooked Ltd"}}, true
+		})
+	})
+	withinSecond(t, "AddStudent", func() {
+		if _, err := AddStudent(models.Student{FullName: "Hook Student", RollNumber: "HOOK-1"}); err != nil {
+			t.Error(err)
+		}
+	})
+
+	if len(policyVersions) == 0 || policyVersions[len(policyVersions)-1] != updated.Version {
+		t.Errorf("policy hook saw versions %v, want the new version %d", policyVersions, updated.Version)
+	}
+	if len(companyNames) == 0 || companyNames[len(companyNames)-1] != "Hooked Ltd" {
+		t.Errorf("company hook saw %q, want the stored company", companyNames)
+	}
+	if len(studentNames) == 0 || studentNames[len(studentNames)-1] != "Hook Student" {
+		t.Errorf("student hook saw %q, want the stored student", studentNames)
+	}
+}
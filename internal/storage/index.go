@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"log/slog"
+	"strings"
+
+	"go-placement-policy/internal/models"
+)
+
+// idSet is a set of student IDs used by the secondary indexes.
+type idSet map[int]struct{}
+
+var (
+	// studentPositions maps a student ID to its position in Students.
+	studentPositions = map[int]int{}
+	// nextStudentID is the next ID handed out by allocateStudentID. It only ever increases,
+	// so IDs are never reused even if records are removed or imported out of order.
+	nextStudentID = 1
+
+	// Secondary indexes used by ListStudents to narrow the candidate set before applying a StudentFilter.
+	studentsByPlaced       = map[bool]idSet{}
+	studentsByDreamCompany = map[string]idSet{} // Keyed by lower-cased dream company name.
+	studentsByDepartment   = map[string]idSet{} // Keyed by lower-cased department.
+	// studentsByRollNumber maps a lower-cased roll number to the ID of the only student holding it.
+	studentsByRollNumber = map[string]int{}
+
+	// companyPositions maps a company ID to its position in Companies.
+	companyPositions = map[string]int{}
+)
+
+// IndexKey normalises a value for the secondary indexes by trimming spaces and lower-casing it. Importers use it
+// to catch rows that name the same record in different ways.
+func IndexKey(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+func (set idSet) add(id int) {
+	set[id] = struct{}{}
+}
+
+func addToIndex(index map[string]idSet, key string, id int) {
+	if key == "" {
+		return
+	}
+	if index[key] == nil {
+		index[key] = idSet{}
+	}
+	index[key].add(id)
+}
+
+func removeFromIndex(index map[string]idSet, key string, id int) {
+	if set := index[key]; set != nil {
+		delete(set, id)
+		if len(set) == 0 {
+			delete(index, key)
+		}
+	}
+}
+
+// indexStudent adds the student to every secondary index. The caller must hold StudentsMutex for writing.
+func indexStudent(s models.Student) {
+	if studentsByPlaced[s.IsPlaced] == nil {
+		studentsByPlaced[s.IsPlaced] = idSet{}
+	}
+	studentsByPlaced[s.IsPlaced].add(s.ID)
+	addToIndex(studentsByDreamCompany, IndexKey(s.DreamCompanyName), s.ID)
+	addToIndex(studentsByDepartment, IndexKey(s.Department), s.ID)
+	if key := IndexKey(s.RollNumber); key != "" {
+		studentsByRollNumber[key] = s.ID
+	}
+}
+
+// unindexStudent removes the student from every secondary index. The caller must hold StudentsMutex for writing.
+func unindexStudent(s models.Student) {
+	delete(studentsByPlaced[s.IsPlaced], s.ID)
+	removeFromIndex(studentsByDreamCompany, IndexKey(s.DreamCompanyName), s.ID)
+	removeFromIndex(studentsByDepartment, IndexKey(s.Department), s.ID)
+	if key := IndexKey(s.RollNumber); key != "" && studentsByRollNumber[key] == s.ID {
+		delete(studentsByRollNumber, key)
+	}
+}
+
+// rollNumberTaken reports whether a student other than id holds the roll number. The caller must hold StudentsMutex.
+func rollNumberTaken(rollNumber string, id int) bool {
+	key := IndexKey(rollNumber)
+	if key == "" {
+		return false
+	}
+	owner, ok := studentsByRollNumber[key]
+	return ok && owner != id
+}
+
+// allocateStudentID returns a fresh student ID. The caller must hold StudentsMutex for writing.
+func allocateStudentID() int {
+	id := nextStudentID
+	nextStudentID++
+	return id
+}
+
+// reserveStudentID makes sure the allocator never hands out id, e.g. after a record with an explicit ID is stored.
+// The caller must hold StudentsMutex for writing.
+func reserveStudentID(id int) {
+	if id >= nextStudentID {
+		nextStudentID = id + 1
+	}
+}
+
+// rebuildStudentIndexes recreates all student indexes from the Students slice.
+// Records with a missing or duplicate ID are given a fresh one so every student stays addressable.
+// The ID allocator is only moved forward, so reloading data without some students never hands their IDs out again.
+func rebuildStudentIndexes() {
+	StudentsMutex.Lock()
+	defer StudentsMutex.Unlock()
+
+	studentPositions = make(map[int]int, len(Students))
+	studentsByPlaced = map[bool]idSet{}
+	studentsByDreamCompany = map[string]idSet{}
+	studentsByDepartment = map[string]idSet{}
+	studentsByRollNumber = map[string]int{}
+
+	for _, s := range Students {
+		reserveStudentID(s.ID)
+	}
+	for i := range Students {
+		if _, duplicate := studentPositions[Students[i].ID]; duplicate || Students[i].ID <= 0 {
+			oldID := Students[i].ID
+			Students[i].ID = allocateStudentID()
+			slog.Warn("student had missing or duplicate ID; reassigned", "name", Students[i].FullName, "oldId", oldID, "studentId", Students[i].ID)
+		}
+		if Students[i].Version == 0 {
+			Students[i].Version = 1
+		}
+		if rollNumberTaken(Students[i].RollNumber, Students[i].ID) {
+			slog.Warn("student has duplicate roll number; cleared", "studentId", Students[i].ID, "name", Students[i].FullName, "rollNumber", Students[i].RollNumber)
+			Students[i].RollNumber = ""
+		}
+		studentPositions[Students[i].ID] = i
+		indexStudent(Students[i])
+	}
+}
+
+// rebuildCompanyIndexes recreates the company ID index from the Companies slice.
+// Companies with duplicate IDs are dropped from the index (the first occurrence wins) and logged.
+func rebuildCompanyIndexes() {
+	CompaniesMutex.Lock()
+	defer CompaniesMutex.Unlock()
+
+	companyPositions = make(map[string]int, len(Companies))
+	for i, c := range Companies {
+		if _, duplicate := companyPositions[c.ID]; duplicate {
+			slog.Warn("duplicate company ID ignored in index", "companyId", c.ID, "name", c.Name)
+			continue
+		}
+		if c.Version == 0 {
+			Companies[i].Version = 1
+		}
+		companyPositions[c.ID] = i
+	}
+}
+
+// candidateStudentIDs returns the smallest index-backed ID set that can satisfy the filter,
+// or nil if no secondary index applies and a full scan is needed. The caller must hold StudentsMutex.
+func candidateStudentIDs(f StudentFilter) idSet {
+	var best idSet
+	consider := func(set idSet) {
+		if set == nil {
+			set = idSet{} // No student has this key, so nothing can match.
+		}
+		if best == nil || len(set) < len(best) {
+			best = set
+		}
+	}
+	if f.IsPlaced != nil {
+		consider(studentsByPlaced[*f.IsPlaced])
+	}
+	if f.DreamCompany != "" {
+		consider(studentsByDreamCompany[IndexKey(f.DreamCompany)])
+	}
+	if f.Department != "" {
+		consider(studentsByDepartment[IndexKey(f.Department)])
+	}
+	return best
+}
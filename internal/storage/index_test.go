@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go-placement-policy/internal/models"
+)
+
+// keepRecords restores the students, companies and student ID allocator when the test ends, so a test can
+// replace them through Load.
+func keepRecords(t *testing.T) {
+	t.Helper()
+	StudentsMutex.Lock()
+	students, next := append([]models.Student{}, Students...), nextStudentID
+	StudentsMutex.Unlock()
+	CompaniesMutex.Lock()
+	companies := append([]models.Company{}, Companies...)
+	CompaniesMutex.Unlock()
+	t.Cleanup(func() {
+		StudentsMutex.Lock()
+		Students, nextStudentID = students, next
+		StudentsMutex.Unlock()
+		CompaniesMutex.Lock()
+		Companies = companies
+		CompaniesMutex.Unlock()
+		rebuildStudentIndexes()
+		rebuildCompanyIndexes()
+		UpdatePlacementStats()
+	})
+}
+
+// loadStudents writes students to students.json in a fresh data directory and loads it.
+func loadStudents(t *testing.T, students []models.Student) {
+	t.Helper()
+	dir := t.TempDir()
+	data, err := json.Marshal(students)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "students.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Load(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadReassignsMissingAndDuplicateIDs(t *testing.T) {
+	keepRecords(t)
+	StudentsMutex.Lock()
+	nextStudentID = 1
+	StudentsMutex.Unlock()
+	loadStudents(t, []models.Student{
+		{ID: 3, FullName: "First", RollNumber: "R1"},
+		{ID: 3, FullName: "Duplicate ID", RollNumber: "R2"},
+		{FullName: "Missing ID", RollNumber: "r1"}, // Also repeats R1, so the roll number is cleared.
+		{ID: 7, FullName: "Highest", RollNumber: "R7", Version: 4},
+	})
+
+	want := []struct {
+		id      int
+		name    string
+		roll    string
+		version int64
+	}{
+		{3, "First", "R1", 1},
+		{8, "Duplicate ID", "R2", 1},
+		{9, "Missing ID", "", 1},
+		{7, "Highest", "R7", 4},
+	}
+	students := AllStudents()
+	if len(students) != len(want) {
+		t.Fatalf("loaded %d students, want %d", len(students), len(want))
+	}
+	for i, w := range want {
+		s := students[i]
+		if s.ID != w.id || s.FullName != w.name || s.RollNumber != w.roll || s.Version != w.version {
+			t.Errorf("student %d = %d %q roll %q version %d; want %+v", i, s.ID, s.FullName, s.RollNumber, s.Version, w)
+		}
+		if found, ok := FindStudentByID(w.id); !ok || found.FullName != w.name {
+			t.Errorf("FindStudentByID(%d) = %q, %v; want %q", w.id, found.FullName, ok, w.name)
+		}
+	}
+	if s, _ := FindStudentByRollNumber("R1"); s.ID != 3 {
+		t.Errorf("roll number R1 belongs to student %d, want 3", s.ID)
+	}
+}
+
+func TestStudentIDsAreNeverReused(t *testing.T) {
+	keepRecords(t)
+	loadStudents(t, []models.Student{{ID: 1, FullName: "One"}, {ID: 2, FullName: "Two"}, {ID: 5, FullName: "Five"}})
+	added, err := AddStudent(models.Student{FullName: "Added"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID <= 5 {
+		t.Fatalf("new student got ID %d, which is not above the loaded IDs", added.ID)
+	}
+
+	// Reloading without the added and highest students must not hand their IDs out again.
+	loadStudents(t, []models.Student{{ID: 1, FullName: "One"}, {ID: 2, FullName: "Two"}, {FullName: "No ID"}})
+	reassigned := AllStudents()[2].ID
+	next, err := AddStudent(models.Student{FullName: "Next"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []int{reassigned, next.ID} {
+		if id <= added.ID {
+			t.Errorf("ID %d was handed out again after the students holding IDs up to %d were removed", id, added.ID)
+		}
+	}
+	if next.ID <= reassigned {
+		t.Errorf("added student got ID %d, not above the reassigned ID %d", next.ID, reassigned)
+	}
+}
+
+func TestStudentLookups(t *testing.T) {
+	keepRecords(t)
+	loadStudents(t, []models.Student{
+		{ID: 1, FullName: "Asha", RollNumber: "CS-001", Department: "CSE"},
+		{ID: 2, FullName: "Bina", RollNumber: "cs-002", Department: "cse"},
+		{ID: 3, FullName: "Chetan", RollNumber: "EE-001", Department: "EEE"},
+		{ID: 4, FullName: "Divya", Department: ""},
+	})
+
+	rollTests := []struct {
+		roll   string
+		wantID int // Zero when no student matches.
+	}{
+		{"CS-001", 1},
+		{"cs-001", 1},
+		{" CS-002 ", 2},
+		{"EE-001", 3},
+		{"ME-001", 0},
+		{"", 0},
+	}
+	for _, tt := range rollTests {
+		s, found := FindStudentByRollNumber(tt.roll)
+		if found != (tt.wantID != 0) || s.ID != tt.wantID {
+			t.Errorf("FindStudentByRollNumber(%q) = %d, %v; want %d", tt.roll, s.ID, found, tt.wantID)
+		}
+	}
+
+	departmentTests := []struct {
+		department string
+		wantIDs    []int
+	}{
+		{"CSE", []int{1, 2}},
+		{"cse", []int{1, 2}},
+		{"EEE", []int{3}},
+		{"MECH", []int{}},
+		{"", []int{1, 2, 3, 4}}, // No department filter.
+	}
+	for _, tt := range departmentTests {
+		ids := []int{}
+		for _, s := range ListStudents(StudentFilter{Department: tt.department}) {
+			ids = append(ids, s.ID)
+		}
+		if !reflect.DeepEqual(ids, tt.wantIDs) {
+			t.Errorf("students in department %q = %v, want %v", tt.department, ids, tt.wantIDs)
+		}
+	}
+
+	// Updates move a student between index entries.
+	if _, ok := UpdateStudent(3, func(s *models.Student) { s.Department, s.RollNumber = "CSE", "CS-003" }); !ok {
+		t.Fatal("UpdateStudent failed")
+	}
+	if got := len(ListStudents(StudentFilter{Department: "EEE"})); got != 0 {
+		t.Errorf("%d students still indexed under their old department", got)
+	}
+	if got := len(ListStudents(StudentFilter{Department: "cse"})); got != 3 {
+		t.Errorf("%d students in CSE after the update, want 3", got)
+	}
+	if _, found := FindStudentByRollNumber("EE-001"); found {
+		t.Error("old roll number still resolves after the update")
+	}
+	if s, _ := FindStudentByRollNumber("cs-003"); s.ID != 3 {
+		t.Errorf("new roll number resolves to %d, want 3", s.ID)
+	}
+}
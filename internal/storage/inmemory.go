@@ -2,32 +2,87 @@ package storage
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"log"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
 
+	_ "go-placement-policy/internal/logging" // Installs the JSON logger before init() below logs the data load.
 	"go-placement-policy/internal/models"
 )
 
 var (
 	ActivePolicyConfig models.PolicyConfig
 	PolicyConfigMutex sync.RWMutex
-	Students           []models.Student
-	Companies          []models.Company
+
+	// Students and Companies are guarded by their own mutexes and indexed by ID (see index.go).
+	// Access them through the functions in students.go and companies.go rather than directly.
+	StudentsMutex  sync.RWMutex
+	Students       []models.Student
+	CompaniesMutex sync.RWMutex
+	Companies      []models.Company
 
 	// Cached placement statistics
 	PlacementStatsMutex       sync.RWMutex
 	CachedTotalStudents       int
 	CachedPlacedStudentsCount int
+	placementStatsVersion     int64 // Incremented whenever either count changes.
+
+	// Applications submitted by students through the self-service API.
+	ApplicationsMutex sync.RWMutex
+	Applications      []models.Application
+	nextApplicationID = 1
 )
 
 func init() {
-	loadStudentsFromFile("internal/data/students.json")
-	loadCompaniesFromFile("internal/data/company.json")
+	rebuildStudentIndexes()
+	rebuildCompanyIndexes()
 	initializeDefaultPolicies()
+}
+
+// Load replaces the students and companies with those in students.json and company.json in dataDir, and must be
+// called before the server starts. A missing file leaves an empty list, so a new deployment can start without
+// data; an unreadable or malformed file is an error.
+func Load(dataDir string) error {
+	var students []models.Student
+	if err := loadFile(filepath.Join(dataDir, "students.json"), &students); err != nil {
+		return err
+	}
+	var companies []models.Company
+	if err := loadFile(filepath.Join(dataDir, "company.json"), &companies); err != nil {
+		return err
+	}
+
+	StudentsMutex.Lock()
+	Students = append([]models.Student{}, students...)
+	StudentsMutex.Unlock()
+	CompaniesMutex.Lock()
+	Companies = append([]models.Company{}, companies...)
+	CompaniesMutex.Unlock()
+
+	rebuildStudentIndexes()
+	rebuildCompanyIndexes()
 	UpdatePlacementStats()
+	slog.Info("loaded data", "dataDir", dataDir, "students", len(students), "companies", len(companies))
+	return nil
+}
+
+// loadFile decodes the JSON array in path into v, leaving v untouched if the file does not exist.
+func loadFile(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		slog.Warn("data file not found; starting without its records", "path", path)
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("reading data file: %w", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("parsing data file %s: %w", path, err)
+	}
+	return nil
 }
 
 func initializeDefaultPolicies() {
@@ -37,6 +92,7 @@ func initializeDefaultPolicies() {
 	// Default values for the placement policy configuration.
 	// These can be overridden via the API.
 	ActivePolicyConfig = models.PolicyConfig{
+		Version: 1,
 		MaximumCompanies: struct {
 			Enabled bool `json:"enabled"`
 			MaxN    int  `json:"maxN"`
@@ -63,104 +119,38 @@ func initializeDefaultPolicies() {
 			RequiredHikePercentage float64 `json:"requiredHikePercentage"`
 		}{Enabled: true, L1ThresholdAmount: 2000000, L2ThresholdAmount: 1000000, RequiredHikePercentage: 30}, // L1 > 20L, L2 > 10L, L2 needs 30% hike.
 	}
-	log.Println("Default policy configuration initialized.")
+	slog.Info("default policy configuration initialized")
 }
 
-// loadCompaniesFromFile attempts to load company data from a JSON file.
-// Similar to loadStudentsFromFile, it tries paths relative to project root and parent directory.
-func loadCompaniesFromFile(filePathFromProjectRoot string) {
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Printf("Warning: Could not get current working directory: %v. Company data might not load.", err)
-		Companies = []models.Company{}
-		return
-	}
-
-	absPath := filepath.Join(wd, filePathFromProjectRoot)
-
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		altPath := filepath.Join(wd, "..", filePathFromProjectRoot) 
-		if _, errStatAlt := os.Stat(altPath); errStatAlt == nil {
-			absPath = altPath
-		} else {
-			log.Printf("Warning: Could not find companies data file at '%s' or '%s'. Initializing with empty company list.", absPath, altPath)
-			Companies = []models.Company{}
-			return
-		}
-	}
-
-	data, err := ioutil.ReadFile(absPath)
-	if err != nil {
-		log.Printf("Warning: Could not read companies data file '%s': %v. Initializing with empty company list.", absPath, err)
-		Companies = []models.Company{}
-		return
-	}
-
-	if err := json.Unmarshal(data, &Companies); err != nil {
-		log.Printf("Warning: Could not unmarshal companies data from '%s': %v. Initializing with empty company list.", absPath, err)
-		Companies = []models.Company{}
-		return
-	}
-	log.Printf("Successfully loaded %d companies from %s", len(Companies), absPath)
-}
-
-// loadStudentsFromFile attempts to load student data from a JSON file.
-// It first tries the provided path relative to the project root,
-// then attempts a path relative to the parent directory (common when running 'go run' from a subdirectory like cmd/api).
-// If loading fails, it initializes an empty student list and logs a warning.
-func loadStudentsFromFile(filePathFromProjectRoot string) {
-	wd, err := os.Getwd()
-	if err != nil {
-		log.Printf("Warning: Could not get current working directory: %v. Student data might not load.", err)
-		Students = []models.Student{}
-		return
-	}
-
-	absPath := filepath.Join(wd, filePathFromProjectRoot)
-
-	// Attempt to find the file, checking an alternative path if the first doesn't exist.
-	// This handles cases where the CWD might be project root or cmd/api/.
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		altPath := filepath.Join(wd, "..", filePathFromProjectRoot) // Path if CWD is cmd/api
-		if _, errStatAlt := os.Stat(altPath); errStatAlt == nil {
-			absPath = altPath
-		} else {
-			log.Printf("Warning: Could not find students data file at '%s' or '%s'. Initializing with empty student list.", absPath, altPath)
-			Students = []models.Student{}
-			return
-		}
-	}
-
-	data, err := ioutil.ReadFile(absPath)
-	if err != nil {
-		log.Printf("Warning: Could not read students data file '%s': %v. Initializing with empty student list.", absPath, err)
-		Students = []models.Student{}
-		return
-	}
-
-	if err := json.Unmarshal(data, &Students); err != nil {
-		log.Printf("Warning: Could not unmarshal students data from '%s': %v. Initializing with empty student list.", absPath, err)
-		Students = []models.Student{}
-		return
-	}
-	log.Printf("Successfully loaded %d students from %s", len(Students), absPath)
-	UpdatePlacementStats() // Ensure placement stats are current after loading student data.
+// PlacementStatsVersion identifies the current placement counts; it changes only when the counts do.
+func PlacementStatsVersion() int64 {
+	PlacementStatsMutex.RLock()
+	defer PlacementStatsMutex.RUnlock()
+	return placementStatsVersion
 }
 
 // UpdatePlacementStats recalculates and caches the total number of students and placed students.
 // This function is mutex-protected and should be invoked whenever the student list changes
 // (e.g., after loading from file, creating a new student) or a student's placement status is updated.
 func UpdatePlacementStats() {
-	PlacementStatsMutex.Lock()
-	defer PlacementStatsMutex.Unlock()
-
-	CachedTotalStudents = len(Students)
+	StudentsMutex.RLock()
+	totalCount := len(Students)
 	placedCount := 0
 	for _, s := range Students {
 		if s.IsPlaced {
 			placedCount++
 		}
 	}
+	StudentsMutex.RUnlock()
+
+	PlacementStatsMutex.Lock()
+	defer PlacementStatsMutex.Unlock()
+
+	if totalCount != CachedTotalStudents || placedCount != CachedPlacedStudentsCount {
+		placementStatsVersion++
+	}
+	CachedTotalStudents = totalCount
 	CachedPlacedStudentsCount = placedCount
-	log.Printf("Placement statistics updated: Total Students = %d, Placed Students = %d", CachedTotalStudents, CachedPlacedStudentsCount)
+	bumpRevision() // Every student mutation ends here, so this also covers changes that leave the counts alone.
+	slog.Debug("placement statistics updated", "totalStudents", CachedTotalStudents, "placedStudents", CachedPlacedStudentsCount)
 }
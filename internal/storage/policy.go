@@ -0,0 +1,41 @@
+package storage
+
+import "go-placement-policy/internal/models"
+
+// ActivePolicy returns a copy of the active policy configuration.
+func ActivePolicy() models.PolicyConfig {
+	PolicyConfigMutex.RLock()
+	defer PolicyConfigMutex.RUnlock()
+	return ActivePolicyConfig
+}
+
+// ConfigurePolicy replaces the active policy configuration. It is UpdatePolicy with a transform that ignores the current config.
+func ConfigurePolicy(config models.PolicyConfig, expectedVersion int64) (previous models.PolicyConfig, updated models.PolicyConfig, err error) {
+	return UpdatePolicy(expectedVersion, func(models.PolicyConfig) (models.PolicyConfig, error) {
+		return config, nil
+	})
+}
+
+// UpdatePolicy atomically derives a new active policy configuration from the current one and assigns it the next version.
+// If expectedVersion is non-zero and does not match the active version, nothing changes and ErrVersionConflict is returned.
+// An error from transform (e.g. a validation failure) is returned unchanged and leaves the active config in place.
+func UpdatePolicy(expectedVersion int64, transform func(current models.PolicyConfig) (models.PolicyConfig, error)) (previous models.PolicyConfig, updated models.PolicyConfig, err error) {
+	PolicyConfigMutex.Lock()
+	previous = ActivePolicyConfig
+	if expectedVersion != 0 && previous.Version != expectedVersion {
+		PolicyConfigMutex.Unlock()
+		return previous, previous, ErrVersionConflict
+	}
+	updated, err = transform(previous)
+	if err != nil {
+		PolicyConfigMutex.Unlock()
+		return previous, previous, err
+	}
+	updated.Version = previous.Version + 1
+	ActivePolicyConfig = updated
+	bumpRevision()
+	PolicyConfigMutex.Unlock()
+
+	policyChanged()
+	return previous, updated, nil
+}
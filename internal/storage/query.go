@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"strings"
+
+	"go-placement-policy/internal/models"
+)
+
+// StudentFilter selects students in ListStudents. Nil pointers and empty strings match everything.
+type StudentFilter struct {
+	IsPlaced     *bool
+	MinCGPA      *float64
+	MaxCGPA      *float64
+	MinSalary    *float64 // Bounds on CurrentSalary.
+	MaxSalary    *float64
+	DreamCompany string // Case-insensitive exact match.
+	Department   string // Case-insensitive exact match.
+	NameContains string // Case-insensitive substring of FullName.
+}
+
+// Matches reports whether the student satisfies every criterion in the filter.
+func (f StudentFilter) Matches(s models.Student) bool {
+	if f.IsPlaced != nil && s.IsPlaced != *f.IsPlaced {
+		return false
+	}
+	if f.MinCGPA != nil && s.CGPA < *f.MinCGPA {
+		return false
+	}
+	if f.MaxCGPA != nil && s.CGPA > *f.MaxCGPA {
+		return false
+	}
+	if f.MinSalary != nil && s.CurrentSalary < *f.MinSalary {
+		return false
+	}
+	if f.MaxSalary != nil && s.CurrentSalary > *f.MaxSalary {
+		return false
+	}
+	if f.DreamCompany != "" && !strings.EqualFold(s.DreamCompanyName, f.DreamCompany) {
+		return false
+	}
+	if f.Department != "" && !strings.EqualFold(s.Department, f.Department) {
+		return false
+	}
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(s.FullName), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	return true
+}
+
+// CompanyFilter selects companies in ListCompanies. Nil pointers and empty strings match everything.
+type CompanyFilter struct {
+	MinSalary    *float64 // Bounds on OfferedSalary.
+	MaxSalary    *float64
+	NameContains string // Case-insensitive substring of Name.
+}
+
+// Matches reports whether the company satisfies every criterion in the filter.
+func (f CompanyFilter) Matches(c models.Company) bool {
+	if f.MinSalary != nil && c.OfferedSalary < *f.MinSalary {
+		return false
+	}
+	if f.MaxSalary != nil && c.OfferedSalary > *f.MaxSalary {
+		return false
+	}
+	if f.NameContains != "" && !strings.Contains(strings.ToLower(c.Name), strings.ToLower(f.NameContains)) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,16 @@
+package storage
+
+import "sync/atomic"
+
+// revision counts changes to students, companies, drives and the policy configuration. Derived data such as
+// placement analytics can be cached for as long as the revision it was computed at is current.
+var revision atomic.Int64
+
+// Revision returns the current data revision. It only ever increases.
+func Revision() int64 {
+	return revision.Load()
+}
+
+func bumpRevision() {
+	revision.Add(1)
+}
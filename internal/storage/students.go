@@ -0,0 +1,207 @@
+package storage
+
+import (
+	"sort"
+
+	"go-placement-policy/internal/models"
+)
+
+// FindStudentByID returns a copy of the student with the given ID.
+func FindStudentByID(id int) (models.Student, bool) {
+	StudentsMutex.RLock()
+	defer StudentsMutex.RUnlock()
+	if pos, ok := studentPositions[id]; ok {
+		return Students[pos], true
+	}
+	return models.Student{}, false
+}
+
+// AllStudents returns a copy of every student in storage order.
+func AllStudents() []models.Student {
+	StudentsMutex.RLock()
+	defer StudentsMutex.RUnlock()
+	result := make([]models.Student, len(Students))
+	copy(result, Students)
+	return result
+}
+
+// AddStudent stores a new student under a freshly allocated ID and returns the stored copy.
+// It returns ErrDuplicateRollNumber if another student already has the student's roll number.
+func AddStudent(student models.Student) (models.Student, error) {
+	StudentsMutex.Lock()
+	if rollNumberTaken(student.RollNumber, 0) {
+		StudentsMutex.Unlock()
+		return models.Student{}, ErrDuplicateRollNumber
+	}
+	student.ID = allocateStudentID()
+	student.Version = 1
+	studentPositions[student.ID] = len(Students)
+	Students = append(Students, student)
+	indexStudent(student)
+	StudentsMutex.Unlock()
+
+	studentChanged(student.ID)
+	UpdatePlacementStats() // Crucial to update stats after adding a new student.
+	return student, nil
+}
+
+// FindStudentByRollNumber returns a copy of the student with the given roll number, compared case-insensitively.
+func FindStudentByRollNumber(rollNumber string) (models.Student, bool) {
+	StudentsMutex.RLock()
+	defer StudentsMutex.RUnlock()
+	if id, ok := studentsByRollNumber[IndexKey(rollNumber)]; ok {
+		return Students[studentPositions[id]], true
+	}
+	return models.Student{}, false
+}
+
+// UpdateStudent applies mutate to the stored student with the given ID and returns the updated copy.
+// It is the unconditional form of UpdateStudentIfVersion.
+func UpdateStudent(id int, mutate func(*models.Student)) (models.Student, bool) {
+	updated, err := UpdateStudentIfVersion(id, 0, mutate)
+	return updated, err == nil
+}
+
+// UpdateStudentIfVersion applies mutate to the stored student with the given ID and returns the updated copy.
+// If expectedVersion is non-zero and does not match the stored version, nothing changes and ErrVersionConflict is returned.
+// mutate cannot change the ID or version; the version is incremented after every successful update.
+// If mutate gives the student a roll number held by another student, the change is undone and ErrDuplicateRollNumber is returned.
+// Placement stats are refreshed afterwards since mutate may change IsPlaced.
+func UpdateStudentIfVersion(id int, expectedVersion int64, mutate func(*models.Student)) (models.Student, error) {
+	StudentsMutex.Lock()
+	pos, found := studentPositions[id]
+	if !found {
+		StudentsMutex.Unlock()
+		return models.Student{}, ErrNotFound
+	}
+	current := Students[pos]
+	if expectedVersion != 0 && current.Version != expectedVersion {
+		StudentsMutex.Unlock()
+		return current, ErrVersionConflict
+	}
+	unindexStudent(current)
+	mutate(&Students[pos])
+	Students[pos].ID = id
+	Students[pos].Version = current.Version + 1
+	if rollNumberTaken(Students[pos].RollNumber, id) {
+		Students[pos] = current
+		indexStudent(current)
+		StudentsMutex.Unlock()
+		return current, ErrDuplicateRollNumber
+	}
+	indexStudent(Students[pos])
+	updated := Students[pos]
+	StudentsMutex.Unlock()
+
+	studentChanged(id)
+	UpdatePlacementStats()
+	return updated, nil
+}
+
+// ReplaceStudent overwrites the stored student with the same ID, subject to the same version check as
+// UpdateStudentIfVersion, and returns the previous and new versions of the record.
+func ReplaceStudent(student models.Student, expectedVersion int64) (previous models.Student, updated models.Student, err error) {
+	updated, err = UpdateStudentIfVersion(student.ID, expectedVersion, func(s *models.Student) {
+		previous = *s
+		*s = student
+	})
+	return previous, updated, err
+}
+
+// RecordOffer marks the student as placed at the given salary and, if the student applied to the company,
+// moves that application to the offered status. It returns the student before and after the change.
+func RecordOffer(studentID int, companyID string, salary float64, expectedVersion int64) (before models.Student, after models.Student, err error) {
+	after, err = UpdateStudentIfVersion(studentID, expectedVersion, func(s *models.Student) {
+		before = *s
+		s.IsPlaced = true
+		s.CurrentSalary = salary
+		s.PlacedCompanyID = companyID
+	})
+	if err != nil {
+		return before, after, err
+	}
+
+	ApplicationsMutex.Lock()
+	for i := range Applications {
+		if Applications[i].StudentID == studentID && Applications[i].CompanyID == companyID {
+			Applications[i].Status = models.ApplicationStatusOffered
+		}
+	}
+	ApplicationsMutex.Unlock()
+	return before, after, nil
+}
+
+// ListStudents returns a copy of every student matching the filter, in storage order.
+// When the filter constrains an indexed field, only students in the smallest matching index are examined.
+func ListStudents(f StudentFilter) []models.Student {
+	StudentsMutex.RLock()
+	defer StudentsMutex.RUnlock()
+
+	result := []models.Student{}
+	candidates := candidateStudentIDs(f)
+	if candidates == nil {
+		for _, s := range Students {
+			if f.Matches(s) {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+
+	positions := make([]int, 0, len(candidates))
+	for id := range candidates {
+		positions = append(positions, studentPositions[id])
+	}
+	sort.Ints(positions) // Preserve storage order, as the full scan does.
+	for _, pos := range positions {
+		if f.Matches(Students[pos]) {
+			result = append(result, Students[pos])
+		}
+	}
+	return result
+}
+
+// ImportStudents applies a bulk upsert atomically. plan runs while StudentsMutex is held for writing, so the
+// students it sees through lookup cannot change before the batch is stored. It returns the records to store,
+// where a zero ID creates a new student and any other ID replaces that student, and whether to commit them.
+// Nothing is stored unless commit is true. Placement stats are refreshed once after the whole batch.
+// The stored copies are returned in plan order, along with the record each one replaced (zero for creates).
+func ImportStudents(plan func(lookup func(rollNumber string) (models.Student, bool)) (batch []models.Student, commit bool)) (stored []models.Student, previous []models.Student) {
+	StudentsMutex.Lock()
+	lookup := func(rollNumber string) (models.Student, bool) {
+		if id, ok := studentsByRollNumber[IndexKey(rollNumber)]; ok {
+			return Students[studentPositions[id]], true
+		}
+		return models.Student{}, false
+	}
+	batch, commit := plan(lookup)
+	if !commit {
+		StudentsMutex.Unlock()
+		return nil, nil
+	}
+
+	for _, student := range batch {
+		var before models.Student
+		if pos, exists := studentPositions[student.ID]; exists && student.ID != 0 {
+			before = Students[pos]
+			unindexStudent(before)
+			student.Version = before.Version + 1
+			Students[pos] = student
+		} else {
+			student.ID = allocateStudentID()
+			student.Version = 1
+			studentPositions[student.ID] = len(Students)
+			Students = append(Students, student)
+		}
+		indexStudent(student)
+		stored = append(stored, student)
+		previous = append(previous, before)
+	}
+	StudentsMutex.Unlock()
+
+	for _, student := range stored {
+		studentChanged(student.ID)
+	}
+	UpdatePlacementStats()
+	return stored, previous
+}
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"go-placement-policy/internal/models"
+)
+
+func TestStudentVersions(t *testing.T) {
+	student, err := AddStudent(models.Student{FullName: "Versioned", RollNumber: "VER-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AddStudent(models.Student{FullName: "Other", RollNumber: "VER-2"}); err != nil {
+		t.Fatal(err)
+	}
+	if student.Version != 1 {
+		t.Fatalf("new student has version %d, want 1", student.Version)
+	}
+	id := student.ID
+
+	tests := []struct {
+		name        string
+		mutate      func() (models.Student, error)
+		wantErr     error
+		wantVersion int64 // The stored version afterwards.
+	}{
+		{"update", func() (models.Student, error) {
+			return UpdateStudentIfVersion(id, 0, func(s *models.Student) { s.CGPA = 8 })
+		}, nil, 2},
+		{"update at the current version", func() (models.Student, error) {
+			return UpdateStudentIfVersion(id, 2, func(s *models.Student) { s.CGPA = 8.5 })
+		}, nil, 3},
+		{"update at a stale version", func() (models.Student, error) {
+			return UpdateStudentIfVersion(id, 2, func(s *models.Student) { s.CGPA = 1 })
+		}, ErrVersionConflict, 3},
+		{"replace at the current version", func() (models.Student, error) {
+			_, updated, err := ReplaceStudent(models.Student{ID: id, FullName: "Replaced", RollNumber: "VER-1", Version: 99}, 3)
+			return updated, err
+		}, nil, 4},
+		{"replace at a stale version", func() (models.Student, error) {
+			_, updated, err := ReplaceStudent(models.Student{ID: id, FullName: "Stale"}, 3)
+			return updated, err
+		}, ErrVersionConflict, 4},
+		{"duplicate roll number", func() (models.Student, error) {
+			return UpdateStudentIfVersion(id, 0, func(s *models.Student) { s.RollNumber = "ver-2" })
+		}, ErrDuplicateRollNumber, 4},
+		{"record offer", func() (models.Student, error) {
+			_, after, err := RecordOffer(id, "VER-CO", 900000, 4)
+			return after, err
+		}, nil, 5},
+		{"record offer at a stale version", func() (models.Student, error) {
+			_, after, err := RecordOffer(id, "VER-CO", 100, 4)
+			return after, err
+		}, ErrVersionConflict, 5},
+		{"import", func() (models.Student, error) {
+			stored, _ := ImportStudents(func(lookup func(string) (models.Student, bool)) ([]models.Student, bool) {
+				s, _ := lookup("VER-1")
+				s.Department = "CSE"
+				return []models.Student{s}, true
+			})
+			return stored[0], nil
+		}, nil, 6},
+		{"unknown student", func() (models.Student, error) {
+			return UpdateStudentIfVersion(-1, 0, func(*models.Student) {})
+		}, ErrNotFound, 6},
+	}
+	for _, tt := range tests {
+		result, err := tt.mutate()
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		stored, _ := FindStudentByID(id)
+		if stored.Version != tt.wantVersion {
+			t.Errorf("%s: stored version %d, want %d", tt.name, stored.Version, tt.wantVersion)
+		}
+		if err == nil && result.Version != tt.wantVersion {
+			t.Errorf("%s: returned version %d, want %d", tt.name, result.Version, tt.wantVersion)
+		}
+		if errors.Is(err, ErrVersionConflict) && result.Version != stored.Version {
+			t.Errorf("%s: conflict returned version %d, want the current %d", tt.name, result.Version, stored.Version)
+		}
+	}
+	if stored, _ := FindStudentByID(id); stored.FullName != "Replaced" || stored.CGPA != 0 || stored.CurrentSalary != 900000 {
+		t.Errorf("stored student %+v; a rejected change was applied", stored)
+	}
+}
+
+func TestCompanyVersions(t *testing.T) {
+	importCompany := func(name string, commit bool) {
+		ImportCompanies(func(func(string) (models.Company, bool)) ([]models.Company, bool) {
+			return []models.Company{{ID: "VER-CO", Name: name, Version: 42}}, commit
+		})
+	}
+	for i, step := range []struct {
+		name        string
+		commit      bool
+		wantVersion int64
+	}{
+		{"First", true, 1},
+		{"Second", true, 2},
+		{"Not committed", false, 2},
+		{"Third", true, 3},
+	} {
+		importCompany(step.name, step.commit)
+		company, _ := FindCompanyByID("VER-CO")
+		if company.Version != step.wantVersion {
+			t.Errorf("step %d (%s): version %d, want %d", i, step.name, company.Version, step.wantVersion)
+		}
+	}
+}
+
+func TestPolicyVersions(t *testing.T) {
+	start := ActivePolicy()
+	t.Cleanup(func() { ConfigurePolicy(start, 0) })
+	v := start.Version
+	errInvalid := errors.New("invalid")
+
+	tests := []struct {
+		name        string
+		expected    int64
+		transform   func(models.PolicyConfig) (models.PolicyConfig, error)
+		wantErr     error
+		wantVersion int64
+	}{
+		{"unconditional", 0, func(c models.PolicyConfig) (models.PolicyConfig, error) { return c, nil }, nil, v + 1},
+		{"at the current version", v + 1, func(c models.PolicyConfig) (models.PolicyConfig, error) {
+			c.DreamOffer.Enabled = !c.DreamOffer.Enabled
+			return c, nil
+		}, nil, v + 2},
+		{"at a stale version", v + 1, func(c models.PolicyConfig) (models.PolicyConfig, error) { return c, nil }, ErrVersionConflict, v + 2},
+		{"rejected by the transform", 0, func(c models.PolicyConfig) (models.PolicyConfig, error) {
+			return models.PolicyConfig{}, errInvalid
+		}, errInvalid, v + 2},
+		{"version set by the transform is ignored", 0, func(c models.PolicyConfig) (models.PolicyConfig, error) {
+			c.Version = 1000
+			return c, nil
+		}, nil, v + 3},
+	}
+	for _, tt := range tests {
+		_, updated, err := UpdatePolicy(tt.expected, tt.transform)
+		if !errors.Is(err, tt.wantErr) {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if active := ActivePolicy().Version; active != tt.wantVersion || updated.Version != tt.wantVersion {
+			t.Errorf("%s: active version %d, returned %d; want %d", tt.name, active, updated.Version, tt.wantVersion)
+		}
+	}
+	if ActivePolicy().DreamOffer.Enabled == start.DreamOffer.Enabled {
+		t.Error("the change made at the current version was lost")
+	}
+}
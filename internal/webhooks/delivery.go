@@ -0,0 +1,321 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/events"
+)
+
+// Headers sent with every delivery. The signature is "sha256=" followed by the hex HMAC-SHA256 of
+// "<timestamp>.<body>" keyed with the webhook's secret, so receivers can reject forged or replayed requests.
+const (
+	HeaderEvent     = "X-Placement-Event"
+	HeaderDelivery  = "X-Placement-Delivery"
+	HeaderTimestamp = "X-Placement-Timestamp"
+	HeaderSignature = "X-Placement-Signature"
+)
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusDead      = "dead" // Every attempt failed; the delivery is in the dead-letter list.
+)
+
+// Retry settings. A failed attempt n (1-based) is retried after BaseBackoff * 2^(n-1), capped at MaxBackoff,
+// until MaxAttempts have been made. Workers bounds how many attempts are in flight at once. They are variables
+// so tests can shorten them.
+var (
+	Workers     = 4
+	MaxAttempts = 6
+	BaseBackoff = 2 * time.Second
+	MaxBackoff  = 5 * time.Minute
+	Client      = &http.Client{Timeout: 10 * time.Second}
+)
+
+// MaxPending bounds the deliveries awaiting an attempt, whether queued or waiting out a backoff. Deliveries
+// created beyond it go straight to the dead-letter list, so an unreachable receiver cannot grow memory without
+// limit. It is a variable so tests can lower it.
+var MaxPending = 1000
+
+// logSize bounds the number of finished deliveries kept in the log; the oldest are dropped first.
+const logSize = 1000
+
+// Attempt is one HTTP request made for a delivery.
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// Delivery is one event sent to one webhook, with the history of its attempts.
+type Delivery struct {
+	ID            int64      `json:"id"`
+	WebhookID     int        `json:"webhookId"`
+	URL           string     `json:"url"`
+	EventID       int64      `json:"eventId"`
+	EventType     string     `json:"eventType"`
+	Status        string     `json:"status"`
+	Attempts      []Attempt  `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	Error         string     `json:"error,omitempty"` // Why a delivery was dead-lettered without an attempt.
+
+	payload []byte
+	secret  string
+	round   int // Attempts made since the delivery was created or last redelivered.
+}
+
+var (
+	deliveriesMutex sync.Mutex
+	deliveries      []*Delivery // Oldest first.
+	nextDeliveryID  int64       = 1
+	pending         int         // Deliveries with StatusPending, bounded by MaxPending.
+)
+
+// The queue holds deliveries whose next attempt is due. Deliveries waiting out a backoff are not in it; a timer
+// puts them back, so a slow receiver never holds a worker between attempts.
+var (
+	queueMutex sync.Mutex
+	queue      []*Delivery
+	queueReady = make(chan struct{}, 1)
+	startOnce  sync.Once
+)
+
+// Start subscribes webhooks to published events and starts the delivery workers. Publishing only queues
+// deliveries, so it never waits for a receiver.
+func Start() {
+	startWorkers()
+	events.AddListener(enqueue)
+}
+
+// startWorkers starts Workers delivery workers, once.
+func startWorkers() {
+	startOnce.Do(func() {
+		for i := 0; i < max(Workers, 1); i++ {
+			go work()
+		}
+	})
+}
+
+// schedule queues d for its next attempt and wakes a worker.
+func schedule(d *Delivery) {
+	queueMutex.Lock()
+	queue = append(queue, d)
+	queueMutex.Unlock()
+	wake()
+}
+
+func wake() {
+	select {
+	case queueReady <- struct{}{}:
+	default: // A wake-up is already pending.
+	}
+}
+
+// work runs one attempt at a time for deliveries taken from the queue.
+func work() {
+	for {
+		queueMutex.Lock()
+		if len(queue) == 0 {
+			queueMutex.Unlock()
+			<-queueReady
+			continue
+		}
+		d := queue[0]
+		queue = queue[1:]
+		more := len(queue) > 0
+		queueMutex.Unlock()
+		if more {
+			wake() // Let another idle worker pick up the rest.
+		}
+		attempt(d)
+	}
+}
+
+// enqueue creates a delivery of e for every webhook subscribed to it. Deliveries that do not fit in the queue
+// are dead-lettered at once.
+func enqueue(e events.Event) {
+	hooks := subscribed(e)
+	if len(hooks) == 0 {
+		return
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		slog.Warn("could not encode event for webhooks", "eventId", e.ID, "error", err)
+		return
+	}
+	for _, h := range hooks {
+		d := &Delivery{WebhookID: h.ID, URL: h.URL, EventID: e.ID, EventType: e.Type, Status: StatusPending,
+			Attempts: []Attempt{}, payload: payload, secret: h.Secret}
+		deliveriesMutex.Lock()
+		d.ID = nextDeliveryID
+		nextDeliveryID++
+		full := pending >= MaxPending
+		if full {
+			d.Status = StatusDead
+			d.Error = ErrQueueFull.Error()
+		} else {
+			pending++
+		}
+		deliveries = append(deliveries, d)
+		trimLog()
+		deliveriesMutex.Unlock()
+		if full {
+			slog.Warn("webhook delivery queue is full; dead-lettered the delivery", "deliveryId", d.ID, "eventId", e.ID, "url", d.URL)
+			continue
+		}
+		schedule(d)
+	}
+}
+
+// trimLog drops the oldest finished deliveries once the log exceeds logSize plus the pending deliveries. Dead
+// letters are dropped last, so the dead-letter list survives bursts of successful deliveries. deliveriesMutex
+// must be held.
+func trimLog() {
+	for _, drop := range []string{StatusSucceeded, StatusDead} {
+		for i := 0; len(deliveries) > logSize+pending && i < len(deliveries); {
+			if deliveries[i].Status == drop {
+				deliveries = append(deliveries[:i], deliveries[i+1:]...)
+				continue
+			}
+			i++
+		}
+	}
+}
+
+// attempt makes the next attempt at d. If it fails, d is queued again after its backoff, or moved to the
+// dead-letter list once MaxAttempts have failed.
+func attempt(d *Delivery) {
+	result := send(d)
+	deliveriesMutex.Lock()
+	d.Attempts = append(d.Attempts, result)
+	d.round++
+	d.NextAttemptAt = nil
+	round := d.round
+	switch {
+	case result.Error == "":
+		d.Status = StatusSucceeded
+		pending--
+	case round >= MaxAttempts:
+		d.Status = StatusDead
+		pending--
+	default:
+		next := time.Now().UTC().Add(Backoff(round))
+		d.NextAttemptAt = &next
+	}
+	status := d.Status
+	deliveriesMutex.Unlock()
+
+	switch status {
+	case StatusDead:
+		slog.Warn("webhook delivery failed", "deliveryId", d.ID, "eventId", d.EventID, "url", d.URL, "attempts", round, "error", result.Error)
+	case StatusPending:
+		time.AfterFunc(Backoff(round), func() { schedule(d) })
+	}
+}
+
+// Backoff returns the delay after the given failed attempt (1-based).
+func Backoff(attempt int) time.Duration {
+	delay := BaseBackoff
+	for i := 1; i < attempt && delay < MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxBackoff)
+}
+
+// Sign returns the signature header value for a body sent at the given Unix timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// send makes one attempt. Any 2xx response is a success.
+func send(d *Delivery) Attempt {
+	start := time.Now()
+	attempt := Attempt{At: start.UTC()}
+	req, err := http.NewRequest(http.MethodPost, d.URL, bytes.NewReader(d.payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	timestamp := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-placement-policy-webhooks")
+	req.Header.Set(HeaderEvent, d.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(d.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.secret, timestamp, d.payload))
+
+	resp, err := Client.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10)) // Drain so the connection can be reused.
+	resp.Body.Close()
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = "unexpected status " + resp.Status
+	}
+	return attempt
+}
+
+// Deliveries returns copies of the logged deliveries, newest first, optionally limited to one webhook
+// (webhookID non-zero) and one status (status non-empty).
+func Deliveries(webhookID int, status string) []Delivery {
+	deliveriesMutex.Lock()
+	defer deliveriesMutex.Unlock()
+	result := []Delivery{}
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		d := deliveries[i]
+		if (webhookID != 0 && d.WebhookID != webhookID) || (status != "" && d.Status != status) {
+			continue
+		}
+		c := *d
+		c.Attempts = append([]Attempt{}, d.Attempts...)
+		result = append(result, c)
+	}
+	return result
+}
+
+// Redeliver moves a dead delivery back to pending and starts a fresh round of attempts. It fails with
+// ErrQueueFull while MaxPending deliveries are pending.
+func Redeliver(id int64) (Delivery, error) {
+	deliveriesMutex.Lock()
+	defer deliveriesMutex.Unlock()
+	for _, d := range deliveries {
+		if d.ID != id {
+			continue
+		}
+		if d.Status != StatusDead {
+			return Delivery{}, ErrNotDead
+		}
+		if pending >= MaxPending {
+			return Delivery{}, ErrQueueFull
+		}
+		d.Status = StatusPending
+		d.Error = ""
+		d.round = 0
+		pending++
+		c := *d
+		c.Attempts = append([]Attempt{}, d.Attempts...)
+		schedule(d)
+		return c, nil
+	}
+	return Delivery{}, ErrDeliveryNotFound
+}
@@ -0,0 +1,273 @@
+package webhooks
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-placement-policy/internal/events"
+)
+
+func TestSign(t *testing.T) {
+	got := Sign("topsecret", 1700000000, []byte(`{"id":1}`))
+	want := "sha256=2b65dcefa7f51ac7ee445bc446105a9557bbfad37a1d5ca4c2480b0b939d1691"
+	if got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+	if Sign("other", 1700000000, []byte(`{"id":1}`)) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("topsecret", 1700000001, []byte(`{"id":1}`)) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	shortenRetries(t, 10, time.Second, 5*time.Second)
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{9, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestDeliveryRetriesUntilSuccess(t *testing.T) {
+	shortenRetries(t, 5, 20*time.Millisecond, time.Second)
+	receiver := newReceiver(t, http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK)
+	hook := addHook(t, receiver.URL, "secret-1")
+
+	enqueue(events.Event{ID: 41, Topic: "policy", Type: "policy.update", Time: time.Now().UTC()})
+	d := waitForStatus(t, hook.ID, StatusSucceeded)
+
+	if len(d.Attempts) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(d.Attempts))
+	}
+	for i, want := range []int{http.StatusServiceUnavailable, http.StatusInternalServerError, http.StatusOK} {
+		if d.Attempts[i].StatusCode != want {
+			t.Errorf("attempt %d: status %d, want %d", i+1, d.Attempts[i].StatusCode, want)
+		}
+	}
+	if gap := d.Attempts[1].At.Sub(d.Attempts[0].At); gap < Backoff(1) {
+		t.Errorf("second attempt came %v after the first, want at least %v", gap, Backoff(1))
+	}
+	if gap := d.Attempts[2].At.Sub(d.Attempts[1].At); gap < Backoff(2) {
+		t.Errorf("third attempt came %v after the second, want at least %v", gap, Backoff(2))
+	}
+
+	req := receiver.last()
+	if req.header.Get(HeaderEvent) != "policy.update" {
+		t.Errorf("%s = %q", HeaderEvent, req.header.Get(HeaderEvent))
+	}
+	if req.header.Get(HeaderDelivery) != strconv.FormatInt(d.ID, 10) {
+		t.Errorf("%s = %q, want %d", HeaderDelivery, req.header.Get(HeaderDelivery), d.ID)
+	}
+	timestamp, err := strconv.ParseInt(req.header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		t.Fatalf("%s: %v", HeaderTimestamp, err)
+	}
+	if got, want := req.header.Get(HeaderSignature), Sign("secret-1", timestamp, req.body); got != want {
+		t.Errorf("%s = %s, want %s", HeaderSignature, got, want)
+	}
+}
+
+func TestDeadLetterAndRedeliver(t *testing.T) {
+	shortenRetries(t, 2, 5*time.Millisecond, time.Second)
+	receiver := newReceiver(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusNoContent)
+	hook := addHook(t, receiver.URL, "secret-2")
+
+	enqueue(events.Event{ID: 42, Topic: "policy", Type: "policy.update", Time: time.Now().UTC()})
+	dead := waitForStatus(t, hook.ID, StatusDead)
+	if len(dead.Attempts) != MaxAttempts {
+		t.Fatalf("dead after %d attempts, want %d", len(dead.Attempts), MaxAttempts)
+	}
+	if dead.NextAttemptAt != nil {
+		t.Error("dead delivery still has a next attempt time")
+	}
+	if !containsDelivery(Deliveries(0, StatusDead), dead.ID) {
+		t.Error("dead delivery missing from the dead-letter list")
+	}
+
+	if _, err := Redeliver(dead.ID); err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	d := waitForStatus(t, hook.ID, StatusSucceeded)
+	if len(d.Attempts) != MaxAttempts+1 {
+		t.Errorf("got %d attempts after redelivery, want %d", len(d.Attempts), MaxAttempts+1)
+	}
+	if _, err := Redeliver(d.ID); !errors.Is(err, ErrNotDead) {
+		t.Errorf("Redeliver of a succeeded delivery: %v, want ErrNotDead", err)
+	}
+	if _, err := Redeliver(-1); !errors.Is(err, ErrDeliveryNotFound) {
+		t.Errorf("Redeliver of an unknown delivery: %v, want ErrDeliveryNotFound", err)
+	}
+}
+
+func TestWorkersBoundConcurrency(t *testing.T) {
+	shortenRetries(t, 1, time.Millisecond, time.Millisecond)
+	var inFlight, peak atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		<-release
+		inFlight.Add(-1)
+	}))
+	t.Cleanup(server.Close)
+	hook := addHook(t, server.URL, "secret-3")
+
+	for i := 0; i < 3*Workers; i++ {
+		enqueue(events.Event{ID: int64(100 + i), Topic: "policy", Type: "policy.update", Time: time.Now().UTC()})
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(Deliveries(hook.ID, StatusSucceeded)) < 3*Workers {
+		if time.Now().After(deadline) {
+			t.Fatal("deliveries did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := peak.Load(); got > int32(Workers) {
+		t.Errorf("%d deliveries were in flight at once, want at most %d", got, Workers)
+	}
+}
+
+func TestFullQueueDeadLettersOverflow(t *testing.T) {
+	shortenRetries(t, 1, time.Millisecond, time.Millisecond)
+	savedMaxPending := MaxPending
+	MaxPending = 2
+	t.Cleanup(func() { MaxPending = savedMaxPending })
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-release }))
+	t.Cleanup(server.Close)
+	hook := addHook(t, server.URL, "secret-4")
+
+	for i := 0; i < 3; i++ {
+		enqueue(events.Event{ID: int64(200 + i), Topic: "policy", Type: "policy.update", Time: time.Now().UTC()})
+	}
+	dead := Deliveries(hook.ID, StatusDead)
+	if len(dead) != 1 || dead[0].EventID != 202 || dead[0].Error != ErrQueueFull.Error() || len(dead[0].Attempts) != 0 {
+		t.Fatalf("dead letters = %+v, want only the third delivery, unattempted", dead)
+	}
+	if _, err := Redeliver(dead[0].ID); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Redeliver with a full queue: %v, want ErrQueueFull", err)
+	}
+
+	close(release)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(Deliveries(hook.ID, StatusSucceeded)) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("queued deliveries did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	d, err := Redeliver(dead[0].ID)
+	if err != nil || d.Status != StatusPending || d.Error != "" {
+		t.Fatalf("Redeliver once the queue drained: %+v, %v", d, err)
+	}
+	for len(Deliveries(hook.ID, StatusSucceeded)) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatal("redelivery did not finish")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// shortenRetries sets the retry settings for one test and starts the workers.
+func shortenRetries(t *testing.T, attempts int, base, ceiling time.Duration) {
+	t.Helper()
+	savedAttempts, savedBase, savedCeiling := MaxAttempts, BaseBackoff, MaxBackoff
+	MaxAttempts, BaseBackoff, MaxBackoff = attempts, base, ceiling
+	t.Cleanup(func() { MaxAttempts, BaseBackoff, MaxBackoff = savedAttempts, savedBase, savedCeiling })
+	startWorkers()
+}
+
+// addHook subscribes a webhook to every event for the duration of the test.
+func addHook(t *testing.T, url, secret string) Webhook {
+	t.Helper()
+	hook, err := Add(Webhook{URL: url, Events: []string{AllEvents}, Secret: secret, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { Delete(hook.ID) })
+	return hook
+}
+
+// waitForStatus waits for the webhook's only delivery to reach status and returns it.
+func waitForStatus(t *testing.T, webhookID int, status string) Delivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		list := Deliveries(webhookID, "")
+		if len(list) == 1 && list[0].Status == status {
+			return list[0]
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("delivery for webhook %d did not become %s: %+v", webhookID, status, list)
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+}
+
+func containsDelivery(list []Delivery, id int64) bool {
+	for _, d := range list {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver is a webhook endpoint that answers with the given status codes in turn, repeating the last one.
+type receiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	requests []receivedRequest
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	rc := &receiver{statuses: statuses}
+	rc.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rc.mutex.Lock()
+		status := rc.statuses[min(len(rc.requests), len(rc.statuses)-1)]
+		rc.requests = append(rc.requests, receivedRequest{header: r.Header.Clone(), body: body})
+		rc.mutex.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rc.Close)
+	return rc
+}
+
+func (rc *receiver) last() receivedRequest {
+	rc.mutex.Lock()
+	defer rc.mutex.Unlock()
+	return rc.requests[len(rc.requests)-1]
+}
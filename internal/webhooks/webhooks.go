@@ -0,0 +1,258 @@
+// Package webhooks delivers change events to external systems, such as the department ERP, as signed HTTP POSTs.
+// Failed deliveries are retried with exponential backoff; deliveries that exhaust their attempts are kept in a
+// dead-letter list from which they can be retried by hand. Subscriptions are saved to a JSON Lines file so they
+// survive restarts; the delivery log is kept in memory.
+package webhooks
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go-placement-policy/internal/events"
+	"go-placement-policy/internal/jsonl"
+)
+
+// Errors returned by the subscription and delivery functions.
+var (
+	ErrNotFound         = errors.New("webhook not found")
+	ErrVersionConflict  = errors.New("webhook version conflict")
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	ErrNotDead          = errors.New("delivery is not in the dead-letter list")
+	ErrQueueFull        = errors.New("webhook delivery queue is full")
+)
+
+// AllEvents subscribes a webhook to every event.
+const AllEvents = "*"
+
+// Webhook is a subscription: events whose type or topic is listed in Events are POSTed to URL, signed with Secret.
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"` // Event types (e.g. "offer.record"), topics (e.g. "policy") or "*".
+	Secret    string    `json:"-"`      // Never returned after creation.
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"createdAt"`
+	Version   int64     `json:"version"`
+}
+
+// Wants reports whether the webhook is subscribed to e.
+func (h Webhook) Wants(e events.Event) bool {
+	if !h.Active {
+		return false
+	}
+	for _, name := range h.Events {
+		if name == AllEvents || name == e.Type || name == e.Topic {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	mutex         sync.RWMutex
+	webhooks      []Webhook
+	nextWebhookID = 1
+	file          *os.File // Subscription log; nil until Open, which keeps subscriptions in memory only.
+)
+
+// record is one line of the subscription log: the whole webhook after it was created or replaced, or the ID of a
+// deleted one. Unlike API responses it carries the secret, so deliveries are still signed after a restart.
+type record struct {
+	Webhook *savedWebhook `json:"webhook,omitempty"`
+	Deleted int           `json:"deleted,omitempty"`
+}
+
+type savedWebhook struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// Open loads the subscriptions saved in the JSON Lines file at path, creating it if needed, and appends every
+// later change to it. The file holds secrets, so it is created readable by its owner only.
+func Open(path string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening webhook subscriptions %s: %w", path, err)
+	}
+	loaded := []Webhook{}
+	nextID := 1
+	repair, err := jsonl.Load(f, func(line []byte) error {
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil {
+			return err
+		}
+		switch {
+		case r.Webhook != nil:
+			h := r.Webhook.Webhook
+			h.Secret = r.Webhook.Secret
+			loaded = append(without(loaded, h.ID), h)
+			nextID = max(nextID, h.ID+1)
+		case r.Deleted != 0:
+			loaded = without(loaded, r.Deleted)
+		default:
+			return errors.New("line has neither a webhook nor a deletion")
+		}
+		return nil
+	})
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("reading webhook subscriptions %s: %w", path, err)
+	}
+	if repair.DroppedBytes > 0 {
+		slog.Warn("webhook subscriptions ended with a torn line; truncated it", "path", path, "droppedBytes", repair.DroppedBytes)
+	}
+
+	if file != nil {
+		file.Close()
+	}
+	file = f
+	webhooks = loaded
+	nextWebhookID = nextID
+	return nil
+}
+
+// without returns hooks minus the webhook with the given ID.
+func without(hooks []Webhook, id int) []Webhook {
+	for i, h := range hooks {
+		if h.ID == id {
+			return append(hooks[:i], hooks[i+1:]...)
+		}
+	}
+	return hooks
+}
+
+// save appends r to the subscription log, if one is open. mutex must be held.
+func save(r record) error {
+	if file == nil {
+		return nil
+	}
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// saveWebhook appends h to the subscription log. mutex must be held.
+func saveWebhook(h Webhook) error {
+	return save(record{Webhook: &savedWebhook{Webhook: h, Secret: h.Secret}})
+}
+
+// GenerateSecret returns a random signing secret for webhooks created without one.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// List returns copies of all webhooks, ordered by ID.
+func List() []Webhook {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	result := make([]Webhook, len(webhooks))
+	copy(result, webhooks)
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Find returns a copy of the webhook with the given ID.
+func Find(id int) (Webhook, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	for _, h := range webhooks {
+		if h.ID == id {
+			return h, true
+		}
+	}
+	return Webhook{}, false
+}
+
+// Add stores a new webhook, assigning its ID, creation time and first version. Once Open has been called the
+// webhook is saved first; if saving fails, nothing changes and the error is returned.
+func Add(h Webhook) (Webhook, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	h.ID = nextWebhookID
+	h.CreatedAt = time.Now().UTC()
+	h.Version = 1
+	if err := saveWebhook(h); err != nil {
+		return Webhook{}, err
+	}
+	nextWebhookID++
+	webhooks = append(webhooks, h)
+	return h, nil
+}
+
+// Replace updates the webhook with h.ID, keeping its creation time and, if h.Secret is empty, its secret.
+// If expectedVersion is non-zero and does not match, nothing changes and ErrVersionConflict is returned
+// along with the current webhook. Like Add, it saves the change before making it.
+func Replace(h Webhook, expectedVersion int64) (previous Webhook, updated Webhook, err error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i := range webhooks {
+		if webhooks[i].ID != h.ID {
+			continue
+		}
+		previous = webhooks[i]
+		if expectedVersion != 0 && previous.Version != expectedVersion {
+			return previous, previous, ErrVersionConflict
+		}
+		if h.Secret == "" {
+			h.Secret = previous.Secret
+		}
+		h.CreatedAt = previous.CreatedAt
+		h.Version = previous.Version + 1
+		if err := saveWebhook(h); err != nil {
+			return previous, previous, err
+		}
+		webhooks[i] = h
+		return previous, h, nil
+	}
+	return Webhook{}, Webhook{}, ErrNotFound
+}
+
+// Delete removes the webhook, saving the deletion first like Add. Deliveries already queued for it are still
+// attempted.
+func Delete(id int) (Webhook, error) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	for i, h := range webhooks {
+		if h.ID == id {
+			if err := save(record{Deleted: id}); err != nil {
+				return Webhook{}, err
+			}
+			webhooks = append(webhooks[:i], webhooks[i+1:]...)
+			return h, nil
+		}
+	}
+	return Webhook{}, ErrNotFound
+}
+
+// subscribed returns the active webhooks that want e.
+func subscribed(e events.Event) []Webhook {
+	mutex.RLock()
+	defer mutex.RUnlock()
+	var result []Webhook
+	for _, h := range webhooks {
+		if h.Wants(e) {
+			result = append(result, h)
+		}
+	}
+	return result
+}
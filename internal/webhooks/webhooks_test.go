@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// useSubscriptionLog opens a fresh subscription log for one test and restores the in-memory subscriptions after.
+func useSubscriptionLog(t *testing.T) string {
+	t.Helper()
+	mutex.Lock()
+	savedHooks, savedNextID, savedFile := webhooks, nextWebhookID, file
+	webhooks, nextWebhookID, file = nil, 1, nil
+	mutex.Unlock()
+	t.Cleanup(func() {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if file != nil {
+			file.Close()
+		}
+		webhooks, nextWebhookID, file = savedHooks, savedNextID, savedFile
+	})
+	path := filepath.Join(t.TempDir(), "webhooks.jsonl")
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenRestoresSubscriptions(t *testing.T) {
+	path := useSubscriptionLog(t)
+	erp, err := Add(Webhook{URL: "https://erp.example/hook", Events: []string{"policy"}, Secret: "erp-secret", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gone, err := Add(Webhook{URL: "https://old.example/hook", Events: []string{AllEvents}, Secret: "old-secret", Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	erp.Events, erp.Active, erp.Secret = []string{"policy", "offer.record"}, false, ""
+	if _, erp, err = Replace(erp, erp.Version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Delete(gone.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := List(); len(got) != 1 || !reflect.DeepEqual(got[0], erp) || got[0].Secret != "erp-secret" {
+		t.Fatalf("reloaded %+v, want only %+v with its secret", got, erp)
+	}
+	next, err := Add(Webhook{URL: "https://new.example/hook", Events: []string{AllEvents}, Active: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.ID != gone.ID+1 {
+		t.Errorf("new webhook got ID %d, want %d: IDs of deleted webhooks must not be reused", next.ID, gone.ID+1)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("subscription log mode %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+}
+
+func TestOpenRejectsCorruptSubscriptions(t *testing.T) {
+	tests := []struct {
+		name, content, wantErr string
+		wantHooks              int
+	}{
+		{"torn last line", `{"webhook":{"id":1,"url":"https://a.example","events":["*"],"active":true,"version":1,"secret":"s"}}` + "\n" + `{"webhook":{"id":2,"ur`, "", 1},
+		{"corrupt middle line", "not json\n" + `{"deleted":1}` + "\n", "line 1 is corrupt", 0},
+		{"empty record", "{}\n{}\n", "neither a webhook nor a deletion", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := useSubscriptionLog(t)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			err := Open(path)
+			if tt.wantErr == "" && err != nil || tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Open: %v, want error containing %q", err, tt.wantErr)
+			}
+			if tt.wantErr == "" && len(List()) != tt.wantHooks {
+				t.Errorf("loaded %d webhooks, want %d", len(List()), tt.wantHooks)
+			}
+		})
+	}
+}